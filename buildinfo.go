@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/qishu321/exporter/exporter"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+)
+
+// revision 和 buildDate 与 version 一样在构建时通过 -ldflags 设置, 如
+// -X main.revision=$(git rev-parse HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ),
+// 未设置时使用 go build 记录的 VCS 信息
+var (
+	revision  = ""
+	buildDate = ""
+)
+
+// buildInfo 是 /version 的输出
+type buildInfo struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
+
+func currentBuildInfo() buildInfo {
+	info := buildInfo{Version: version, Revision: revision, BuildDate: buildDate, GoVersion: runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Revision == "":
+				info.Revision = s.Value
+			case s.Key == "vcs.time" && info.BuildDate == "":
+				info.BuildDate = s.Value
+			}
+		}
+	}
+	return info
+}
+
+func (b buildInfo) String() string {
+	return fmt.Sprintf("process-exporter %s (revision %s, built %s, %s)", b.Version, b.Revision, b.BuildDate, b.GoVersion)
+}
+
+// buildInfoCollector 导出值恒为 1 的 process_exporter_build_info, 版本信息在标签中
+func buildInfoCollector(b buildInfo) prometheus.Collector {
+	return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: "process_exporter",
+		Name:      "build_info",
+		Help:      exporter.T("help.build_info"),
+		ConstLabels: prometheus.Labels{
+			"version":    b.Version,
+			"revision":   b.Revision,
+			"build_date": b.BuildDate,
+			"goversion":  b.GoVersion,
+		},
+	}, func() float64 { return 1 })
+}
+
+// versionHandler 以 JSON 返回版本信息
+func versionHandler(b buildInfo) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(b)
+	})
+}
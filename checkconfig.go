@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/qishu321/exporter/exporter"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// runCheckConfig 实现 check-config 子命令: 检查配置文件并打印每个目标的采集计划, 不启动服务.
+// 没有问题时返回 0, 有问题时返回 1, 便于在下发配置前执行
+func runCheckConfig(args []string) int {
+	fs := flag.NewFlagSet("check-config", flag.ExitOnError)
+	configFile := fs.String("config.file", "", exporter.T("flag.config.file"))
+	fs.Parse(args)
+	path := *configFile
+	if path == "" && fs.NArg() == 1 {
+		path = fs.Arg(0)
+	}
+	if path == "" {
+		fmt.Fprintln(os.Stderr, exporter.T("cli.check_config_usage"))
+		return 2
+	}
+
+	c, err := exporter.CheckConfig(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, exporter.T("cli.check_config", err))
+		return 1
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, exporter.T("checkconfig.header"))
+	for _, t := range c.Targets {
+		aggregation := t.Aggregation
+		if aggregation == "" {
+			aggregation = exporter.AggregateFirst
+		}
+		if t.IncludeChildren {
+			aggregation += "+children"
+		}
+		labels := strings.Join(t.Labels, ",")
+		if labels == "" {
+			labels = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", t.Name, t.Match, aggregation, t.Interval, labels)
+	}
+	tw.Flush()
+
+	if len(c.Problems) > 0 {
+		fmt.Fprintln(os.Stderr)
+		for _, p := range c.Problems {
+			fmt.Fprintln(os.Stderr, exporter.T("checkconfig.problem", p))
+		}
+		return 1
+	}
+	fmt.Println()
+	fmt.Println(exporter.T("checkconfig.ok", path, len(c.Targets)))
+	return 0
+}
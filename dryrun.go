@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/qishu321/exporter/exporter"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// dryRun 打印配置中的每个目标在当前进程表中匹配到的进程, 以及没有匹配到任何进程的目标
+func dryRun(ctx context.Context, w io.Writer, cfg exporter.Config) error {
+	res, err := exporter.Resolve(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, exporter.T("dryrun.header"))
+	var missing []string
+	for _, r := range res {
+		if len(r.PIDs) == 0 {
+			missing = append(missing, r.Target)
+			fmt.Fprintf(tw, "%s\t%s\t-\t-\n", r.Target, r.Match)
+			continue
+		}
+		others := make([]string, 0, len(r.PIDs)-1)
+		for _, pid := range r.PIDs[1:] {
+			others = append(others, fmt.Sprint(pid))
+		}
+		other := strings.Join(others, ",")
+		if other == "" {
+			other = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", r.Target, r.Match, r.PIDs[0], other)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(missing) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, exporter.T("dryrun.unmatched", strings.Join(missing, ", ")))
+	}
+	return nil
+}
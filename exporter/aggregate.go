@@ -0,0 +1,149 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shirou/gopsutil/process"
+	"strconv"
+)
+
+// 目标匹配到多个进程时的聚合方式
+const (
+	// 只使用第一个匹配的进程, 默认
+	AggregateFirst = "first"
+	// process_cpu_usage_percent 等指标为所有匹配进程的和、平均值或最大值
+	AggregateSum = "sum"
+	AggregateAvg = "avg"
+	AggregateMax = "max"
+	// 与 first 相同, 另外按 pid 标签分别导出每个进程的数据
+	AggregatePerPID = "per_pid"
+)
+
+func validateAggregation(t TargetConfig) error {
+	switch t.Aggregation {
+	case "", AggregateFirst, AggregatePerPID:
+		return nil
+	case AggregateSum, AggregateAvg, AggregateMax:
+		// 匹配的进程通常也是后代进程, 会被重复计算
+		if t.IncludeChildren {
+			return fmt.Errorf("target %s: include_children cannot be combined with aggregation %s", t.Name, t.Aggregation)
+		}
+		return nil
+	}
+	return fmt.Errorf("target %s: unsupported aggregation %q, want %s, %s, %s, %s or %s",
+		t.Name, t.Aggregation, AggregateFirst, AggregateSum, AggregateAvg, AggregateMax, AggregatePerPID)
+}
+
+// readPID 读取单个进程的 CPU、内存、文件描述符数和线程数, 用于聚合第一个之外的匹配进程
+func readPID(ctx context.Context, pid int32, smaps bool) (*Stats, error) {
+	p, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	s := &Stats{PID: pid}
+	if s.CPUPercent, err = p.CPUPercentWithContext(ctx); err != nil {
+		return nil, err
+	}
+	memoryPercent, err := p.MemoryPercentWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.MemoryPercent = float64(memoryPercent)
+	memInfo, err := p.MemoryInfoWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.MemoryRSS, s.MemoryVMS, s.MemorySwap = memInfo.RSS, memInfo.VMS, memInfo.Swap
+	s.MemoryShared, _ = sharedMemory(ctx, p)
+	if smaps {
+		if swap, swapPss, err := readSmaps(pid); err == nil {
+			s.MemorySwap, s.MemorySwapPss = swap, swapPss
+		}
+	}
+	s.NumFDs, _ = p.NumFDsWithContext(ctx)
+	s.NumThreads, _ = p.NumThreadsWithContext(ctx)
+	return s, nil
+}
+
+// aggregate 按目标的聚合方式把 pids 中其他进程的数据合并到第一个进程的 s 中,
+// per_pid 时导出每个进程的数据. 读取失败的进程 (如已经退出) 跳过
+func (e *Exporter) aggregate(ctx context.Context, processName, mode string, s *Stats, pids []int32) {
+	all := []*Stats{s}
+	for _, pid := range pids[1:] {
+		ps, err := readPID(ctx, pid, e.config.Smaps)
+		if err != nil {
+			e.logger.Debug(T("log.aggregate_skip"), "target", processName, "pid", pid, "error", err)
+			continue
+		}
+		all = append(all, ps)
+	}
+
+	if mode == AggregatePerPID {
+		seen := make(map[string]bool, len(all))
+		for _, ps := range all {
+			pid := strconv.Itoa(int(ps.PID))
+			seen[pid] = true
+			e.pidCPU.WithLabelValues(processName, pid).Set(ps.CPUPercent)
+			e.pidMemory.WithLabelValues(processName, pid).Set(ps.MemoryPercent)
+			e.pidRSS.WithLabelValues(processName, pid).Set(float64(ps.MemoryRSS))
+		}
+		// 删除已经退出的进程的序列
+		for pid := range e.perPID[processName] {
+			if !seen[pid] {
+				labels := prometheus.Labels{"process": processName, "pid": pid}
+				e.pidCPU.Delete(labels)
+				e.pidMemory.Delete(labels)
+				e.pidRSS.Delete(labels)
+			}
+		}
+		e.perPID[processName] = seen
+		return
+	}
+
+	for _, ps := range all[1:] {
+		switch mode {
+		case AggregateSum, AggregateAvg:
+			s.CPUPercent += ps.CPUPercent
+			s.MemoryPercent += ps.MemoryPercent
+			s.MemoryRSS += ps.MemoryRSS
+			s.MemoryVMS += ps.MemoryVMS
+			s.MemorySwap += ps.MemorySwap
+			s.MemoryShared += ps.MemoryShared
+			s.MemorySwapPss += ps.MemorySwapPss
+			s.NumFDs += ps.NumFDs
+			s.NumThreads += ps.NumThreads
+		case AggregateMax:
+			s.CPUPercent = max(s.CPUPercent, ps.CPUPercent)
+			s.MemoryPercent = max(s.MemoryPercent, ps.MemoryPercent)
+			s.MemoryRSS = max(s.MemoryRSS, ps.MemoryRSS)
+			s.MemoryVMS = max(s.MemoryVMS, ps.MemoryVMS)
+			s.MemorySwap = max(s.MemorySwap, ps.MemorySwap)
+			s.MemoryShared = max(s.MemoryShared, ps.MemoryShared)
+			s.MemorySwapPss = max(s.MemorySwapPss, ps.MemorySwapPss)
+			s.NumFDs = max(s.NumFDs, ps.NumFDs)
+			s.NumThreads = max(s.NumThreads, ps.NumThreads)
+		}
+	}
+	if mode == AggregateAvg {
+		n := len(all)
+		s.CPUPercent /= float64(n)
+		s.MemoryPercent /= float64(n)
+		s.MemoryRSS /= uint64(n)
+		s.MemoryVMS /= uint64(n)
+		s.MemorySwap /= uint64(n)
+		s.MemoryShared /= uint64(n)
+		s.MemorySwapPss /= uint64(n)
+		s.NumFDs /= int32(n)
+		s.NumThreads /= int32(n)
+	}
+}
+
+// deletePerPID 删除目标的所有按 pid 导出的序列
+func (e *Exporter) deletePerPID(processName string) {
+	labels := prometheus.Labels{"process": processName}
+	e.pidCPU.DeletePartialMatch(labels)
+	e.pidMemory.DeletePartialMatch(labels)
+	e.pidRSS.DeletePartialMatch(labels)
+	delete(e.perPID, processName)
+}
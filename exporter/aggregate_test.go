@@ -0,0 +1,44 @@
+package exporter
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"testing"
+	"time"
+)
+
+// aggregation: sum 时 num_threads 为所有匹配进程的和, first 时只取第一个进程
+func TestAggregation(t *testing.T) {
+	const name = "ag-pair"
+	startNamed(t, name)
+	startNamed(t, name)
+	clock := NewFakeClock(time.Unix(1700000000, 0))
+	tests := []struct {
+		aggregation string
+		want        float64
+	}{
+		{AggregateFirst, 1},
+		{AggregateSum, 2},
+		{AggregateMax, 1},
+	}
+	for _, tt := range tests {
+		e := newClockExporter(t, clock, Config{Targets: []TargetConfig{{Name: name, Aggregation: tt.aggregation}}})
+		e.Update(context.Background())
+		if got := testutil.ToFloat64(e.numThreads.WithLabelValues(name)); got != tt.want {
+			t.Errorf("num_threads with aggregation %s = %v, want %v", tt.aggregation, got, tt.want)
+		}
+	}
+}
+
+// aggregation: per_pid 时按 pid 标签分别导出每个进程
+func TestAggregationPerPID(t *testing.T) {
+	const name = "ag-perpid"
+	startNamed(t, name)
+	startNamed(t, name)
+	clock := NewFakeClock(time.Unix(1700000000, 0))
+	e := newClockExporter(t, clock, Config{Targets: []TargetConfig{{Name: name, Aggregation: AggregatePerPID}}})
+	e.Update(context.Background())
+	if n := testutil.CollectAndCount(e.pidRSS); n != 2 {
+		t.Fatalf("got %d per-pid rss series, want 2", n)
+	}
+}
@@ -0,0 +1,247 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go.starlark.net/starlark"
+	"maps"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// 告警通知的格式
+const (
+	WebhookAlertmanager = "alertmanager"
+	WebhookSlack        = "slack"
+)
+
+const (
+	// alertResendInterval 是向 Alertmanager 重复发送仍在触发的告警的间隔, 应小于其 resolve_timeout
+	alertResendInterval = time.Minute
+	// alertTimeout 是发送一次通知的超时时间
+	alertTimeout = 10 * time.Second
+)
+
+// alertRule 是编译好的告警规则
+type alertRule struct {
+	cfg     AlertConfig
+	program *starlark.Program
+}
+
+// alertState 是一条规则在一个满足条件的目标上的状态
+type alertState struct {
+	// 条件开始满足的时间
+	since    time.Time
+	firing   bool
+	firedAt  time.Time
+	lastSent time.Time
+}
+
+// alert 是一次通知中的一条告警
+type alert struct {
+	rule     *alertRule
+	target   string
+	startsAt time.Time
+	// 为零表示仍在触发
+	endsAt time.Time
+}
+
+// alerter 对每个采集周期的结果求值告警规则, 在触发和恢复时发送 webhook
+type alerter struct {
+	rules    []*alertRule
+	webhook  WebhookConfig
+	client   *http.Client
+	instance string
+	states   map[[2]string]*alertState
+}
+
+// newAlerter 编译配置中的告警规则, 没有规则时返回 nil
+func newAlerter(cfg Config) (*alerter, error) {
+	if len(cfg.Alerts) == 0 {
+		return nil, nil
+	}
+	switch {
+	case cfg.AlertWebhook.URL == "":
+		return nil, fmt.Errorf("alerts require alert_webhook.url")
+	case cfg.AlertWebhook.Format != "" && cfg.AlertWebhook.Format != WebhookAlertmanager && cfg.AlertWebhook.Format != WebhookSlack:
+		return nil, fmt.Errorf("alert_webhook: unknown format %q", cfg.AlertWebhook.Format)
+	}
+	targets := make(map[string]bool)
+	for _, name := range cfg.TargetNames() {
+		targets[name] = true
+	}
+	a := &alerter{webhook: cfg.AlertWebhook, client: &http.Client{Timeout: alertTimeout}, states: make(map[[2]string]*alertState)}
+	a.instance, _ = os.Hostname()
+	for _, ac := range cfg.Alerts {
+		switch {
+		case ac.Name == "":
+			return nil, fmt.Errorf("alert without name")
+		case (ac.Expr == "") == !ac.Down:
+			return nil, fmt.Errorf("alert %s: exactly one of expr and down is required", ac.Name)
+		case ac.Target != "" && !targets[ac.Target]:
+			return nil, fmt.Errorf("alert %s: unknown target %q", ac.Name, ac.Target)
+		}
+		r := &alertRule{cfg: ac}
+		if ac.Expr != "" {
+			var err error
+			if r.program, err = compileScript(ac.Name, ac.Expr); err != nil {
+				return nil, fmt.Errorf("compiling alert %s: %w", ac.Name, err)
+			}
+		}
+		a.rules = append(a.rules, r)
+	}
+	return a, nil
+}
+
+// evalAlerts 基于本周期的数据求值所有告警规则, 调用方需持有 mutex. 通知在后台发送, 不阻塞采集
+func (e *Exporter) evalAlerts() {
+	a := e.alerter
+	if a == nil {
+		return
+	}
+	now := e.clock.Now()
+	var batch []alert
+	for _, r := range a.rules {
+		targets := e.processNames
+		if r.cfg.Target != "" {
+			targets = []string{r.cfg.Target}
+		}
+		for _, target := range targets {
+			// Reload 移除的目标不再求值
+			if _, ok := e.matchers[target]; !ok {
+				continue
+			}
+			key := [2]string{r.cfg.Name, target}
+			st, ok := a.states[key]
+			if !e.alertActive(r, target) {
+				if ok && st.firing {
+					e.logger.Info(T("log.alert_resolved"), "alert", r.cfg.Name, "target", target)
+					batch = append(batch, alert{rule: r, target: target, startsAt: st.firedAt, endsAt: now})
+				}
+				delete(a.states, key)
+				continue
+			}
+			if !ok {
+				st = &alertState{since: now}
+				a.states[key] = st
+			}
+			switch {
+			case !st.firing && now.Sub(st.since) >= r.cfg.For:
+				st.firing, st.firedAt, st.lastSent = true, now, now
+				e.logger.Warn(T("log.alert_firing"), "alert", r.cfg.Name, "target", target)
+				batch = append(batch, alert{rule: r, target: target, startsAt: now})
+			case st.firing && a.format() == WebhookAlertmanager && now.Sub(st.lastSent) >= alertResendInterval:
+				// Alertmanager 在 resolve_timeout 内没有再次收到的告警会自动恢复
+				st.lastSent = now
+				batch = append(batch, alert{rule: r, target: target, startsAt: st.firedAt})
+			}
+		}
+	}
+	if len(batch) > 0 {
+		go e.sendAlerts(batch)
+	}
+}
+
+// alertActive 判断规则在目标上是否满足条件, 没有数据的目标不满足 expr 规则
+func (e *Exporter) alertActive(r *alertRule, target string) bool {
+	s, ok := e.stats[target]
+	if r.cfg.Down {
+		return !ok
+	}
+	if !ok {
+		return false
+	}
+	v, ok, err := evalProgram(r.cfg.Name, r.program, s)
+	if err != nil {
+		e.logger.Error(T("log.alert_eval"), "alert", r.cfg.Name, "target", target, "error", err)
+	}
+	return ok && v != 0
+}
+
+func (a *alerter) format() string {
+	if a.webhook.Format == "" {
+		return WebhookAlertmanager
+	}
+	return a.webhook.Format
+}
+
+// sendAlerts 把一批告警发送到 webhook
+func (e *Exporter) sendAlerts(batch []alert) {
+	a := e.alerter
+	body, err := a.encode(batch)
+	if err == nil {
+		err = a.post(body)
+	}
+	if err != nil {
+		e.self.alertNotifications.WithLabelValues("error").Inc()
+		e.logger.Error(T("log.alert_webhook"), "error", err)
+		return
+	}
+	e.self.alertNotifications.WithLabelValues("success").Inc()
+}
+
+func (a *alerter) post(body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), alertTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, string(a.webhook.URL), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// encode 按 webhook 的格式编码一批告警: Alertmanager 的 /api/v2/alerts 或 Slack 的 incoming webhook
+func (a *alerter) encode(batch []alert) ([]byte, error) {
+	if a.format() == WebhookSlack {
+		lines := make([]string, 0, len(batch))
+		for _, x := range batch {
+			status := "FIRING"
+			if !x.endsAt.IsZero() {
+				status = "RESOLVED"
+			}
+			line := fmt.Sprintf("[%s] %s: %s on %s", status, x.rule.cfg.Name, x.target, a.instance)
+			if x.rule.cfg.Summary != "" {
+				line += " - " + x.rule.cfg.Summary
+			}
+			lines = append(lines, line)
+		}
+		return json.Marshal(map[string]string{"text": strings.Join(lines, "\n")})
+	}
+
+	type amAlert struct {
+		Labels      map[string]string `json:"labels"`
+		Annotations map[string]string `json:"annotations,omitempty"`
+		StartsAt    time.Time         `json:"startsAt"`
+		EndsAt      *time.Time        `json:"endsAt,omitempty"`
+	}
+	alerts := make([]amAlert, 0, len(batch))
+	for _, x := range batch {
+		labels := maps.Clone(x.rule.cfg.Labels)
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels["alertname"], labels["process"], labels["instance"] = x.rule.cfg.Name, x.target, a.instance
+		am := amAlert{Labels: labels, StartsAt: x.startsAt}
+		if x.rule.cfg.Summary != "" {
+			am.Annotations = map[string]string{"summary": x.rule.cfg.Summary}
+		}
+		if !x.endsAt.IsZero() {
+			am.EndsAt = &x.endsAt
+		}
+		alerts = append(alerts, am)
+	}
+	return json.Marshal(alerts)
+}
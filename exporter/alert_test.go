@@ -0,0 +1,77 @@
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// webhookServer 返回收到的每个 Alertmanager 格式的通知
+func webhookServer(t *testing.T) (string, <-chan []map[string]any) {
+	t.Helper()
+	ch := make(chan []map[string]any, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var alerts []map[string]any
+		if err := json.Unmarshal(body, &alerts); err != nil {
+			t.Errorf("webhook body %s: %v", body, err)
+		}
+		ch <- alerts
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, ch
+}
+
+func receive(t *testing.T, ch <-chan []map[string]any) []map[string]any {
+	t.Helper()
+	select {
+	case alerts := <-ch:
+		return alerts
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification received")
+		return nil
+	}
+}
+
+// down 告警在条件持续 for 之后触发, 进程出现后发送恢复通知
+func TestAlertFiring(t *testing.T) {
+	const name = "al-flap"
+	url, ch := webhookServer(t)
+	clock := NewFakeClock(time.Unix(1700000000, 0))
+	e := newClockExporter(t, clock, Config{
+		Processes:    []string{name},
+		Alerts:       []AlertConfig{{Name: "AlDown", Target: name, Down: true, For: time.Minute, Labels: map[string]string{"severity": "critical"}}},
+		AlertWebhook: WebhookConfig{URL: Secret(url)},
+	})
+	ctx := context.Background()
+
+	e.Update(ctx)
+	if st := e.alerter.states[[2]string{"AlDown", name}]; st == nil || st.firing {
+		t.Fatalf("alert state = %+v before for elapsed, want pending", st)
+	}
+	clock.Advance(time.Minute)
+	e.Update(ctx)
+	alerts := receive(t, ch)
+	if len(alerts) != 1 {
+		t.Fatalf("got %d alerts, want 1", len(alerts))
+	}
+	labels, _ := alerts[0]["labels"].(map[string]any)
+	if labels["alertname"] != "AlDown" || labels["process"] != name || labels["severity"] != "critical" {
+		t.Fatalf("alert labels = %v", labels)
+	}
+	if _, ok := alerts[0]["endsAt"]; ok {
+		t.Fatalf("firing alert has endsAt: %v", alerts[0])
+	}
+
+	startNamed(t, name)
+	clock.Advance(defaultPIDRescanInterval)
+	e.Update(ctx)
+	alerts = receive(t, ch)
+	if len(alerts) != 1 || alerts[0]["endsAt"] == nil {
+		t.Fatalf("got %v, want one resolved alert", alerts)
+	}
+}
@@ -0,0 +1,112 @@
+package exporter
+
+import (
+	"encoding/json"
+	"gopkg.in/yaml.v3"
+	"io"
+	"net/http"
+	"time"
+)
+
+// apiResponse 与 Prometheus HTTP API 的响应格式保持一致
+type apiResponse struct {
+	Status string      `json:"status"`
+	Data   interface{} `json:"data,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeAPIError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, apiResponse{Status: "error", Error: err.Error()})
+}
+
+// APIHandler 返回 /api/v1/ 下的 JSON API, 挂载到其他前缀时需配合 http.StripPrefix
+func (e *Exporter) APIHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/config", e.handleConfig)
+	mux.HandleFunc("GET /api/v1/stats", e.handleStats)
+	mux.HandleFunc("GET /api/v1/targets", e.handleListTargets)
+	mux.HandleFunc("POST /api/v1/targets", e.admin(e.handleAddTarget))
+	mux.HandleFunc("DELETE /api/v1/targets/{name}", e.admin(e.handleDeleteTarget))
+	mux.HandleFunc("/api/v1/export.csv", e.exportHandler(',', "text/csv; charset=utf-8"))
+	mux.HandleFunc("/api/v1/export.tsv", e.exportHandler('\t', "text/tab-separated-values; charset=utf-8"))
+	return mux
+}
+
+// handleConfig 返回当前生效的配置 (YAML 格式), 其中的 Secret 字段已被隐藏
+func (e *Exporter) handleConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	e.mutex.Lock()
+	out, err := yaml.Marshal(e.config)
+	e.mutex.Unlock()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, apiResponse{Status: "success", Data: map[string]string{"yaml": string(out)}})
+}
+
+// statsView 是 /api/v1/stats 中单个目标的数据, 没有匹配到进程的目标 up 为 false, 其余字段为 0
+type statsView struct {
+	Process string `json:"process"`
+	Up      bool   `json:"up"`
+
+	PID               int32      `json:"pid,omitempty"`
+	CPUPercent        float64    `json:"cpu_percent"`
+	MemoryPercent     float64    `json:"memory_percent"`
+	MemoryRSSBytes    uint64     `json:"memory_rss_bytes"`
+	MemoryVMSBytes    uint64     `json:"memory_vms_bytes"`
+	MemorySwapBytes   uint64     `json:"memory_swap_bytes"`
+	MemorySharedBytes uint64     `json:"memory_shared_bytes"`
+	NumFDs            int32      `json:"num_fds"`
+	NumThreads        int32      `json:"num_threads"`
+	Status            string     `json:"status,omitempty"`
+	StartTime         *time.Time `json:"start_time,omitempty"`
+	Incomplete        bool       `json:"incomplete"`
+	Time              *time.Time `json:"time,omitempty"`
+}
+
+func statsViews(snap []Stats) []statsView {
+	views := make([]statsView, 0, len(snap))
+	for _, s := range snap {
+		views = append(views, statsView{
+			Process:           s.Process,
+			Up:                s.PID != 0,
+			PID:               s.PID,
+			CPUPercent:        s.CPUPercent,
+			MemoryPercent:     s.MemoryPercent,
+			MemoryRSSBytes:    s.MemoryRSS,
+			MemoryVMSBytes:    s.MemoryVMS,
+			MemorySwapBytes:   s.MemorySwap,
+			MemorySharedBytes: s.MemoryShared,
+			NumFDs:            s.NumFDs,
+			NumThreads:        s.NumThreads,
+			Status:            s.Status,
+			StartTime:         timePtr(s.StartTime),
+			Incomplete:        s.Incomplete,
+			Time:              timePtr(s.Time),
+		})
+	}
+	return views
+}
+
+// handleStats 以 JSON 返回每个目标最近一次采集的数据, 供无法解析 Prometheus 文本格式的工具使用
+func (e *Exporter) handleStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, apiResponse{Status: "success", Data: statsViews(e.Snapshot())})
+}
+
+// WriteStatsJSON 以与 /api/v1/stats 的 data 相同的格式把 stats 写到 w
+func WriteStatsJSON(w io.Writer, stats []Stats) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(statsViews(stats))
+}
@@ -0,0 +1,47 @@
+package exporter
+
+import (
+	"encoding/json"
+	"github.com/prometheus/client_golang/prometheus"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// /api/v1/config 隐藏所有带令牌、命令和内部地址的字段
+func TestConfigRedactsSecrets(t *testing.T) {
+	secrets := []string{"https://kubelet.internal:10250", "mysqladmin -psecret ping", "http://127.0.0.1:8080/healthz?token=secret",
+		"https://hooks.slack.com/services/T000/B000/XXXXXXXX"}
+	e, err := New(Opts{
+		Config: Config{
+			KubeletURL:   Secret(secrets[0]),
+			AlertWebhook: WebhookConfig{URL: Secret(secrets[3]), Format: WebhookSlack},
+			Targets: []TargetConfig{
+				{Name: "api-cmd", CheckCommand: Secret(secrets[1])},
+				{Name: "api-http", Probe: &ProbeConfig{HTTP: Secret(secrets[2])}},
+			},
+		},
+		Registerer: prometheus.NewRegistry(),
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	w := apiRequest(e, http.MethodGet, "/api/v1/config", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET = %d %s", w.Code, w.Body)
+	}
+	var resp struct {
+		Data struct{ YAML string }
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(resp.Data.YAML, secretToken); n != len(secrets) {
+		t.Errorf("got %d %s in config, want %d:\n%s", n, secretToken, len(secrets), resp.Data.YAML)
+	}
+	for _, s := range secrets {
+		if strings.Contains(resp.Data.YAML, s) {
+			t.Errorf("config contains %q:\n%s", s, resp.Data.YAML)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package exporter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+// maxBinarySums 是缓存的可执行文件校验和的最大数量, 超过后清空重新计算
+const maxBinarySums = 1024
+
+// binaryRead 是监控的进程的可执行文件的路径, 正在运行的和磁盘上的文件的 sha256
+type binaryRead struct {
+	path    string
+	running string
+	// 磁盘上的文件已被删除时为空
+	onDisk string
+}
+
+// changed 判断磁盘上的可执行文件是否与正在运行的不同
+func (b binaryRead) changed() bool {
+	return b.onDisk != b.running
+}
+
+// binaryKey 标识一个文件的内容, 大小和修改时间都没变时复用上次的校验和
+type binaryKey struct {
+	name  string
+	size  int64
+	mtime time.Time
+}
+
+// binarySums 缓存可执行文件的 sha256, 由采集的 worker 并发使用
+type binarySums struct {
+	mu   sync.Mutex
+	sums map[binaryKey]string
+}
+
+func newBinarySums() *binarySums {
+	return &binarySums{sums: make(map[binaryKey]string)}
+}
+
+// sum 返回文件 name 的 sha256, 内容没变时不重新读取
+func (b *binarySums) sum(name string) (string, error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return "", err
+	}
+	key := binaryKey{name: name, size: fi.Size(), mtime: fi.ModTime()}
+	b.mu.Lock()
+	sum, ok := b.sums[key]
+	b.mu.Unlock()
+	if ok {
+		return sum, nil
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	sum = hex.EncodeToString(h.Sum(nil))
+	b.mu.Lock()
+	if len(b.sums) >= maxBinarySums {
+		clear(b.sums)
+	}
+	b.sums[key] = sum
+	b.mu.Unlock()
+	return sum, nil
+}
+
+// read 计算进程 pid 正在运行的和磁盘上的可执行文件的校验和
+func (b *binarySums) read(pid int32) (*binaryRead, error) {
+	path, onDisk, running, err := exePaths(pid)
+	if err != nil {
+		return nil, err
+	}
+	r := &binaryRead{path: path}
+	if r.running, err = b.sum(running); err != nil {
+		return nil, err
+	}
+	if r.onDisk, err = b.sum(onDisk); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return r, nil
+}
+
+// binaryState 是上一次导出的目标的可执行文件
+type binaryState struct {
+	pid int32
+	binaryRead
+}
+
+// setBinary 导出目标的可执行文件的校验和, 磁盘上的文件与正在运行的不同 (升级后没有重启) 时计数加 1,
+// 同一个进程和同一个磁盘文件只计一次
+func (e *Exporter) setBinary(processName string, pid int32, b *binaryRead) {
+	prev, ok := e.binaries[processName]
+	if ok && (prev.path != b.path || prev.running != b.running) {
+		e.binaryInfo.DeletePartialMatch(prometheus.Labels{"process": processName})
+	}
+	e.binaries[processName] = binaryState{pid: pid, binaryRead: *b}
+	// 路径来自 /proc, 可能不是合法的 UTF-8
+	e.binaryInfo.WithLabelValues(processName, b.running, sanitizeName(b.path)).Set(1)
+	e.binaryChanges.WithLabelValues(processName)
+	if b.changed() && !(ok && prev.pid == pid && prev.onDisk == b.onDisk) {
+		e.binaryChanges.WithLabelValues(processName).Inc()
+	}
+}
+
+// deleteBinary 删除目标的可执行文件信息
+func (e *Exporter) deleteBinary(processName string) {
+	e.binaryInfo.DeletePartialMatch(prometheus.Labels{"process": processName})
+	delete(e.binaries, processName)
+}
@@ -0,0 +1,19 @@
+package exporter
+
+import (
+	"os"
+	"strings"
+)
+
+// exePaths 返回进程的可执行文件的路径, 读取磁盘上的该文件的路径, 以及读取正在运行的文件的路径 /proc/<pid>/exe,
+// 后者在磁盘上的文件被替换或删除后仍然指向进程启动时的文件. 磁盘上的文件通过 /proc/<pid>/root 读取,
+// 进程在其他 mount namespace (如容器) 中时路径也是正确的
+func exePaths(pid int32) (path, onDisk, running string, err error) {
+	running = procPath(pid, "exe")
+	path, err = os.Readlink(running)
+	if err != nil {
+		return "", "", "", err
+	}
+	path = strings.TrimSuffix(path, " (deleted)")
+	return path, procPath(pid, "root", path), running, nil
+}
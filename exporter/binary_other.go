@@ -0,0 +1,19 @@
+//go:build !linux
+
+package exporter
+
+import (
+	"github.com/shirou/gopsutil/process"
+)
+
+// exePaths 返回进程的可执行文件的路径, 其他平台上无法读取正在运行的文件, 只能计算磁盘上的
+func exePaths(pid int32) (path, onDisk, running string, err error) {
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return "", "", "", err
+	}
+	if path, err = p.Exe(); err != nil {
+		return "", "", "", err
+	}
+	return path, path, path, nil
+}
@@ -0,0 +1,66 @@
+package exporter
+
+import "time"
+
+// cgroupStats 是进程所在 cgroup 的内存和 CPU 限制及用量
+type cgroupStats struct {
+	// 内存限制 (字节), 0 表示不限制
+	memLimit, memUsage uint64
+	// CPU 配额 (核数), 0 表示不限制
+	cpuQuota float64
+	// CPU 控制器中的路径和累计的 CPU 时间 (秒), 读取失败时 cpuUsage 为 -1
+	cpuPath  string
+	cpuUsage float64
+}
+
+// cgroupCPU 记录一个目标所在 cgroup 上一次的 CPU 时间, 用于计算两次采集之间的 CPU 使用率
+type cgroupCPU struct {
+	path  string
+	usage float64
+	at    time.Time
+}
+
+// updateCgroup 导出监控的进程所在 cgroup 的限制以及用量与限制之比, 没有设置限制时删除对应的序列.
+// 容器中的进程占主机内存的百分比没有意义, 应该与 cgroup 的限制比较
+func (e *Exporter) updateCgroup(processName string, pid int32) {
+	cg, err := readCgroup(pid)
+	if err != nil {
+		e.deleteCgroup(processName)
+		return
+	}
+	if cg.memLimit > 0 {
+		e.cgroupMemLimit.WithLabelValues(processName).Set(float64(cg.memLimit))
+		e.cgroupMemRatio.WithLabelValues(processName).Set(float64(cg.memUsage) / float64(cg.memLimit))
+	} else {
+		e.cgroupMemLimit.DeleteLabelValues(processName)
+		e.cgroupMemRatio.DeleteLabelValues(processName)
+	}
+	if cg.cpuQuota > 0 {
+		e.cgroupCPUQuota.WithLabelValues(processName).Set(cg.cpuQuota)
+	} else {
+		e.cgroupCPUQuota.DeleteLabelValues(processName)
+	}
+
+	now := e.clock.Now()
+	last := e.cgroupCPU[processName]
+	if cg.cpuUsage < 0 {
+		delete(e.cgroupCPU, processName)
+	} else {
+		e.cgroupCPU[processName] = &cgroupCPU{path: cg.cpuPath, usage: cg.cpuUsage, at: now}
+	}
+	if cg.cpuQuota > 0 && cg.cpuUsage >= 0 && last != nil && last.path == cg.cpuPath && now.After(last.at) {
+		used := max(cg.cpuUsage-last.usage, 0) / now.Sub(last.at).Seconds()
+		e.cgroupCPURatio.WithLabelValues(processName).Set(used / cg.cpuQuota)
+	} else if cg.cpuQuota == 0 {
+		e.cgroupCPURatio.DeleteLabelValues(processName)
+	}
+}
+
+// deleteCgroup 删除目标的所有 cgroup 序列
+func (e *Exporter) deleteCgroup(processName string) {
+	e.cgroupMemLimit.DeleteLabelValues(processName)
+	e.cgroupMemRatio.DeleteLabelValues(processName)
+	e.cgroupCPUQuota.DeleteLabelValues(processName)
+	e.cgroupCPURatio.DeleteLabelValues(processName)
+	delete(e.cgroupCPU, processName)
+}
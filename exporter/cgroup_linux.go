@@ -0,0 +1,155 @@
+package exporter
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// cgroupRoot 是 cgroup 文件系统的挂载点, 以容器运行时需要挂载主机的 /sys/fs/cgroup
+const cgroupRoot = "/sys/fs/cgroup"
+
+// cgroupV1Unlimited 以上的 memory.limit_in_bytes 表示不限制 (页对齐后的 int64 最大值)
+const cgroupV1Unlimited = 1 << 62
+
+// readCgroup 根据 /proc/<pid>/cgroup 读取进程所在 cgroup 的内存和 CPU 限制及用量, 支持 v1 和 v2.
+// 限制取该 cgroup 及其所有上级中最小的一个, 例如 systemd slice 上设置的限制
+func readCgroup(pid int32) (*cgroupStats, error) {
+	f, err := os.Open(procPath(pid, "cgroup"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	// v1 中控制器名到挂载目录和路径, 如 cpu -> (cpu,cpuacct, /docker/<id>)
+	v1 := make(map[string][2]string)
+	v2 := ""
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		parts := strings.SplitN(sc.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[0] == "0" && parts[1] == "" {
+			v2 = parts[2]
+			continue
+		}
+		for _, c := range strings.Split(parts[1], ",") {
+			v1[c] = [2]string{parts[1], parts[2]}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers")); err != nil {
+		v2 = ""
+	}
+
+	cg := &cgroupStats{cpuUsage: -1}
+	found := false
+	if m, ok := v1["memory"]; ok {
+		dir := filepath.Join(cgroupRoot, m[0])
+		found = true
+		cg.memLimit = minLimit(dir, m[1], func(d string) (uint64, bool) {
+			n, ok := readUint(filepath.Join(d, "memory.limit_in_bytes"))
+			return n, ok && n < cgroupV1Unlimited
+		})
+		cg.memUsage, _ = readUint(filepath.Join(dir, m[1], "memory.usage_in_bytes"))
+	} else if v2 != "" {
+		found = true
+		cg.memLimit = minLimit(cgroupRoot, v2, func(d string) (uint64, bool) {
+			return readUint(filepath.Join(d, "memory.max"))
+		})
+		cg.memUsage, _ = readUint(filepath.Join(cgroupRoot, v2, "memory.current"))
+	}
+	if c, ok := v1["cpu"]; ok {
+		found = true
+		cg.cpuQuota = minQuota(filepath.Join(cgroupRoot, c[0]), c[1], func(d string) float64 {
+			quota, err := strconv.ParseInt(readLine(filepath.Join(d, "cpu.cfs_quota_us")), 10, 64)
+			period, ok := readUint(filepath.Join(d, "cpu.cfs_period_us"))
+			if err != nil || quota <= 0 || !ok || period == 0 {
+				return 0
+			}
+			return float64(quota) / float64(period)
+		})
+		cg.cpuPath = c[1]
+		if a, ok := v1["cpuacct"]; ok {
+			if ns, ok := readUint(filepath.Join(cgroupRoot, a[0], a[1], "cpuacct.usage")); ok {
+				cg.cpuUsage = float64(ns) / 1e9
+			}
+		}
+	} else if v2 != "" {
+		found = true
+		cg.cpuQuota = minQuota(cgroupRoot, v2, func(d string) float64 {
+			// 如 "max 100000" 或 "50000 100000"
+			quota, period, _ := strings.Cut(readLine(filepath.Join(d, "cpu.max")), " ")
+			q, err1 := strconv.ParseFloat(quota, 64)
+			p, err2 := strconv.ParseFloat(period, 64)
+			if err1 != nil || err2 != nil || p == 0 {
+				return 0
+			}
+			return q / p
+		})
+		cg.cpuPath = v2
+		if usec, ok := cgroupStat(filepath.Join(cgroupRoot, v2, "cpu.stat"), "usage_usec"); ok {
+			cg.cpuUsage = float64(usec) / 1e6
+		}
+	}
+	if !found {
+		return nil, os.ErrNotExist
+	}
+	return cg, nil
+}
+
+// minLimit 返回 p 及其所有上级 cgroup 中最小的限制, 都不限制时返回 0
+func minLimit(root, p string, limit func(dir string) (uint64, bool)) uint64 {
+	var min uint64
+	for d := p; ; d = path.Dir(d) {
+		if n, ok := limit(filepath.Join(root, d)); ok && (min == 0 || n < min) {
+			min = n
+		}
+		if d == "/" || d == "." {
+			return min
+		}
+	}
+}
+
+// minQuota 与 minLimit 相同, quota 返回 0 表示不限制
+func minQuota(root, p string, quota func(dir string) float64) float64 {
+	var min float64
+	for d := p; ; d = path.Dir(d) {
+		if q := quota(filepath.Join(root, d)); q > 0 && (min == 0 || q < min) {
+			min = q
+		}
+		if d == "/" || d == "." {
+			return min
+		}
+	}
+}
+
+func readLine(name string) string {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// readUint 读取只有一个整数的 cgroup 文件, 文件不存在或为 "max" 时返回 false
+func readUint(name string) (uint64, bool) {
+	n, err := strconv.ParseUint(readLine(name), 10, 64)
+	return n, err == nil
+}
+
+// cgroupStat 读取 cpu.stat 等 "键 值" 格式的文件中的一项
+func cgroupStat(name, key string) (uint64, bool) {
+	for _, line := range strings.Split(readLine(name), "\n") {
+		if v, ok := strings.CutPrefix(line, key+" "); ok {
+			n, err := strconv.ParseUint(v, 10, 64)
+			return n, err == nil
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package exporter
+
+import "errors"
+
+// readCgroup 只在 Linux 上支持
+func readCgroup(pid int32) (*cgroupStats, error) {
+	return nil, errors.New("cgroups are only supported on Linux")
+}
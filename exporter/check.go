@@ -0,0 +1,136 @@
+package exporter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// PlannedTarget 是检查配置后一个监控目标的采集计划
+type PlannedTarget struct {
+	Name  string
+	Match string
+	// 为空时为 first
+	Aggregation     string
+	Interval        time.Duration
+	IncludeChildren bool
+	// 静态标签, 以及来自环境变量和命令行的动态标签的名称, 已排序
+	Labels []string
+}
+
+// ConfigCheck 是 CheckConfig 的结果
+type ConfigCheck struct {
+	Config  *Config
+	Targets []PlannedTarget
+	// 启动时会报错的设置, 以及未知的字段、同名的目标等会被静默忽略的问题
+	Problems []error
+}
+
+// CheckConfig 读取并检查配置文件, 不启动采集也不读取进程表. 文件无法读取或解析时返回错误,
+// 其他问题全部放在 Problems 中, 而不是遇到第一个就停止
+func CheckConfig(path string) (*ConfigCheck, error) {
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &ConfigCheck{Config: cfg}
+	problem := func(err error) {
+		if err != nil {
+			c.Problems = append(c.Problems, err)
+		}
+	}
+
+	// 拼错的字段在正常加载时被忽略
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&Config{}); err != nil && !errors.Is(err, io.EOF) {
+		problem(err)
+	}
+
+	if cfg.Locale != "" && cfg.Locale != LocaleZH && cfg.Locale != LocaleEN {
+		problem(fmt.Errorf("unsupported locale %q, want %s or %s", cfg.Locale, LocaleZH, LocaleEN))
+	}
+	_, _, err = cpuGaugeOpts(cfg.Units.CPU)
+	problem(err)
+	_, err = memGaugeOpts(cfg.Units.Memory)
+	problem(err)
+
+	names := cfg.TargetNames()
+	for _, name := range names {
+		problem(validateTargetName(name))
+	}
+	seen := make(map[string]bool, len(cfg.Targets))
+	for _, t := range cfg.Targets {
+		if seen[t.Name] {
+			problem(fmt.Errorf("target %s is defined more than once, only the first definition is used", t.Name))
+		}
+		seen[t.Name] = true
+	}
+	targets := targetConfigs(cfg.Targets)
+	_, err = targetLabelNames(targets, cfg.Groups)
+	problem(err)
+	for _, t := range cfg.Targets {
+		problem(validateAggregation(t))
+		problem(validateLogFiles(t))
+		for _, lc := range t.Logs {
+			_, err := newLogWatch(t.Name, lc)
+			problem(err)
+		}
+		if t.Journal != nil {
+			_, err := newJournalWatch(t.Name, *t.Journal)
+			problem(err)
+		}
+	}
+	problem(validateTopN(*cfg))
+	_, err = newProbes(cfg.Targets)
+	problem(err)
+	for _, sc := range cfg.Scripts {
+		_, err := newScript(sc)
+		problem(err)
+	}
+	for _, dc := range cfg.Derived {
+		_, err := newDerived(dc)
+		problem(err)
+	}
+	_, err = newAlerter(*cfg)
+	problem(err)
+
+	var docker *dockerClient
+	if cfg.Docker {
+		docker, err = newDockerClient(cfg.DockerSocket)
+		problem(err)
+	}
+	matchers, err := targetMatchers(names, targets, cfg.Exclude, docker)
+	problem(err)
+	_, err = newNameGroups(cfg.Groups, cfg.Exclude, docker)
+	problem(err)
+
+	floor := cfg.CollectInterval
+	if floor <= 0 {
+		floor = CollectInterval
+	}
+	for _, name := range names {
+		t := targets[name]
+		p := PlannedTarget{Name: name, Match: t.Match, Aggregation: t.Aggregation, Interval: max(t.Interval, floor), IncludeChildren: t.IncludeChildren}
+		if m, ok := matchers[name]; ok {
+			p.Match = m.pattern
+		}
+		for _, labels := range []map[string]string{t.Labels, t.EnvLabels, t.CmdlineLabels} {
+			for label := range labels {
+				p.Labels = append(p.Labels, label)
+			}
+		}
+		sort.Strings(p.Labels)
+		c.Targets = append(c.Targets, p)
+	}
+	return c, nil
+}
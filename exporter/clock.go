@@ -0,0 +1,131 @@
+package exporter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CollectInterval 是默认的采集周期的间隔, 同一进程在一个周期内不会被重复采集
+const CollectInterval = 5 * time.Second
+
+// Clock 抽象了 Exporter 使用的时间, 测试中可以用 FakeClock 代替真实时间
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker 是 Clock 创建的定时器
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// realClock 使用系统时间
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTicker(d time.Duration) Ticker { return realTicker{time.NewTicker(d)} }
+
+type realTicker struct{ t *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.t.C }
+
+func (t realTicker) Stop() { t.t.Stop() }
+
+// FakeClock 是只在调用 Advance 时前进的 Clock, 用于编写可重复的测试
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+// NewFakeClock 创建以 now 为当前时间的 FakeClock
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now 返回当前的模拟时间
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTicker 创建一个随 Advance 触发的 Ticker
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTicker{clock: c, period: d, next: c.now.Add(d), c: make(chan time.Time, 1)}
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+// Advance 把时间向前推进 d, 并触发到期的 Ticker.
+// 与 time.Ticker 一样, 接收方来不及处理时多余的触发会被丢弃
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	for _, t := range c.tickers {
+		for !t.next.After(c.now) {
+			select {
+			case t.c <- t.next:
+			default:
+			}
+			t.next = t.next.Add(t.period)
+		}
+	}
+}
+
+type fakeTicker struct {
+	clock  *FakeClock
+	period time.Duration
+	next   time.Time
+	c      chan time.Time
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.c }
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	for i, x := range t.clock.tickers {
+		if x == t {
+			t.clock.tickers = append(t.clock.tickers[:i], t.clock.tickers[i+1:]...)
+			break
+		}
+	}
+}
+
+// Run 每隔一个采集周期调用一次 Update, 直到 ctx 被取消. journal 等需要在后台持续跟踪的数据源也由 Run 启动.
+// 测试中可以不调用 Run, 而是直接调用 Update 同步地执行一个周期. 抓取时采集时 Run 只启动后台数据源
+func (e *Exporter) Run(ctx context.Context) {
+	e.runJournals(ctx)
+	if e.sampleInterval > 0 {
+		go e.runSampler(ctx)
+	}
+	e.heartbeat.Store(e.clock.Now().UnixNano())
+	t := e.clock.NewTicker(e.collectInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C():
+			if !e.active.Load() || e.collectOnScrape {
+				// 备用实例和抓取时采集时不在后台采集, 只记录 Run 仍在运行, 不算作完成了一个周期
+				e.heartbeat.Store(e.clock.Now().UnixNano())
+				continue
+			}
+			// 停止时让进行中的周期完成, 它仍然受 collection_timeout 限制
+			e.Update(context.WithoutCancel(ctx))
+		}
+	}
+}
+
+// CycleInterval 返回采集周期的间隔
+func (e *Exporter) CycleInterval() time.Duration {
+	return e.collectInterval
+}
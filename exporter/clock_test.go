@@ -0,0 +1,198 @@
+package exporter
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// startNamed 以 name 为进程名启动一个 sleep, 测试结束时结束它
+func startNamed(t *testing.T, name string) *exec.Cmd {
+	t.Helper()
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep not found")
+	}
+	data, err := os.ReadFile(sleep)
+	if err != nil {
+		t.Skip(err)
+	}
+	bin := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(bin, data, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(bin, "60")
+	if err := cmd.Start(); err != nil {
+		t.Skip(err)
+	}
+	t.Cleanup(func() { stop(cmd) })
+	return cmd
+}
+
+func stop(cmd *exec.Cmd) {
+	if cmd.ProcessState == nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}
+}
+
+func newClockExporter(t *testing.T, clock *FakeClock, cfg Config) *Exporter {
+	t.Helper()
+	e, err := New(Opts{Config: cfg, Registerer: prometheus.NewRegistry(), Clock: clock})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return e
+}
+
+// 进程以新的 PID 重新出现时 restarts_total 加 1
+func TestUpdateRestarts(t *testing.T) {
+	const name = "fc-restart"
+	cmd := startNamed(t, name)
+	clock := NewFakeClock(time.Unix(1700000000, 0))
+	e := newClockExporter(t, clock, Config{Processes: []string{name}})
+	ctx := context.Background()
+
+	e.Update(ctx)
+	if got := testutil.ToFloat64(e.up.WithLabelValues(name)); got != 1 {
+		t.Fatalf("up = %v, want 1", got)
+	}
+	clock.Advance(CollectInterval)
+	e.Update(ctx)
+	if got := testutil.ToFloat64(e.restarts.WithLabelValues(name)); got != 0 {
+		t.Fatalf("restarts_total = %v with the same PID, want 0", got)
+	}
+
+	stop(cmd)
+	cmd = startNamed(t, name)
+	clock.Advance(defaultPIDRescanInterval)
+	e.Update(ctx)
+	if got := testutil.ToFloat64(e.restarts.WithLabelValues(name)); got != 1 {
+		t.Fatalf("restarts_total = %v after PID change, want 1", got)
+	}
+}
+
+// 进程消失超过 series_ttl 后删除目标的序列
+func TestSeriesTTLExpiry(t *testing.T) {
+	const name = "fc-ttl"
+	cmd := startNamed(t, name)
+	clock := NewFakeClock(time.Unix(1700000000, 0))
+	e := newClockExporter(t, clock, Config{Processes: []string{name}, SeriesTTL: time.Minute})
+	ctx := context.Background()
+
+	e.Update(ctx)
+	stop(cmd)
+	clock.Advance(defaultPIDRescanInterval)
+	e.Update(ctx)
+	if n := testutil.CollectAndCount(e.up); n != 1 {
+		t.Fatalf("got %d up series within series_ttl, want 1", n)
+	}
+	if got := testutil.ToFloat64(e.up.WithLabelValues(name)); got != 0 {
+		t.Fatalf("up = %v after the process exited, want 0", got)
+	}
+
+	clock.Advance(time.Minute)
+	e.Update(ctx)
+	if n := testutil.CollectAndCount(e.up); n != 0 {
+		t.Fatalf("got %d up series after series_ttl, want 0", n)
+	}
+}
+
+// /-/healthy 在最近一个周期距今 3 个采集周期以上时返回 503
+func TestHealthyStaleCycle(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1700000000, 0))
+	e := newClockExporter(t, clock, Config{Processes: []string{"fc-missing"}})
+	h := e.HealthyHandler()
+	status := func() int {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/-/healthy", nil))
+		return w.Code
+	}
+
+	if got := status(); got != http.StatusServiceUnavailable {
+		t.Fatalf("status before the first cycle = %d, want %d", got, http.StatusServiceUnavailable)
+	}
+	e.Update(context.Background())
+	if got := status(); got != http.StatusOK {
+		t.Fatalf("status after a cycle = %d, want %d", got, http.StatusOK)
+	}
+	if got := testutil.ToFloat64(e.self.lastCollect); got != 1700000000 {
+		t.Fatalf("last_collect_timestamp_seconds = %v, want the fake clock time", got)
+	}
+	clock.Advance(3*CollectInterval - time.Second)
+	if got := status(); got != http.StatusOK {
+		t.Fatalf("status within 3 intervals = %d, want %d", got, http.StatusOK)
+	}
+	clock.Advance(time.Second)
+	if got := status(); got != http.StatusServiceUnavailable {
+		t.Fatalf("status after 3 intervals = %d, want %d", got, http.StatusServiceUnavailable)
+	}
+}
+
+// runExporter 在后台运行 e.Run 直到测试结束, 并等待 Run 开始运行
+func runExporter(t *testing.T, e *Exporter) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go e.Run(ctx)
+	waitFor(t, func() bool { return e.heartbeat.Load() != 0 })
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !cond(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+	}
+}
+
+// 备用实例存活但不算作完成了采集周期, /-/ready 返回 503
+func TestStandbyReadiness(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1700000000, 0))
+	e := newClockExporter(t, clock, Config{Processes: []string{"fc-missing"}})
+	e.SetActive(false)
+	runExporter(t, e)
+
+	start := e.heartbeat.Load()
+	clock.Advance(3 * CollectInterval)
+	waitFor(t, func() bool { return e.heartbeat.Load() != start })
+	if err := e.Healthy(); err != nil {
+		t.Fatalf("Healthy = %v in standby, want nil", err)
+	}
+	if err := e.Ready(); err == nil {
+		t.Fatal("Ready = nil in standby, want error")
+	}
+	if _, ok := e.LastCycle(); ok {
+		t.Fatal("standby reported a successful collection cycle")
+	}
+}
+
+// 抓取时采集时第一次成功的抓取之后才就绪
+func TestOnScrapeReadiness(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1700000000, 0))
+	e, err := New(Opts{Config: Config{Processes: []string{"fc-missing"}}, Registerer: prometheus.NewRegistry(),
+		Clock: clock, CollectOnScrape: true})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	runExporter(t, e)
+
+	if err := e.Healthy(); err != nil {
+		t.Fatalf("Healthy = %v before the first scrape, want nil", err)
+	}
+	if err := e.Ready(); err == nil {
+		t.Fatal("Ready = nil before the first scrape, want error")
+	}
+	e.Update(context.Background())
+	if err := e.Ready(); err != nil {
+		t.Fatalf("Ready = %v after a scrape, want nil", err)
+	}
+}
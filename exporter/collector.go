@@ -0,0 +1,82 @@
+package exporter
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"sort"
+	"sync"
+)
+
+// Collector 是第三方采集器需要实现的接口,
+// 在 prometheus.Collector 的基础上还需要提供一个唯一的名称
+type Collector interface {
+	prometheus.Collector
+	Name() string
+}
+
+// CollectorFactory 创建一个 Collector
+type CollectorFactory func() (Collector, error)
+
+// PluginSymbol 是 Go plugin 需要导出的工厂函数名, 类型必须为
+// func() (exporter.Collector, error)
+const PluginSymbol = "NewCollector"
+
+var (
+	factoriesMu sync.Mutex
+	factories   = make(map[string]CollectorFactory)
+)
+
+// RegisterCollector 注册一个采集器工厂, 通常在第三方包的 init 中调用,
+// 之后创建的每个 Exporter 都会带上这个采集器. 名称重复时 panic
+func RegisterCollector(name string, factory CollectorFactory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if factory == nil {
+		panic("exporter: RegisterCollector factory is nil")
+	}
+	if _, dup := factories[name]; dup {
+		panic("exporter: RegisterCollector called twice for collector " + name)
+	}
+	factories[name] = factory
+}
+
+// extraCollectors 按名称顺序创建所有已注册的采集器, 并加载 opts 中的 plugin
+func extraCollectors(opts Opts) ([]Collector, error) {
+	factoriesMu.Lock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fs := make([]CollectorFactory, 0, len(names))
+	for _, name := range names {
+		fs = append(fs, factories[name])
+	}
+	factoriesMu.Unlock()
+
+	cs := append([]Collector(nil), opts.Collectors...)
+	for i, f := range fs {
+		c, err := f()
+		if err != nil {
+			return nil, fmt.Errorf("creating collector %s: %w", names[i], err)
+		}
+		cs = append(cs, c)
+	}
+	for _, path := range opts.Plugins {
+		c, err := LoadPlugin(path)
+		if err != nil {
+			return nil, err
+		}
+		cs = append(cs, c)
+	}
+
+	seen := make(map[string]bool)
+	for _, c := range cs {
+		if seen[c.Name()] {
+			return nil, fmt.Errorf("duplicate collector name %q", c.Name())
+		}
+		seen[c.Name()] = true
+	}
+	return cs, nil
+}
@@ -0,0 +1,306 @@
+package exporter
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"time"
+)
+
+// Config 是 exporter 的声明式配置, 可以从 YAML 文件加载
+type Config struct {
+	// 需要监控的进程名
+	Processes []string `yaml:"processes,omitempty"`
+
+	// 需要额外设置的监控目标, 名称与 Processes 中的进程名含义相同, 两者可以同时使用
+	Targets []TargetConfig `yaml:"targets,omitempty"`
+
+	// 按命令行或环境变量中的名称自动生成的监控目标, 如 java -Dapp.name=foo 生成目标 foo
+	Groups []GroupConfig `yaml:"groups,omitempty"`
+
+	// 是否按所属用户聚合主机上的所有进程, 导出 process_user_* 指标
+	ByUser bool `yaml:"by_user,omitempty"`
+
+	// 大于 0 时导出主机上 CPU 使用率或常驻内存最高的 N 个进程
+	TopN int `yaml:"top_n,omitempty"`
+
+	// 前 N 个进程的排序方式: cpu (默认) 或 memory
+	TopNSort string `yaml:"top_n_sort,omitempty"`
+
+	// 大于 0 时每隔该时间采样一次监控的进程的 CPU 使用率和常驻内存, 导出为直方图, 如 1s
+	SampleInterval time.Duration `yaml:"sample_interval,omitempty"`
+
+	// 是否通过 nvidia-smi (NVML) 查询监控的进程使用的 GPU 显存和使用率
+	GPU bool `yaml:"gpu,omitempty"`
+
+	// 匹配监控目标时排除的进程, 规则与 match 相同, 如 cmdline:grep .* 排除查找进程的脚本
+	Exclude []string `yaml:"exclude,omitempty"`
+
+	// 是否从 /proc/<pid>/smaps_rollup 读取 swap, 包括共享内存交换出去的部分, 并导出 SwapPss
+	Smaps bool `yaml:"smaps,omitempty"`
+
+	// 是否计算监控的进程的可执行文件的 sha256, 并统计磁盘上的文件被替换而进程没有重启的次数
+	BinaryHash bool `yaml:"binary_hash,omitempty"`
+
+	// 是否导出主机的 CPU 时间、内存、负载和进程数, 在小型设备上代替 node_exporter
+	Host bool `yaml:"host,omitempty"`
+
+	// 是否按 CPU 导出监控的进程的 CPU 时间, 用于检查绑核的服务
+	PerCPU bool `yaml:"per_cpu,omitempty"`
+
+	// 在抓取时读取该目录中的 *.prom 文件并一起输出, 与 node_exporter 的 textfile 采集器相同
+	TextfileDirectory string `yaml:"textfile_directory,omitempty"`
+
+	// 是否匹配内核线程 (ps 中显示为 [kswapd0] 的进程), 默认跳过
+	KernelThreads bool `yaml:"kernel_threads,omitempty"`
+
+	// 指标说明和日志的语言: zh 或 en
+	Locale string `yaml:"locale,omitempty"`
+
+	// CPU 和内存指标的单位
+	Units UnitsConfig `yaml:"units,omitempty"`
+
+	// 监控目标指标名的前缀, 为空时为 process. exporter 自身的指标始终以 process_exporter_ 为前缀
+	Namespace string `yaml:"namespace,omitempty"`
+
+	// 过渡期间是否继续以旧的名称 Cpuinfo、Meminfo 和 Pidinfo 导出
+	LegacyNames bool `yaml:"legacy_names,omitempty"`
+
+	// 是否查询监控的进程所在的 Docker 容器, 并允许用 container: 规则按容器名匹配
+	Docker bool `yaml:"docker,omitempty"`
+
+	// Docker API 的 unix socket, 为空时为 /var/run/docker.sock
+	DockerSocket string `yaml:"docker_socket,omitempty"`
+
+	// kubelet 的地址, 如 https://127.0.0.1:10250, 设置后查询监控的进程所在的 pod. 以 DaemonSet 运行时需要 hostPID
+	KubeletURL Secret `yaml:"kubelet_url,omitempty"`
+
+	// 访问 kubelet 时不校验其服务证书
+	KubeletInsecure bool `yaml:"kubelet_insecure,omitempty"`
+
+	// 采集周期的间隔, 为 0 时为 5 秒
+	CollectInterval time.Duration `yaml:"collect_interval,omitempty"`
+
+	// 大于 0 时目标的进程消失超过该时间后删除目标的所有序列, 直到进程重新出现, 如 1h
+	SeriesTTL time.Duration `yaml:"series_ttl,omitempty"`
+
+	// 在内存中保留多长时间的采集结果, 供 /api/v1/export.csv?range= 导出, 为 0 时只能导出当前数据
+	HistoryRetention time.Duration `yaml:"history_retention,omitempty"`
+
+	// 计算 process_memory_growth_bytes_per_hour 使用的时间窗口, 为 0 时为 1 小时
+	MemoryGrowthWindow time.Duration `yaml:"memory_growth_window,omitempty"`
+
+	// 一个采集周期的期限, 超时后本周期跳过剩余的目标, 为 0 时为 4 秒
+	CollectionTimeout time.Duration `yaml:"collection_timeout,omitempty"`
+
+	// 两次完整扫描进程表之间的最长间隔, 期间上一周期匹配到的 PID 仍然存在且匹配时直接复用, 为 0 时为 30 秒
+	PIDRescanInterval time.Duration `yaml:"pid_rescan_interval,omitempty"`
+
+	// 一个周期内读取单个目标的期限, 超时的目标保留上一周期的值, 为 0 时为 1 秒
+	TargetTimeout time.Duration `yaml:"target_timeout,omitempty"`
+
+	// 并发读取目标的 goroutine 数, 为 0 时为 8
+	CollectWorkers int `yaml:"collect_workers,omitempty"`
+
+	// 大于 0 时并发的抓取依次进行, 该时间内的抓取复用上一次的结果, 如 2s
+	CacheTTL time.Duration `yaml:"cache_ttl,omitempty"`
+
+	// 一次抓取中每个第三方采集器的期限, 超时的采集器本次不输出剩余的指标, 为 0 时为 5 秒
+	ScrapeTimeout time.Duration `yaml:"scrape_timeout,omitempty"`
+
+	// 基于采集数据计算派生指标的脚本
+	Scripts []ScriptConfig `yaml:"scripts,omitempty"`
+
+	// 基于所有进程数据计算的派生指标
+	Derived []DerivedConfig `yaml:"derived,omitempty"`
+
+	// 内置的告警规则, 触发和恢复时发送到 AlertWebhook, 用于没有 Prometheus 的主机
+	Alerts []AlertConfig `yaml:"alerts,omitempty"`
+
+	// 告警通知的 webhook
+	AlertWebhook WebhookConfig `yaml:"alert_webhook,omitempty"`
+}
+
+// AlertConfig 描述一条内置告警规则, Expr 和 Down 只能设置一个
+type AlertConfig struct {
+	Name string `yaml:"name,omitempty"`
+
+	// 只对该目标求值, 为空时对所有目标求值
+	Target string `yaml:"target,omitempty"`
+
+	// 与 scripts 相同的 Starlark 表达式, 结果为真时满足条件, 如 "cpu_percent > 90"
+	Expr string `yaml:"expr,omitempty"`
+
+	// 为 true 时目标没有匹配到进程时满足条件
+	Down bool `yaml:"down,omitempty"`
+
+	// 条件持续多长时间后触发, 为 0 时立即触发
+	For time.Duration `yaml:"for,omitempty"`
+
+	// 附加在告警上的标签和摘要, 如 severity: critical
+	Labels  map[string]string `yaml:"labels,omitempty"`
+	Summary string            `yaml:"summary,omitempty"`
+}
+
+// WebhookConfig 描述告警通知发送的地址和格式
+type WebhookConfig struct {
+	URL Secret `yaml:"url,omitempty"`
+
+	// alertmanager (默认, 如 http://alertmanager:9093/api/v2/alerts) 或 slack (incoming webhook)
+	Format string `yaml:"format,omitempty"`
+}
+
+// GroupConfig 描述如何从匹配的进程生成监控目标, 同一名称的进程归入同一个目标
+type GroupConfig struct {
+	// 匹配进程的规则, 与目标的 match 相同
+	Match string `yaml:"match,omitempty"`
+	// 从命令行提取目标名的正则表达式, 有捕获组时使用第一个捕获组, 如 -Dapp\.name=(\S+)
+	NameFromCmdline string `yaml:"name_from_cmdline,omitempty"`
+	// 以该环境变量的值作为目标名, 与 NameFromCmdline 只能设置一个
+	NameFromEnv string `yaml:"name_from_env,omitempty"`
+	// 生成的目标的其他设置, 如 interval、aggregation 和 labels, 其中的 name 和 match 不使用,
+	// 不支持健康检查、日志跟踪和 journal
+	Target TargetConfig `yaml:"target,omitempty"`
+}
+
+// TargetConfig 是单个监控目标的配置
+type TargetConfig struct {
+	// 进程名, 没有设置 Match 时按它精确匹配进程
+	Name string `yaml:"name,omitempty"`
+
+	// 匹配进程的规则, 如 "name:nginx.*" 或 "cmdline:.*-jar app.jar.*", 设置后 Name 只用作 process 标签
+	Match string `yaml:"match,omitempty"`
+
+	// 两次采集之间的最短间隔, 如 30s, 不足 5 秒 (采集周期) 时为 5 秒
+	Interval time.Duration `yaml:"interval,omitempty"`
+
+	// 每次采集后在 interval 之外再随机推迟 0 到 jitter, 让大量间隔相同的目标分散到不同的采集周期
+	Jitter time.Duration `yaml:"jitter,omitempty"`
+
+	// 匹配到多个进程时的聚合方式: first (默认, 只使用第一个进程)、sum、avg、max 或 per_pid
+	Aggregation string `yaml:"aggregation,omitempty"`
+
+	// 为 true 时监控的进程的 CPU、内存、磁盘 I/O、文件描述符数和线程数包括其所有后代进程, 如 postgres、php-fpm, 只支持 Linux
+	IncludeChildren bool `yaml:"include_children,omitempty"`
+
+	// 附加在 process_target_info 上的静态标签, 如 team: payments
+	Labels map[string]string `yaml:"labels,omitempty"`
+
+	// 从监控的进程的环境变量读取的标签, 键为标签名, 值为环境变量名, 如 version: APP_VERSION
+	EnvLabels map[string]string `yaml:"env_labels,omitempty"`
+
+	// 从监控的进程的命令行提取的标签, 键为标签名, 值为正则表达式, 有捕获组时取第一个捕获组, 如 port: '--port[= ](\d+)'
+	CmdlineLabels map[string]string `yaml:"cmdline_labels,omitempty"`
+
+	// 每个采集周期执行一次的健康检查
+	Probe *ProbeConfig `yaml:"probe,omitempty"`
+
+	// 每个采集周期通过 shell 执行一次的检查命令, 如 "mysqladmin ping", 退出码为 0 表示健康
+	CheckCommand Secret `yaml:"check_command,omitempty"`
+
+	// 检查命令的超时时间, 为 0 时为 3 秒
+	CheckTimeout time.Duration `yaml:"check_timeout,omitempty"`
+	// 需要跟踪的日志文件
+	Logs []LogWatchConfig `yaml:"logs,omitempty"`
+	// 需要统计大小和增长的日志文件, 可以使用通配符, 如 /var/log/nginx/*.log
+	LogFiles []string `yaml:"log_files,omitempty"`
+	// 由 systemd 管理的目标可以统计其 unit 在 journal 中的错误日志
+	Journal *JournalConfig `yaml:"journal,omitempty"`
+}
+
+// JournalConfig 描述需要统计的 systemd unit 日志, 不低于 Priority 的条目数导出为
+// process_journal_entries_total{unit,priority}
+type JournalConfig struct {
+	Unit string `yaml:"unit,omitempty"`
+
+	// emerg, alert, crit, err, warning, notice, info 或 debug, 为空时为 err
+	Priority string `yaml:"priority,omitempty"`
+}
+
+// LogWatchConfig 描述一个需要跟踪的日志文件, 新增的行中匹配各个正则表达式的行数
+// 导出为 process_log_lines_total{pattern}
+type LogWatchConfig struct {
+	Path     string   `yaml:"path,omitempty"`
+	Patterns []string `yaml:"patterns,omitempty"`
+}
+
+// ProbeConfig 描述一个健康检查, TCP 和 HTTP 只能设置一个
+type ProbeConfig struct {
+	// 建立 TCP 连接的地址, 如 "127.0.0.1:3306"
+	TCP string `yaml:"tcp,omitempty"`
+
+	// 发送 GET 请求的 URL, 如 "http://127.0.0.1:8080/healthz"
+	HTTP Secret `yaml:"http,omitempty"`
+
+	// HTTP 检查期望的状态码, 为 0 时接受所有 2xx
+	ExpectedStatus int `yaml:"expected_status,omitempty"`
+
+	// 超时时间, 为 0 时为 3 秒
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// TargetNames 返回 Processes 和 Targets 中的所有进程名, 重复的只保留第一个
+func (c Config) TargetNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, name := range c.Processes {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, t := range c.Targets {
+		if !seen[t.Name] {
+			seen[t.Name] = true
+			names = append(names, t.Name)
+		}
+	}
+	return names
+}
+
+// ScriptConfig 描述一个 Starlark 脚本, 它在每个采集周期对每个进程执行一次,
+// 结果以 Name 为指标名、process 为标签导出为 gauge
+type ScriptConfig struct {
+	Name string `yaml:"name,omitempty"`
+	Help string `yaml:"help,omitempty"`
+
+	// 一个 Starlark 表达式, 如 "cpu_percent / 100",
+	// 或者一段给全局变量 value 赋值的 Starlark 程序
+	Source string `yaml:"source,omitempty"`
+}
+
+// DerivedConfig 描述一个派生指标, Expr 是在每个采集周期结束后求值一次的 Starlark 表达式,
+// 其中 cpu_percent 等变量是以进程名为键的 dict, prev_cpu_percent 等为上一周期的值,
+// 例如 "cpu_percent['nginx'] + cpu_percent['php-fpm']" 或
+// "memory_percent['java'] - prev_memory_percent['java']"
+type DerivedConfig struct {
+	Name string `yaml:"name,omitempty"`
+	Help string `yaml:"help,omitempty"`
+	Expr string `yaml:"expr,omitempty"`
+}
+
+// Secret 是配置中的敏感字段, 如令牌和密码, 在 /api/v1/config 等输出中会被隐藏
+type Secret string
+
+const secretToken = "<secret>"
+
+// MarshalYAML 隐藏非空的 Secret
+func (s Secret) MarshalYAML() (interface{}, error) {
+	if s == "" {
+		return nil, nil
+	}
+	return secretToken, nil
+}
+
+// LoadConfig 从 YAML 文件加载配置
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+	cfg := &Config{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,77 @@
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shirou/gopsutil/net"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// connStates 是 network_connections 的 state 标签的取值, none 为 UDP 等没有状态的连接
+var connStates = []string{
+	"established", "syn_sent", "syn_recv", "fin_wait1", "fin_wait2", "time_wait",
+	"close", "close_wait", "last_ack", "listen", "closing", "none", "other",
+}
+
+// countConnStates 按状态统计 TCP 和 UDP 连接数, 忽略 Unix 域套接字
+func countConnStates(conns []net.ConnectionStat) map[string]int {
+	counts := make(map[string]int, len(connStates))
+	for _, s := range connStates {
+		counts[s] = 0
+	}
+	for _, c := range conns {
+		if c.Family == syscall.AF_UNIX {
+			continue
+		}
+		state := strings.ToLower(c.Status)
+		if _, ok := counts[state]; !ok {
+			state = "other"
+		}
+		counts[state]++
+	}
+	return counts
+}
+
+// listenPort 是进程监听的一个端口, proto 为 tcp 或 udp, 不区分 IPv4 和 IPv6
+type listenPort struct {
+	port  string
+	proto string
+}
+
+// listenPorts 返回处于 LISTEN 状态的 TCP 套接字和没有对端地址的 UDP 套接字的端口
+func listenPorts(conns []net.ConnectionStat) map[listenPort]bool {
+	ports := make(map[listenPort]bool)
+	for _, c := range conns {
+		if c.Family == syscall.AF_UNIX || c.Laddr.Port == 0 {
+			continue
+		}
+		port := strconv.Itoa(int(c.Laddr.Port))
+		switch {
+		case c.Type == syscall.SOCK_STREAM && c.Status == "LISTEN":
+			ports[listenPort{port: port, proto: "tcp"}] = true
+		case c.Type == syscall.SOCK_DGRAM && c.Raddr.Port == 0:
+			ports[listenPort{port: port, proto: "udp"}] = true
+		}
+	}
+	return ports
+}
+
+// setListenPorts 导出目标监听的端口, 并删除已经不再监听的端口的序列
+func (e *Exporter) setListenPorts(processName string, ports map[listenPort]bool) {
+	for lp := range e.listening[processName] {
+		if !ports[lp] {
+			e.listenPorts.DeleteLabelValues(processName, lp.port, lp.proto)
+		}
+	}
+	for lp := range ports {
+		e.listenPorts.WithLabelValues(processName, lp.port, lp.proto).Set(1)
+	}
+	e.listening[processName] = ports
+}
+
+// deleteListenPorts 删除目标监听的所有端口的序列
+func (e *Exporter) deleteListenPorts(processName string) {
+	e.listenPorts.DeletePartialMatch(prometheus.Labels{"process": processName})
+	delete(e.listening, processName)
+}
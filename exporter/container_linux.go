@@ -0,0 +1,22 @@
+package exporter
+
+import (
+	"os"
+	"regexp"
+)
+
+// containerIDPattern 匹配 cgroup 路径中的容器 ID, 如 /docker/<id>、docker-<id>.scope 和 cri-containerd-<id>.scope
+var containerIDPattern = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// containerID 根据 /proc/<pid>/cgroup 返回进程所在容器的 ID, 不在容器中时返回空字符串
+func containerID(pid int32) string {
+	data, err := os.ReadFile(procPath(pid, "cgroup"))
+	if err != nil {
+		return ""
+	}
+	ids := containerIDPattern.FindAll(data, -1)
+	if len(ids) == 0 {
+		return ""
+	}
+	return string(ids[len(ids)-1])
+}
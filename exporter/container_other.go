@@ -0,0 +1,8 @@
+//go:build !linux
+
+package exporter
+
+// containerID 只在 Linux 上能够识别容器
+func containerID(pid int32) string {
+	return ""
+}
@@ -0,0 +1,25 @@
+package exporter
+
+// cpuTime 记录一个目标上一次采集时监控的进程及其 CPU 时间
+type cpuTime struct {
+	pid          int32
+	user, system float64
+
+	// 为 true 时下一次采集只记录基线, 用于从其他实例接管计数器之后
+	baseline bool
+}
+
+// updateCPUTime 把监控的进程自上次采集以来的用户态和内核态 CPU 时间累加到
+// cpu_user_seconds_total 和 cpu_system_seconds_total. 进程重启后计入新进程启动以来的全部 CPU 时间,
+// 因此计数器不会因为重启而减小
+func (e *Exporter) updateCPUTime(processName string, pid int32, user, system float64) {
+	du, ds := user, system
+	if last, ok := e.cpuTime[processName]; ok && last.pid == pid {
+		du, ds = user-last.user, system-last.system
+	} else if ok && last.baseline {
+		du, ds = 0, 0
+	}
+	e.cpuTime[processName] = &cpuTime{pid: pid, user: user, system: system}
+	e.cpuUser.WithLabelValues(processName).Add(max(du, 0))
+	e.cpuSystem.WithLabelValues(processName).Add(max(ds, 0))
+}
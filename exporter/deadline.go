@@ -0,0 +1,57 @@
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"time"
+)
+
+// 默认的采集周期和抓取期限
+const (
+	defaultCollectionTimeout = 4 * time.Second
+	defaultScrapeTimeout     = 5 * time.Second
+	// 一个周期内读取单个目标的期限
+	defaultTargetTimeout = time.Second
+)
+
+// defaultCollectWorkers 是并发读取目标的默认 goroutine 数
+const defaultCollectWorkers = 8
+
+// timeoutCollector 限制第三方采集器在一次抓取中的耗时, 超时后丢弃它之后输出的指标,
+// 其他采集器的结果照常返回, 一个卡住的采集器不会导致整个抓取失败
+type timeoutCollector struct {
+	Collector
+	timeout  time.Duration
+	timeouts *prometheus.CounterVec
+}
+
+func (c *timeoutCollector) Collect(ch chan<- prometheus.Metric) {
+	buf := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.Collector.Collect(buf)
+	}()
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case m := <-buf:
+			ch <- m
+		case <-done:
+			return
+		case <-timer.C:
+			c.timeouts.WithLabelValues(c.Name()).Inc()
+			// 采集器返回前继续读取并丢弃, 避免它永远阻塞
+			go func() {
+				for {
+					select {
+					case <-buf:
+					case <-done:
+						return
+					}
+				}
+			}()
+			return
+		}
+	}
+}
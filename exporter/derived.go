@@ -0,0 +1,113 @@
+package exporter
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+	"sort"
+)
+
+// derived 是编译好的派生指标表达式
+type derived struct {
+	name  string
+	expr  syntax.Expr
+	gauge *prometheus.GaugeVec
+}
+
+func newDerived(cfg DerivedConfig) (*derived, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("derived metric without name")
+	}
+	expr, err := syntax.ParseExpr(cfg.Name, cfg.Expr, 0)
+	if err != nil {
+		return nil, fmt.Errorf("parsing derived metric %s: %w", cfg.Name, err)
+	}
+	help := cfg.Help
+	if help == "" {
+		help = T("help.derived", cfg.Expr)
+	}
+	return &derived{
+		name: cfg.Name,
+		expr: expr,
+		gauge: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: cfg.Name,
+			Help: help,
+		}, nil),
+	}, nil
+}
+
+// derivedEnv 构造表达式求值环境: 每个变量是以进程名为键的 dict
+func derivedEnv(cur, prev map[string]*Stats) starlark.StringDict {
+	env := starlark.StringDict{
+		"sum": starlark.NewBuiltin("sum", starlarkSum),
+	}
+	for prefix, stats := range map[string]map[string]*Stats{"": cur, "prev_": prev} {
+		names := make([]string, 0, len(stats))
+		for name := range stats {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		dicts := make(map[string]*starlark.Dict, len(statsVars))
+		for _, v := range statsVars {
+			dicts[v] = starlark.NewDict(len(names))
+			env[prefix+v] = dicts[v]
+		}
+		for _, name := range names {
+			for k, val := range stats[name].values() {
+				dicts[k].SetKey(starlark.String(name), starlark.Float(val))
+			}
+		}
+	}
+	return env
+}
+
+// starlarkSum 对可迭代对象 (如 dict 的 values()) 求和
+func starlarkSum(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var iterable starlark.Iterable
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &iterable); err != nil {
+		return nil, err
+	}
+	iter := iterable.Iterate()
+	defer iter.Done()
+
+	var total float64
+	var x starlark.Value
+	for iter.Next(&x) {
+		f, ok := starlark.AsFloat(x)
+		if !ok {
+			return nil, fmt.Errorf("%s: got %s, want number", b.Name(), x.Type())
+		}
+		total += f
+	}
+	return starlark.Float(total), nil
+}
+
+// evalDerived 基于本周期和上一周期的数据计算所有派生指标
+func (e *Exporter) evalDerived() {
+	if len(e.derived) == 0 {
+		return
+	}
+	env := derivedEnv(e.stats, e.prevStats)
+	for _, d := range e.derived {
+		thread := &starlark.Thread{Name: d.name}
+		thread.SetMaxExecutionSteps(100000)
+
+		res, err := starlark.EvalExpr(thread, d.expr, env)
+		if err != nil {
+			e.logger.Error(T("log.derived"), "derived", d.name, "error", err)
+			d.gauge.Reset()
+			continue
+		}
+		v, ok, err := toFloat(res)
+		if err != nil {
+			e.logger.Error(T("log.derived"), "derived", d.name, "error", err)
+		}
+		if !ok {
+			d.gauge.Reset()
+			continue
+		}
+		d.gauge.WithLabelValues().Set(v)
+	}
+}
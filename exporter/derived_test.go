@@ -0,0 +1,34 @@
+package exporter
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"testing"
+	"time"
+)
+
+// 派生指标在每个采集周期结束后对所有目标的数据求值
+func TestDerived(t *testing.T) {
+	startNamed(t, "dv-a")
+	startNamed(t, "dv-b")
+	clock := NewFakeClock(time.Unix(1700000000, 0))
+	e := newClockExporter(t, clock, Config{
+		Processes: []string{"dv-a", "dv-b", "dv-missing"},
+		Derived: []DerivedConfig{
+			{Name: "dv_threads", Expr: "num_threads['dv-a'] + num_threads['dv-b']"},
+			{Name: "dv_found", Expr: "len(pid)"},
+			{Name: "dv_missing", Expr: "num_threads['dv-missing']"},
+		},
+	})
+	e.Update(context.Background())
+	if got := testutil.ToFloat64(e.derived[0].gauge.WithLabelValues()); got != 2 {
+		t.Fatalf("dv_threads = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(e.derived[1].gauge.WithLabelValues()); got != 2 {
+		t.Fatalf("dv_found = %v, want 2", got)
+	}
+	// 没有找到的目标不在 dict 中, 求值出错时不输出序列
+	if n := testutil.CollectAndCount(e.derived[2].gauge); n != 0 {
+		t.Fatalf("got %d dv_missing series, want 0", n)
+	}
+}
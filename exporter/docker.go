@@ -0,0 +1,100 @@
+//go:build !nodocker
+
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDockerSocket 是 Docker API 默认的 unix socket
+const DefaultDockerSocket = "/var/run/docker.sock"
+
+// dockerClient 通过 Docker API 查询容器名, 结果按容器 ID 缓存
+type dockerClient struct {
+	client *http.Client
+
+	mu    sync.Mutex
+	names map[string]string
+}
+
+func newDockerClient(socket string) (*dockerClient, error) {
+	if socket == "" {
+		socket = DefaultDockerSocket
+	}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}
+	return &dockerClient{
+		client: &http.Client{Transport: transport, Timeout: 2 * time.Second},
+		names:  make(map[string]string),
+	}, nil
+}
+
+// containerName 返回容器 ID 对应的容器名 (不带开头的 /)
+func (d *dockerClient) containerName(ctx context.Context, id string) (string, error) {
+	d.mu.Lock()
+	name, ok := d.names[id]
+	d.mu.Unlock()
+	if ok {
+		return name, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://docker/containers/"+id+"/json", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("inspecting container %s: %s", id, resp.Status)
+	}
+	var c struct{ Name string }
+	if err := json.NewDecoder(resp.Body).Decode(&c); err != nil {
+		return "", fmt.Errorf("inspecting container %s: %w", id, err)
+	}
+	name = strings.TrimPrefix(c.Name, "/")
+
+	d.mu.Lock()
+	// 容器频繁创建和删除时避免缓存无限增长
+	if len(d.names) >= 4096 {
+		clear(d.names)
+	}
+	d.names[id] = name
+	d.mu.Unlock()
+	return name, nil
+}
+
+// setContainer 导出监控的进程所在的容器, 容器变化时删除旧的序列
+func (e *Exporter) setContainer(ctx context.Context, processName string, pid int32) {
+	id := containerID(pid)
+	name := ""
+	if id != "" {
+		var err error
+		if name, err = e.docker.containerName(ctx, id); err != nil {
+			e.logger.Debug(T("log.container_name"), "target", processName, "container_id", id, "error", err)
+		}
+	}
+	key := id + "/" + name
+	if e.containers[processName] == key {
+		return
+	}
+	e.containerInfo.DeletePartialMatch(prometheus.Labels{"process": processName})
+	e.containers[processName] = key
+	if id != "" {
+		e.containerInfo.WithLabelValues(processName, id, name).Set(1)
+	}
+}
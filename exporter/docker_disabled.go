@@ -0,0 +1,27 @@
+//go:build nodocker
+
+package exporter
+
+import (
+	"context"
+	"errors"
+)
+
+// DefaultDockerSocket 是 Docker API 默认的 unix socket
+const DefaultDockerSocket = "/var/run/docker.sock"
+
+var errNoDocker = errors.New("built without Docker support (nodocker)")
+
+// dockerClient 在使用 nodocker 标签构建时不可用
+type dockerClient struct{}
+
+// newDockerClient 在使用 nodocker 标签构建时总是返回错误
+func newDockerClient(socket string) (*dockerClient, error) {
+	return nil, errNoDocker
+}
+
+func (d *dockerClient) containerName(ctx context.Context, id string) (string, error) {
+	return "", errNoDocker
+}
+
+func (e *Exporter) setContainer(ctx context.Context, processName string, pid int32) {}
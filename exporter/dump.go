@@ -0,0 +1,80 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/prometheus/common/expfmt"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// dump 是写入快照文件的内容
+type dump struct {
+	Time    time.Time         `json:"time"`
+	Config  string            `json:"config"`
+	State   []targetStateView `json:"state"`
+	Stats   []Stats           `json:"stats"`
+	Metrics string            `json:"metrics"`
+}
+
+// WriteDump 把当前所有序列 (文本格式) 和内部状态写入 dir 下以时间命名的 JSON 文件, 返回文件路径,
+// 便于附在问题报告中或事后分析. 配置中的 Secret 字段已被隐藏
+func (e *Exporter) WriteDump(dir string) (string, error) {
+	mfs, err := e.gatherer.Gather()
+	if err != nil {
+		return "", fmt.Errorf("gathering metrics: %w", err)
+	}
+	var metrics bytes.Buffer
+	enc := expfmt.NewEncoder(&metrics, expfmt.FmtText)
+	for _, mf := range mfs {
+		if err := enc.Encode(mf); err != nil {
+			return "", err
+		}
+	}
+
+	e.mutex.Lock()
+	d := dump{
+		Time:    e.clock.Now(),
+		State:   e.stateViews(),
+		Stats:   e.snapshot(),
+		Metrics: metrics.String(),
+	}
+	cfg, err := yaml.Marshal(e.config)
+	e.mutex.Unlock()
+	if err != nil {
+		return "", err
+	}
+	d.Config = string(cfg)
+
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "process-exporter-dump-"+d.Time.UTC().Format("20060102T150405Z")+".json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", err
+	}
+	e.logger.Info(T("log.dump"), "path", path)
+	return path, nil
+}
+
+// DumpHandler 返回在 POST 时把快照写入 dir 并返回文件路径的 http.Handler
+func (e *Exporter) DumpHandler(dir string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost && r.Method != http.MethodPut {
+			w.Header().Set("Allow", "POST, PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		path, err := e.WriteDump(dir)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintln(w, path)
+	})
+}
@@ -0,0 +1,56 @@
+package exporter
+
+import (
+	"context"
+	"github.com/shirou/gopsutil/process"
+	"maps"
+	"regexp"
+	"strings"
+)
+
+// readDynamicLabels 从监控的进程的环境变量和命令行读取目标的 env_labels 和 cmdline_labels,
+// 读取失败 (如没有权限读取其他用户的环境变量) 或没有匹配到时标签为空
+func readDynamicLabels(ctx context.Context, t TargetConfig, p *process.Process) map[string]string {
+	if len(t.EnvLabels) == 0 && len(t.CmdlineLabels) == 0 {
+		return nil
+	}
+	labels := make(map[string]string, len(t.EnvLabels)+len(t.CmdlineLabels))
+	if len(t.EnvLabels) > 0 {
+		env := make(map[string]string)
+		if environ, err := p.EnvironWithContext(ctx); err == nil {
+			for _, kv := range environ {
+				if k, v, ok := strings.Cut(kv, "="); ok {
+					env[k] = v
+				}
+			}
+		}
+		for label, name := range t.EnvLabels {
+			labels[label] = sanitizeName(env[name])
+		}
+	}
+	if len(t.CmdlineLabels) > 0 {
+		cmdline, _ := p.CmdlineWithContext(ctx)
+		for label, expr := range t.CmdlineLabels {
+			// 已在加载配置时校验过
+			re := regexp.MustCompile(expr)
+			value := ""
+			if m := re.FindStringSubmatch(cmdline); m != nil {
+				// 有捕获组时使用第一个捕获组, 否则使用整个匹配
+				value = m[0]
+				if len(m) > 1 {
+					value = m[1]
+				}
+			}
+			labels[label] = sanitizeName(value)
+		}
+	}
+	return labels
+}
+
+// setDynamicLabels 记录目标的动态标签, 取值变化时删除 process_target_info 上旧的序列
+func (e *Exporter) setDynamicLabels(processName string, labels map[string]string) {
+	if !maps.Equal(e.dynamicLabels[processName], labels) {
+		e.targetInfo.DeletePartialMatch(map[string]string{"process": processName})
+	}
+	e.dynamicLabels[processName] = labels
+}
@@ -0,0 +1,12 @@
+//go:build !windows
+
+package exporter
+
+import (
+	"errors"
+	"log/slog"
+)
+
+func newEventLogHandler(source string, opts *slog.HandlerOptions) (slog.Handler, error) {
+	return nil, errors.New("the Windows event log is only available on Windows")
+}
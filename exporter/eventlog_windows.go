@@ -0,0 +1,79 @@
+//go:build windows
+
+package exporter
+
+import (
+	"context"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// eventID 是写入事件日志时使用的事件 ID
+const eventID = 1
+
+// newEventLogHandler 创建写入 Windows 事件日志的 handler, 日志来源 source 不存在时尝试注册
+// (需要管理员权限, 失败时日志仍可写入, 只是事件查看器中没有消息说明)
+func newEventLogHandler(source string, opts *slog.HandlerOptions) (slog.Handler, error) {
+	eventlog.InstallAsEventCreate(source, eventlog.Error|eventlog.Warning|eventlog.Info)
+	l, err := eventlog.Open(source)
+	if err != nil {
+		return nil, err
+	}
+	w := &eventLogWriter{log: l}
+	// 事件日志自带时间和级别, 只保留消息和属性
+	hopts := *opts
+	hopts.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == slog.LevelKey) {
+			return slog.Attr{}
+		}
+		return a
+	}
+	return &eventLogHandler{next: slog.NewTextHandler(w, &hopts), w: w}, nil
+}
+
+// eventLogWriter 把 TextHandler 输出的一行写为一条事件, 级别由 eventLogHandler 在写入前设置
+type eventLogWriter struct {
+	mu    sync.Mutex
+	log   *eventlog.Log
+	level slog.Level
+}
+
+func (w *eventLogWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimSuffix(string(p), "\n")
+	var err error
+	switch {
+	case w.level >= slog.LevelError:
+		err = w.log.Error(eventID, msg)
+	case w.level >= slog.LevelWarn:
+		err = w.log.Warning(eventID, msg)
+	default:
+		err = w.log.Info(eventID, msg)
+	}
+	return len(p), err
+}
+
+type eventLogHandler struct {
+	next slog.Handler
+	w    *eventLogWriter
+}
+
+func (h *eventLogHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.next.Enabled(ctx, l)
+}
+
+func (h *eventLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &eventLogHandler{next: h.next.WithAttrs(attrs), w: h.w}
+}
+
+func (h *eventLogHandler) WithGroup(name string) slog.Handler {
+	return &eventLogHandler{next: h.next.WithGroup(name), w: h.w}
+}
+
+func (h *eventLogHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.w.mu.Lock()
+	defer h.w.mu.Unlock()
+	h.w.level = r.Level
+	return h.next.Handle(ctx, r)
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package exporter
+
+// trimExe 只在 Windows 上去掉 .exe 后缀
+func trimExe(name string) string {
+	return name
+}
+
+// sameName 判断进程名 name 是否为 exact
+func sameName(exact, name string) bool {
+	return exact == name
+}
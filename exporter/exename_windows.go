@@ -0,0 +1,16 @@
+package exporter
+
+import "strings"
+
+// trimExe 去掉进程名的 .exe 后缀, 使 Windows 上的目标可以写成 nginx 而不是 nginx.exe
+func trimExe(name string) string {
+	if len(name) > 4 && strings.EqualFold(name[len(name)-4:], ".exe") {
+		return name[:len(name)-4]
+	}
+	return name
+}
+
+// sameName 判断进程名 name 是否为 exact, Windows 上的进程名不区分大小写, 且可以省略 .exe 后缀
+func sameName(exact, name string) bool {
+	return strings.EqualFold(exact, name) || strings.EqualFold(exact, trimExe(name))
+}
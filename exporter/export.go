@@ -0,0 +1,67 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var exportHeader = []string{"timestamp", "process", "pid", "cpu_percent", "memory_percent", "memory_rss_bytes", "num_fds", "status"}
+
+// exportHandler 以 CSV (comma 为 ',') 或 TSV (comma 为 '\t') 导出数据.
+// 不带 range 参数时导出当前数据, range=15m 时导出缓存中最近 15 分钟的数据
+func (e *Exporter) exportHandler(comma rune, contentType string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var samples []sample
+		if rng := r.URL.Query().Get("range"); rng != "" {
+			d, err := time.ParseDuration(rng)
+			if err != nil || d <= 0 {
+				http.Error(w, fmt.Sprintf("invalid range %q", rng), http.StatusBadRequest)
+				return
+			}
+			e.mutex.Lock()
+			samples = e.history.since(e.clock.Now().Add(-d))
+			e.mutex.Unlock()
+		} else {
+			samples = []sample{{time: e.clock.Now(), stats: e.Snapshot()}}
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		cw := csv.NewWriter(w)
+		cw.Comma = comma
+		cw.Write(exportHeader)
+		// 被跳过采集的周期会重复上一次的数据, 只输出一次
+		last := make(map[string]time.Time)
+		for _, smp := range samples {
+			for _, s := range smp.stats {
+				if s.PID == 0 || (!s.Time.IsZero() && last[s.Process].Equal(s.Time)) {
+					continue
+				}
+				last[s.Process] = s.Time
+				ts := s.Time
+				if ts.IsZero() {
+					ts = smp.time
+				}
+				cw.Write([]string{
+					ts.UTC().Format(time.RFC3339),
+					s.Process,
+					strconv.Itoa(int(s.PID)),
+					strconv.FormatFloat(s.CPUPercent, 'f', -1, 64),
+					strconv.FormatFloat(s.MemoryPercent, 'f', -1, 64),
+					strconv.FormatUint(s.MemoryRSS, 10),
+					strconv.Itoa(int(s.NumFDs)),
+					s.Status,
+				})
+			}
+		}
+		cw.Flush()
+	}
+}
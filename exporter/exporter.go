@@ -0,0 +1,1475 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shirou/gopsutil/process"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"io/fs"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// errNotFound 表示没有找到匹配的进程
+var errNotFound = errors.New("process not found")
+
+// tracerName 是创建 span 时使用的 instrumentation 名称
+const tracerName = "github.com/qishu321/exporter/exporter"
+
+// Opts 是创建 Exporter 所需的参数
+type Opts struct {
+	// 监控的进程和派生指标等配置
+	Config Config
+
+	// 指标注册到的 Registerer, 为空时使用 prometheus.DefaultRegisterer
+	Registerer prometheus.Registerer
+
+	// 输出和打印指标时使用的 Gatherer, 为空时若 Registerer 同时实现了
+	// prometheus.Gatherer (如 *prometheus.Registry) 则使用它, 否则使用 prometheus.DefaultGatherer
+	Gatherer prometheus.Gatherer
+
+	// 额外注册的第三方采集器, 与 RegisterCollector 注册的采集器一起生效
+	Collectors []Collector
+
+	// 需要加载的 Go plugin 路径, 每个 plugin 需导出 NewCollector
+	Plugins []string
+
+	// 日志输出, 为空时使用 slog.Default()
+	Logger *slog.Logger
+
+	// 为采集周期创建 span 的 TracerProvider, 为空时使用 otel.GetTracerProvider()
+	TracerProvider trace.TracerProvider
+
+	// 为直方图指标同时提供 native histogram (仅 protobuf 格式可见)
+	NativeHistograms bool
+
+	// 采集使用的时钟, 为空时使用系统时间. 测试中可以传入 FakeClock
+	Clock Clock
+
+	// 在每次抓取时采集, 而不是由 Run 每 5 秒在后台采集一次, 见 ProcessCollector
+	CollectOnScrape bool
+
+	// 允许通过 POST 和 DELETE /api/v1/targets 修改监控目标, 默认关闭
+	AdminAPI bool
+}
+
+// Exporter 采集进程指标并写入注册的 GaugeVec
+type Exporter struct {
+	config       Config
+	processNames []string
+	gatherer     prometheus.Gatherer
+	logger       *slog.Logger
+	self         *selfMetrics
+	tracer       trace.Tracer
+	clock        Clock
+	scripts      []*script
+	derived      []*derived
+
+	// targets 中的目标配置, 每个目标的匹配规则和 process_target_info 的标签名
+	targets        map[string]TargetConfig
+	matchers       map[string]*matcher
+	infoLabelNames []string
+
+	// groups 规则, 由它们生成的目标及其所属的规则, 以及上一次为生成目标扫描进程表的时间
+	nameGroups []*nameGroup
+	generated  map[string]*nameGroup
+	groupsAt   time.Time
+
+	// CPU 百分比的换算系数和内存指标的单位
+	cpuScale float64
+	memUnit  string
+
+	cpuUsage *prometheus.GaugeVec
+	memUsage *prometheus.GaugeVec
+	pidUsage *prometheus.GaugeVec
+
+	// 目标是否匹配到了进程
+	up *prometheus.GaugeVec
+
+	// 启用 Docker 发现时监控的进程所在的容器, 以及每个目标上一次导出的容器
+	docker        *dockerClient
+	containerInfo *prometheus.GaugeVec
+	containers    map[string]string
+
+	// 设置了 kubelet_url 时监控的进程所在的 pod, 以及每个目标上一次导出的 pod
+	kubelet *kubeletClient
+	podInfo *prometheus.GaugeVec
+	pods    map[string]podRef
+
+	// 设置了 binary_hash 时监控的进程的可执行文件的校验和, 以及磁盘上的文件与正在运行的不同的次数
+	binarySums    *binarySums
+	binaryInfo    *prometheus.GaugeVec
+	binaryChanges *prometheus.CounterVec
+	binaries      map[string]binaryState
+
+	// 设置了 by_user 时按所属用户聚合的进程数、常驻内存和 CPU 时间
+	users     *users
+	userProcs *prometheus.GaugeVec
+	userRSS   *prometheus.GaugeVec
+	userCPU   *prometheus.CounterVec
+
+	// 设置了 top_n 时主机上 CPU 使用率或常驻内存最高的进程
+	topN   *topN
+	topCPU *prometheus.GaugeVec
+	topRSS *prometheus.GaugeVec
+
+	// 配置了 alerts 时的内置告警
+	alerter *alerter
+
+	// 设置了 sample_interval 时在采集周期之间采样的 CPU 使用率和常驻内存的分布
+	sampleInterval time.Duration
+	cpuHist        *prometheus.HistogramVec
+	rssHist        *prometheus.HistogramVec
+
+	// 目标的信息指标, incomplete 为 true 表示部分数据因权限不足无法读取, 同时带有目标的静态标签
+	targetInfo *prometheus.GaugeVec
+
+	// aggregation 为 per_pid 的目标中每个进程的数据, 以及每个目标上次导出的 PID
+	pidCPU    *prometheus.GaugeVec
+	pidMemory *prometheus.GaugeVec
+	pidRSS    *prometheus.GaugeVec
+	perPID    map[string]map[string]bool
+
+	// 在运行队列中等待 CPU 的时间和 CPU 饱和度
+	cpuWait       *prometheus.CounterVec
+	cpuSaturation *prometheus.GaugeVec
+	sched         map[string]schedSample
+
+	// 等待块设备 I/O 的时间, 来自 delay accounting
+	ioWait  *prometheus.CounterVec
+	ioDelay map[string]ioDelaySample
+
+	// 监控的进程累计的用户态和内核态 CPU 时间
+	cpuUser   *prometheus.CounterVec
+	cpuSystem *prometheus.CounterVec
+	cpuTime   map[string]*cpuTime
+
+	// 设置了 per_cpu 时监控的进程在每个 CPU 上累计的 CPU 时间, 以及进程允许运行的 CPU
+	coreSeconds  *prometheus.CounterVec
+	coreCPU      map[string]*coreCPU
+	affinityInfo *prometheus.GaugeVec
+	affinity     map[string]string
+
+	// 监控的进程累计读写的字节数和次数
+	ioReadBytes  *prometheus.CounterVec
+	ioWriteBytes *prometheus.CounterVec
+	ioReads      *prometheus.CounterVec
+	ioWrites     *prometheus.CounterVec
+	ioCount      map[string]*ioCount
+
+	// 常驻内存、虚拟内存、交换到 swap 和共享内存的字节数
+	memRSS    *prometheus.GaugeVec
+	memVMS    *prometheus.GaugeVec
+	memSwap   *prometheus.GaugeVec
+	memShared *prometheus.GaugeVec
+	// 开启 smaps 时按共享进程数均摊的 swap 字节数
+	memSwapPss *prometheus.GaugeVec
+
+	// 常驻内存的增长速度, 用于发现缓慢的内存泄漏
+	memGrowth *prometheus.GaugeVec
+	growth    map[string]*growth
+
+	// 按类型统计的打开的文件描述符数
+	openFiles *prometheus.GaugeVec
+
+	// 按状态统计的 TCP 和 UDP 连接数
+	connections *prometheus.GaugeVec
+	states      *prometheus.GaugeVec
+
+	// 监控的进程的调度优先级、nice 值和 OOM 分数
+	priority    *prometheus.GaugeVec
+	nice        *prometheus.GaugeVec
+	oomScore    *prometheus.GaugeVec
+	oomScoreAdj *prometheus.GaugeVec
+
+	// 监听的 TCP 和 UDP 端口, 以及每个目标上一次导出的端口
+	listenPorts *prometheus.GaugeVec
+	listening   map[string]map[listenPort]bool
+
+	// 线程数和累计的主动、被动上下文切换次数
+	numThreads     *prometheus.GaugeVec
+	voluntaryCtx   *prometheus.CounterVec
+	involuntaryCtx *prometheus.CounterVec
+	ctxSwitches    map[string]*ctxSwitches
+
+	// 累计的次缺页和主缺页次数, 主缺页需要从磁盘 (包括 swap) 读入
+	minorFaults *prometheus.CounterVec
+	majorFaults *prometheus.CounterVec
+	pageFaults  map[string]*pageFaults
+
+	// 监控的进程所在 cgroup 的内存和 CPU 限制, 以及用量与限制之比
+	cgroupMemLimit *prometheus.GaugeVec
+	cgroupMemRatio *prometheus.GaugeVec
+	cgroupCPUQuota *prometheus.GaugeVec
+	cgroupCPURatio *prometheus.GaugeVec
+	cgroupCPU      map[string]*cgroupCPU
+
+	// 设置了 gpu 时本周期每个进程的 GPU 用量, 以及每个目标上一次导出的 GPU
+	gpuProcs  map[int32]map[string]*gpuUsage
+	gpuMemory *prometheus.GaugeVec
+	gpuUtil   *prometheus.GaugeVec
+	gpus      map[string]map[string]bool
+
+	// 每个目标最近一次从环境变量和命令行读取的动态标签
+	dynamicLabels map[string]map[string]string
+
+	// 打开的文件描述符总数及其上限
+	openFDs *prometheus.GaugeVec
+	maxFDs  *prometheus.GaugeVec
+
+	// 所有匹配进程 CPU 时间和读写字节数之和, 带有增长最多的 PID 作为 exemplar
+	groupCPUSeconds *prometheus.CounterVec
+	groupIORead     *prometheus.CounterVec
+	groupIOWrite    *prometheus.CounterVec
+	groupCPU        map[string]*groupCPU
+
+	// 健康检查及其结果
+	probes        []*probe
+	probeUp       *prometheus.GaugeVec
+	probeDuration *prometheus.GaugeVec
+	checkExitCode *prometheus.GaugeVec
+
+	// 跟踪的日志文件和匹配的行数
+	logWatches []*logWatch
+	logLines   *prometheus.CounterVec
+
+	// log_files 匹配到的文件的大小和累计写入的字节数, 以及上一周期每个文件的大小
+	logFileSize    *prometheus.GaugeVec
+	logFileWritten *prometheus.CounterVec
+	logFiles       map[string]map[string]int64
+
+	// 跟踪的 systemd unit 和其中的日志条目数
+	journals       []*journalWatch
+	journalEntries *prometheus.CounterVec
+
+	// 目标匹配到的进程中新出现和消失的进程数
+	starts *prometheus.CounterVec
+	exits  *prometheus.CounterVec
+
+	// 监控的进程的启动时间和重启次数
+	startTime *prometheus.GaugeVec
+	restarts  *prometheus.CounterVec
+
+	// 监控的进程新创建的子进程数
+	childrenSpawned *prometheus.CounterVec
+	children        map[string]*children
+
+	// 第三方采集器
+	collectors []Collector
+
+	// 按名称分组的所有采集器, 包括第三方采集器
+	groups map[string][]prometheus.Collector
+
+	// 使用互斥锁确保在更新指标时不被同时执行
+	mutex sync.Mutex
+
+	// 串行化 /api/v1/targets 对配置的修改
+	apiMutex sync.Mutex
+
+	// 存储每个进程上次更新的时间戳
+	lastUpdate map[string]time.Time
+	// 设置了 jitter 的目标本次额外推迟的时间
+	delays map[string]time.Duration
+
+	// 每个进程的内部状态, 供 /debug/state 查看
+	state map[string]*targetState
+
+	// 每个进程最近一次采集到的数据, 以及上一周期的数据
+	stats     map[string]*Stats
+	prevStats map[string]*Stats
+
+	// 供 CSV/TSV 导出使用的历史数据
+	history history
+
+	// 最近一次完成采集周期的时间 (UnixNano) 和是否有过成功的周期, 不受 mutex 保护,
+	// 以便采集卡住时 watchdog 仍能读取
+	lastCycle atomic.Int64
+	succeeded atomic.Bool
+	// 备用实例和抓取时采集时 Run 最近一次运行的时间 (UnixNano), 只用于判断 exporter 是否仍然存活
+	heartbeat atomic.Int64
+
+	// 是否为主实例, 备用实例不采集数据, /metrics 只输出 exporter 自身的指标
+	active atomic.Bool
+
+	// 是否在每次抓取时采集
+	collectOnScrape bool
+
+	// 是否允许通过 API 修改监控目标
+	adminAPI bool
+
+	// 采集周期的间隔
+	collectInterval time.Duration
+}
+
+// New 创建 Exporter 并把指标注册到 opts.Registerer
+func New(opts Opts) (*Exporter, error) {
+	reg := opts.Registerer
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	gatherer := opts.Gatherer
+	if gatherer == nil {
+		if g, ok := reg.(prometheus.Gatherer); ok {
+			gatherer = g
+		} else {
+			gatherer = prometheus.DefaultGatherer
+		}
+	}
+
+	ns := opts.Config.Namespace
+	if ns == "" {
+		ns = namespace
+	}
+	cpuOpts, cpuScale, err := cpuGaugeOpts(opts.Config.Units.CPU)
+	if err != nil {
+		return nil, err
+	}
+	memOpts, err := memGaugeOpts(opts.Config.Units.Memory)
+	if err != nil {
+		return nil, err
+	}
+	cpuOpts.Namespace, memOpts.Namespace = ns, ns
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	for _, name := range opts.Config.TargetNames() {
+		if err := validateTargetName(name); err != nil {
+			return nil, err
+		}
+	}
+
+	targets := targetConfigs(opts.Config.Targets)
+	infoLabelNames, err := targetLabelNames(targets, opts.Config.Groups)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range targets {
+		if err := validateAggregation(t); err != nil {
+			return nil, err
+		}
+		if err := validateLogFiles(t); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateTopN(opts.Config); err != nil {
+		return nil, err
+	}
+	var docker *dockerClient
+	if opts.Config.Docker {
+		if docker, err = newDockerClient(opts.Config.DockerSocket); err != nil {
+			return nil, err
+		}
+	}
+	matchers, err := targetMatchers(opts.Config.TargetNames(), targets, opts.Config.Exclude, docker)
+	if err != nil {
+		return nil, err
+	}
+	nameGroups, err := newNameGroups(opts.Config.Groups, opts.Config.Exclude, docker)
+	if err != nil {
+		return nil, err
+	}
+
+	nativeFactor := 0.0
+	if opts.NativeHistograms {
+		nativeFactor = nativeHistogramBucketFactor
+	}
+
+	clock := opts.Clock
+	if clock == nil {
+		clock = realClock{}
+	}
+
+	tp := opts.TracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+
+	e := &Exporter{
+		config:       opts.Config,
+		processNames: opts.Config.TargetNames(),
+
+		targets:        targets,
+		matchers:       matchers,
+		infoLabelNames: infoLabelNames,
+		nameGroups:     nameGroups,
+		generated:      make(map[string]*nameGroup),
+		logger:         logger,
+		self:           newSelfMetrics(opts.NativeHistograms),
+		tracer:         tp.Tracer(tracerName),
+		clock:          clock,
+		gatherer:       gatherer,
+		cpuScale:       cpuScale,
+		memUnit:        opts.Config.Units.Memory,
+		cpuUsage:       prometheus.NewGaugeVec(cpuOpts, []string{"process"}),
+		memUsage:       prometheus.NewGaugeVec(memOpts, []string{"process"}),
+		groupCPUSeconds: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "group_cpu_seconds_total",
+			Help:      T("help.group_cpu_seconds_total"),
+		}, []string{"process"}),
+		groupIORead: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "group_io_read_bytes_total",
+			Help:      T("help.group_io_read_bytes_total"),
+		}, []string{"process"}),
+		groupIOWrite: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "group_io_write_bytes_total",
+			Help:      T("help.group_io_write_bytes_total"),
+		}, []string{"process"}),
+		groupCPU: make(map[string]*groupCPU),
+		childrenSpawned: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "children_spawned_total",
+			Help:      T("help.children_spawned_total"),
+		}, []string{"process"}),
+		children: make(map[string]*children),
+		probeUp: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "healthcheck_up",
+			Help:      T("help.healthcheck_up"),
+		}, []string{"process", "type"}),
+		probeDuration: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "healthcheck_duration_seconds",
+			Help:      T("help.healthcheck_duration_seconds"),
+		}, []string{"process", "type"}),
+		checkExitCode: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "check_command_exit_code",
+			Help:      T("help.check_command_exit_code"),
+		}, []string{"process"}),
+		logLines: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "log_lines_total",
+			Help:      T("help.log_lines_total"),
+		}, []string{"process", "path", "pattern"}),
+		logFileSize: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "logfile_size_bytes",
+			Help:      T("help.logfile_size_bytes"),
+		}, []string{"process", "path"}),
+		logFileWritten: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "logfile_written_bytes_total",
+			Help:      T("help.logfile_written_bytes_total"),
+		}, []string{"process", "path"}),
+		logFiles: make(map[string]map[string]int64),
+		journalEntries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "journal_entries_total",
+			Help:      T("help.journal_entries_total"),
+		}, []string{"process", "unit", "priority"}),
+		starts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "starts_total",
+			Help:      T("help.starts_total"),
+		}, []string{"process"}),
+		startTime: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "start_time_seconds",
+			Help:      T("help.start_time_seconds"),
+		}, []string{"process"}),
+		restarts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "restarts_total",
+			Help:      T("help.restarts_total"),
+		}, []string{"process"}),
+		exits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "exits_total",
+			Help:      T("help.exits_total"),
+		}, []string{"process"}),
+		cpuWait: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "cpu_wait_seconds_total",
+			Help:      T("help.cpu_wait_seconds_total"),
+		}, []string{"process"}),
+		pidCPU: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "pid_cpu_percent",
+			Help:      T("help.pid_cpu_percent"),
+		}, []string{"process", "pid"}),
+		pidMemory: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "pid_memory_percent",
+			Help:      T("help.pid_memory_percent"),
+		}, []string{"process", "pid"}),
+		pidRSS: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "pid_memory_rss_bytes",
+			Help:      T("help.pid_memory_rss_bytes"),
+		}, []string{"process", "pid"}),
+		perPID: make(map[string]map[string]bool),
+		cpuSaturation: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "cpu_saturation_ratio",
+			Help:      T("help.cpu_saturation_ratio"),
+		}, []string{"process"}),
+		sched: make(map[string]schedSample),
+		ioWait: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "io_wait_seconds_total",
+			Help:      T("help.io_wait_seconds_total"),
+		}, []string{"process"}),
+		ioDelay: make(map[string]ioDelaySample),
+		cpuUser: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "cpu_user_seconds_total",
+			Help:      T("help.cpu_user_seconds_total"),
+		}, []string{"process"}),
+		cpuSystem: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "cpu_system_seconds_total",
+			Help:      T("help.cpu_system_seconds_total"),
+		}, []string{"process"}),
+		cpuTime: make(map[string]*cpuTime),
+		coreSeconds: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "cpu_core_seconds_total",
+			Help:      T("help.cpu_core_seconds_total"),
+		}, []string{"process", "cpu"}),
+		coreCPU: make(map[string]*coreCPU),
+		affinityInfo: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "cpu_affinity_info",
+			Help:      T("help.cpu_affinity_info"),
+		}, []string{"process", "cpus"}),
+		affinity: make(map[string]string),
+		ioReadBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "io_read_bytes_total",
+			Help:      T("help.io_read_bytes_total"),
+		}, []string{"process"}),
+		ioWriteBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "io_write_bytes_total",
+			Help:      T("help.io_write_bytes_total"),
+		}, []string{"process"}),
+		ioReads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "io_reads_total",
+			Help:      T("help.io_reads_total"),
+		}, []string{"process"}),
+		ioWrites: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "io_writes_total",
+			Help:      T("help.io_writes_total"),
+		}, []string{"process"}),
+		ioCount: make(map[string]*ioCount),
+		memRSS: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "memory_rss_bytes",
+			Help:      T("help.memory_rss_bytes"),
+		}, []string{"process"}),
+		memVMS: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "memory_vms_bytes",
+			Help:      T("help.memory_vms_bytes"),
+		}, []string{"process"}),
+		memSwap: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "memory_swap_bytes",
+			Help:      T("help.memory_swap_bytes"),
+		}, []string{"process"}),
+		memShared: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "memory_shared_bytes",
+			Help:      T("help.memory_shared_bytes"),
+		}, []string{"process"}),
+		memSwapPss: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "memory_swap_pss_bytes",
+			Help:      T("help.memory_swap_pss_bytes"),
+		}, []string{"process"}),
+		memGrowth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "memory_growth_bytes_per_hour",
+			Help:      T("help.memory_growth_bytes_per_hour"),
+		}, []string{"process"}),
+		growth: make(map[string]*growth),
+		openFiles: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "open_files",
+			Help:      T("help.open_files"),
+		}, []string{"process", "type"}),
+		states: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "states",
+			Help:      T("help.states"),
+		}, []string{"process", "state"}),
+		priority: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "priority",
+			Help:      T("help.priority"),
+		}, []string{"process"}),
+		nice: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "nice",
+			Help:      T("help.nice"),
+		}, []string{"process"}),
+		oomScore: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "oom_score",
+			Help:      T("help.oom_score"),
+		}, []string{"process"}),
+		oomScoreAdj: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "oom_score_adj",
+			Help:      T("help.oom_score_adj"),
+		}, []string{"process"}),
+		connections: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "network_connections",
+			Help:      T("help.network_connections"),
+		}, []string{"process", "state"}),
+		listenPorts: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "listen_ports",
+			Help:      T("help.listen_ports"),
+		}, []string{"process", "port", "proto"}),
+		listening: make(map[string]map[listenPort]bool),
+		numThreads: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "num_threads",
+			Help:      T("help.num_threads"),
+		}, []string{"process"}),
+		voluntaryCtx: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "voluntary_ctxt_switches_total",
+			Help:      T("help.voluntary_ctxt_switches_total"),
+		}, []string{"process"}),
+		involuntaryCtx: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "involuntary_ctxt_switches_total",
+			Help:      T("help.involuntary_ctxt_switches_total"),
+		}, []string{"process"}),
+		ctxSwitches: make(map[string]*ctxSwitches),
+		minorFaults: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "minor_page_faults_total",
+			Help:      T("help.minor_page_faults_total"),
+		}, []string{"process"}),
+		majorFaults: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "major_page_faults_total",
+			Help:      T("help.major_page_faults_total"),
+		}, []string{"process"}),
+		pageFaults: make(map[string]*pageFaults),
+		cgroupMemLimit: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "cgroup_memory_limit_bytes",
+			Help:      T("help.cgroup_memory_limit_bytes"),
+		}, []string{"process"}),
+		cgroupMemRatio: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "cgroup_memory_usage_ratio",
+			Help:      T("help.cgroup_memory_usage_ratio"),
+		}, []string{"process"}),
+		cgroupCPUQuota: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "cgroup_cpu_quota_cores",
+			Help:      T("help.cgroup_cpu_quota_cores"),
+		}, []string{"process"}),
+		cgroupCPURatio: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "cgroup_cpu_usage_ratio",
+			Help:      T("help.cgroup_cpu_usage_ratio"),
+		}, []string{"process"}),
+		cgroupCPU: make(map[string]*cgroupCPU),
+		gpuMemory: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "gpu_memory_bytes",
+			Help:      T("help.gpu_memory_bytes"),
+		}, []string{"process", "gpu"}),
+		gpuUtil: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "gpu_utilization_percent",
+			Help:      T("help.gpu_utilization_percent"),
+		}, []string{"process", "gpu"}),
+		gpus:          make(map[string]map[string]bool),
+		dynamicLabels: make(map[string]map[string]string),
+		openFDs: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "open_fds",
+			Help:      T("help.open_fds"),
+		}, []string{"process"}),
+		maxFDs: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "max_fds",
+			Help:      T("help.max_fds"),
+		}, []string{"process"}),
+		targetInfo: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "target_info",
+			Help:      T("help.target_info"),
+		}, infoLabelNames),
+		pidUsage: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "pid",
+			Help:      T("help.pid"),
+		}, []string{"process"}),
+		docker: docker,
+		containerInfo: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "container_info",
+			Help:      T("help.container_info"),
+		}, []string{"process", "container_id", "container_name"}),
+		containers: make(map[string]string),
+		binarySums: newBinarySums(),
+		binaryInfo: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "binary_info",
+			Help:      T("help.binary_info"),
+		}, []string{"process", "sha256", "path"}),
+		binaryChanges: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "binary_changes_total",
+			Help:      T("help.binary_changes_total"),
+		}, []string{"process"}),
+		binaries: make(map[string]binaryState),
+		podInfo: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "pod_info",
+			Help:      T("help.pod_info"),
+		}, []string{"process", "namespace", "pod", "container"}),
+		pods: make(map[string]podRef),
+		userProcs: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "user_processes",
+			Help:      T("help.user_processes"),
+		}, []string{"user"}),
+		userRSS: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "user_memory_rss_bytes",
+			Help:      T("help.user_memory_rss_bytes"),
+		}, []string{"user"}),
+		userCPU: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "user_cpu_seconds_total",
+			Help:      T("help.user_cpu_seconds_total"),
+		}, []string{"user"}),
+		topCPU: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "top_cpu_usage_percent",
+			Help:      T("help.top_cpu_usage_percent"),
+		}, []string{"name", "pid"}),
+		topRSS: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "top_memory_rss_bytes",
+			Help:      T("help.top_memory_rss_bytes"),
+		}, []string{"name", "pid"}),
+		sampleInterval: opts.Config.SampleInterval,
+		cpuHist: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns,
+			Name:      "cpu_percent_histogram",
+			Help:      T("help.cpu_percent_histogram"),
+			Buckets:   sampleCPUBuckets,
+
+			NativeHistogramBucketFactor: nativeFactor,
+		}, []string{"process"}),
+		rssHist: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns,
+			Name:      "memory_rss_bytes_histogram",
+			Help:      T("help.memory_rss_bytes_histogram"),
+			Buckets:   sampleRSSBuckets,
+
+			NativeHistogramBucketFactor: nativeFactor,
+		}, []string{"process"}),
+		up: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "up",
+			Help:      T("help.up"),
+		}, []string{"process"}),
+		lastUpdate: make(map[string]time.Time),
+		delays:     make(map[string]time.Duration),
+		state:      make(map[string]*targetState),
+		history:    history{retention: opts.Config.HistoryRetention},
+		stats:      make(map[string]*Stats),
+
+		collectOnScrape: opts.CollectOnScrape,
+		adminAPI:        opts.AdminAPI,
+		collectInterval: opts.Config.CollectInterval,
+	}
+	if e.collectInterval <= 0 {
+		e.collectInterval = CollectInterval
+	}
+
+	if opts.Config.KubeletURL != "" {
+		if e.kubelet, err = newKubeletClient(string(opts.Config.KubeletURL), opts.Config.KubeletInsecure); err != nil {
+			return nil, err
+		}
+	}
+	if e.probes, err = newProbes(opts.Config.Targets); err != nil {
+		return nil, err
+	}
+	if e.logWatches, err = e.newLogWatches(opts.Config.Targets, nil); err != nil {
+		return nil, err
+	}
+	for _, t := range opts.Config.Targets {
+		if t.Journal != nil {
+			j, err := newJournalWatch(t.Name, *t.Journal)
+			if err != nil {
+				return nil, err
+			}
+			e.journals = append(e.journals, j)
+		}
+	}
+	for _, sc := range opts.Config.Scripts {
+		script, err := newScript(sc)
+		if err != nil {
+			return nil, err
+		}
+		e.scripts = append(e.scripts, script)
+	}
+	if e.alerter, err = newAlerter(opts.Config); err != nil {
+		return nil, err
+	}
+	for _, dc := range opts.Config.Derived {
+		d, err := newDerived(dc)
+		if err != nil {
+			return nil, err
+		}
+		e.derived = append(e.derived, d)
+	}
+
+	// 按 /metrics?collect[]= 可以选择的名称分组
+	e.groups = map[string][]prometheus.Collector{
+		"cpu":         {e.cpuUsage, e.cpuUser, e.cpuSystem, e.groupCPUSeconds, e.cpuWait, e.cpuSaturation, e.pidCPU, e.cpuHist, e.coreSeconds},
+		"memory":      {e.memUsage, e.memRSS, e.memVMS, e.memSwap, e.memShared, e.memSwapPss, e.memGrowth, e.pidMemory, e.pidRSS, e.minorFaults, e.majorFaults, e.rssHist},
+		"pid":         {e.pidUsage, e.up, e.states, e.priority, e.nice, e.oomScore, e.oomScoreAdj},
+		"info":        {e.targetInfo, e.containerInfo, e.podInfo, e.binaryInfo, e.binaryChanges, e.affinityInfo},
+		"fds":         {e.openFiles, e.openFDs, e.maxFDs},
+		"io":          {e.ioReadBytes, e.ioWriteBytes, e.ioReads, e.ioWrites, e.ioWait, e.groupIORead, e.groupIOWrite},
+		"threads":     {e.numThreads, e.voluntaryCtx, e.involuntaryCtx},
+		"connections": {e.connections, e.listenPorts},
+		"churn":       {e.childrenSpawned, e.starts, e.exits, e.startTime, e.restarts},
+		"healthcheck": {e.probeUp, e.probeDuration, e.checkExitCode},
+		"logs":        {e.logLines, e.logFileSize, e.logFileWritten},
+		"journal":     {e.journalEntries},
+		"cgroup":      {e.cgroupMemLimit, e.cgroupMemRatio, e.cgroupCPUQuota, e.cgroupCPURatio},
+		"gpu":         {e.gpuMemory, e.gpuUtil},
+		"users":       {e.userProcs, e.userRSS, e.userCPU},
+		"topn":        {e.topCPU, e.topRSS},
+		"host":        platformCollectors(ns),
+		"textfile":    {},
+		"exporter":    e.self.collectors(),
+	}
+	if opts.Config.Host {
+		e.groups["host"] = append(e.groups["host"], newHostCollector(ns))
+	}
+	if opts.Config.TextfileDirectory != "" {
+		e.groups["textfile"] = append(e.groups["textfile"], newTextfileCollector(ns, opts.Config.TextfileDirectory, logger))
+	}
+	// 过渡期间继续以旧的名称导出
+	if opts.Config.LegacyNames {
+		if cpuOpts.Name == "cpu_usage_percent" {
+			e.groups["cpu"] = append(e.groups["cpu"], newLegacyCollector(e.cpuUsage, "Cpuinfo", T("help.cpu_percent")))
+		}
+		if memOpts.Name == "memory_usage_percent" {
+			e.groups["memory"] = append(e.groups["memory"], newLegacyCollector(e.memUsage, "Meminfo", T("help.memory_percent")))
+		}
+		e.groups["pid"] = append(e.groups["pid"], newLegacyCollector(e.pidUsage, "Pidinfo", T("help.pid")))
+	}
+	for _, sc := range e.scripts {
+		e.groups["scripts"] = append(e.groups["scripts"], sc.gauge)
+	}
+	for _, d := range e.derived {
+		e.groups["derived"] = append(e.groups["derived"], d.gauge)
+	}
+	var builtins []prometheus.Collector
+	for _, name := range append(append([]string(nil), builtinGroups...), "scripts", "derived") {
+		builtins = append(builtins, e.groups[name]...)
+	}
+	// 抓取时采集的模式下所有内置指标由一个 ProcessCollector 输出
+	if opts.CollectOnScrape {
+		builtins = []prometheus.Collector{&ProcessCollector{e: e, collectors: builtins}}
+	}
+	for _, c := range builtins {
+		if err := reg.Register(c); err != nil {
+			return nil, fmt.Errorf("registering metrics: %w", err)
+		}
+	}
+
+	cs, err := extraCollectors(opts)
+	if err != nil {
+		return nil, err
+	}
+	scrapeTimeout := opts.Config.ScrapeTimeout
+	if scrapeTimeout <= 0 {
+		scrapeTimeout = defaultScrapeTimeout
+	}
+	for _, c := range cs {
+		tc := &timeoutCollector{Collector: c, timeout: scrapeTimeout, timeouts: e.self.collTimeouts}
+		if err := reg.Register(tc); err != nil {
+			return nil, fmt.Errorf("registering collector %s: %w", c.Name(), err)
+		}
+		if _, dup := e.groups[c.Name()]; dup {
+			return nil, fmt.Errorf("collector name %q conflicts with a built-in collector", c.Name())
+		}
+		e.groups[c.Name()] = []prometheus.Collector{tc}
+	}
+	e.collectors = cs
+	e.SetActive(true)
+	return e, nil
+}
+
+// Update 采集一次所有进程的指标
+func (e *Exporter) Update(ctx context.Context) {
+	ctx, span := e.tracer.Start(ctx, "collect")
+	defer span.End()
+
+	e.discoverGroups(ctx)
+	// 配置可能被 Reload 替换, 先取出本周期使用的期限、健康检查和每个目标的匹配规则
+	e.mutex.Lock()
+	timeout := e.config.CollectionTimeout
+	targetTimeout := e.config.TargetTimeout
+	workers := e.config.CollectWorkers
+	probes := e.probes
+	now := e.clock.Now()
+	rescan := e.config.PIDRescanInterval
+	if rescan <= 0 {
+		rescan = defaultPIDRescanInterval
+	}
+	var binaries *binarySums
+	if e.config.BinaryHash {
+		binaries = e.binarySums
+	}
+	jobs := make([]readJob, 0, len(e.processNames))
+	for _, name := range e.processNames {
+		last, ok := e.lastUpdate[name]
+		st := e.targetState(name)
+		jobs = append(jobs, readJob{name: name, m: e.matchers[name], target: e.targets[name],
+			kernelThreads: e.config.KernelThreads, smaps: e.config.Smaps, perCPU: e.config.PerCPU, binaries: binaries, due: !ok || !now.Before(e.nextCollection(name, last)),
+			cached: st.pids, rescan: now.Sub(st.scannedAt) >= rescan})
+	}
+	e.mutex.Unlock()
+	if timeout <= 0 {
+		timeout = min(defaultCollectionTimeout, e.collectInterval)
+	}
+	if targetTimeout <= 0 {
+		targetTimeout = defaultTargetTimeout
+	}
+	if workers <= 0 {
+		workers = defaultCollectWorkers
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := e.clock.Now()
+	result := "success"
+	defer func() {
+		e.self.cycles.WithLabelValues(result).Inc()
+		e.self.cycleDuration.Observe(e.clock.Now().Sub(start).Seconds())
+		e.lastCycle.Store(e.clock.Now().UnixNano())
+		e.self.lastCollect.Set(float64(e.clock.Now().UnixNano()) / 1e9)
+		if result == "success" {
+			e.succeeded.Store(true)
+		}
+	}()
+
+	e.runProbes(ctx, probes)
+	reads := e.readTargets(ctx, jobs, workers, targetTimeout)
+
+	// 使用互斥锁确保在更新指标时不被同时执行
+	_, lockSpan := e.tracer.Start(ctx, "wait-lock")
+	e.mutex.Lock()
+	lockSpan.End()
+	defer e.mutex.Unlock()
+
+	e.prevStats = make(map[string]*Stats, len(e.stats))
+	for name, s := range e.stats {
+		e.prevStats[name] = s
+	}
+
+	// 所有目标共用一次 nvidia-smi 的结果
+	e.gpuProcs = nil
+	if e.config.GPU {
+		var err error
+		if e.gpuProcs, err = gpuProcesses(ctx); err != nil {
+			e.logger.Warn(T("log.gpu"), "error", err)
+		}
+	}
+
+	for _, processName := range e.processNames {
+		r, ok := reads[processName]
+		// 读取期间 Reload 增加或修改的目标在下一周期采集
+		if !ok || (r != nil && r.pattern != e.matchers[processName].pattern) {
+			continue
+		}
+		// 读取超时的目标保留上一周期的值
+		if r == nil || ctx.Err() != nil {
+			e.self.targetTimeouts.WithLabelValues(processName).Inc()
+			result = "timeout"
+			continue
+		}
+		// 一个目标失败不影响其他目标
+		if err := e.updateTarget(ctx, processName, r); err != nil {
+			if ctx.Err() != nil {
+				e.self.targetTimeouts.WithLabelValues(processName).Inc()
+				result = "timeout"
+				continue
+			}
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			if result == "success" {
+				result = "error"
+			}
+		}
+	}
+
+	e.expireTargets(e.clock.Now())
+	e.updateUsers(ctx)
+	e.updateTopN(ctx)
+	e.pollLogs()
+	e.updateLogFiles()
+	e.evalScripts()
+	e.evalDerived()
+	e.evalAlerts()
+	e.history.add(e.clock.Now(), e.snapshot())
+}
+
+// errorType 返回目标采集失败的原因, 用作 process_exporter_collect_errors_total 的 error_type 标签:
+// 没有匹配到进程或进程在读取期间退出时为 not_found, 权限不足时为 permission_denied, 其余为 other
+func errorType(err error) string {
+	switch {
+	case errors.Is(err, errNotFound) || errors.Is(err, process.ErrorProcessNotRunning) || errors.Is(err, fs.ErrNotExist):
+		return "not_found"
+	case errors.Is(err, fs.ErrPermission):
+		return "permission_denied"
+	}
+	return "other"
+}
+
+// updateTarget 根据 worker 读取的数据更新单个进程的指标
+func (e *Exporter) updateTarget(ctx context.Context, processName string, r *targetRead) (err error) {
+	ctx, span := e.tracer.Start(ctx, "collect-target", trace.WithAttributes(attribute.String("target", processName)))
+	start := e.clock.Now()
+	st := e.targetState(processName)
+	notFound := false
+	defer func() {
+		if notFound {
+			st.observe(start, errNotFound)
+			// 已过期的目标不再重新创建序列
+			if !st.expired {
+				e.self.collectErrors.WithLabelValues(processName, errorType(errNotFound)).Inc()
+			}
+		} else {
+			st.observe(start, err)
+		}
+		if err != nil {
+			e.self.collectErrors.WithLabelValues(processName, errorType(err)).Inc()
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	pids := r.pids
+	if r.scanned {
+		st.scannedAt = start
+	}
+	// 已过期的目标在进程重新出现之前不再创建序列
+	if st.expired {
+		if len(pids) == 0 {
+			notFound = true
+			return nil
+		}
+		st.expired = false
+	}
+	if len(pids) > 0 {
+		st.lastSeen = start
+	}
+	e.updateChurn(processName, st.pids, pids, st.lastAttempt.IsZero())
+	st.pids = pids
+	pid := 0
+	if len(pids) > 0 {
+		pid = int(pids[0])
+	}
+	e.updateRestarts(processName, st, int32(pid))
+	span.SetAttributes(attribute.Int("pid", pid))
+	if pid == 0 {
+		// 如果进程不存在, process_up 为 0 并删除其他序列, 以免与空闲的进程混淆
+		e.up.WithLabelValues(processName).Set(0)
+		e.cpuUsage.DeleteLabelValues(processName)
+		e.memUsage.DeleteLabelValues(processName)
+		e.pidUsage.DeleteLabelValues(processName)
+		e.targetInfo.DeletePartialMatch(prometheus.Labels{"process": processName})
+		delete(e.dynamicLabels, processName)
+		e.openFiles.DeletePartialMatch(prometheus.Labels{"process": processName})
+		e.connections.DeletePartialMatch(prometheus.Labels{"process": processName})
+		e.deleteListenPorts(processName)
+		e.states.DeletePartialMatch(prometheus.Labels{"process": processName})
+		e.priority.DeleteLabelValues(processName)
+		e.nice.DeleteLabelValues(processName)
+		e.oomScore.DeleteLabelValues(processName)
+		e.oomScoreAdj.DeleteLabelValues(processName)
+		e.deleteCgroup(processName)
+		e.deleteGPU(processName)
+		e.containerInfo.DeletePartialMatch(prometheus.Labels{"process": processName})
+		delete(e.containers, processName)
+		e.podInfo.DeletePartialMatch(prometheus.Labels{"process": processName})
+		delete(e.pods, processName)
+		e.deleteBinary(processName)
+		e.deleteAffinity(processName)
+		e.openFDs.DeleteLabelValues(processName)
+		e.maxFDs.DeleteLabelValues(processName)
+		e.startTime.DeleteLabelValues(processName)
+		e.numThreads.DeleteLabelValues(processName)
+		e.memGrowth.DeleteLabelValues(processName)
+		e.cpuSaturation.DeleteLabelValues(processName)
+		e.cpuHist.DeleteLabelValues(processName)
+		e.rssHist.DeleteLabelValues(processName)
+		delete(e.growth, processName)
+		delete(e.stats, processName)
+		e.deletePerPID(processName)
+		e.deleteMemory(processName)
+		notFound = true
+		return nil
+	}
+	e.up.WithLabelValues(processName).Set(1)
+
+	// 检查是否需要更新，避免在短时间内频繁更新导致数据丢失
+	if !r.due {
+		return nil
+	}
+
+	logger := e.logger.With("target", processName, "pid", pid)
+	if r.err != nil {
+		logger.Warn(T("log.get_process"), "error", r.err, "duration", e.clock.Now().Sub(start))
+		return r.err
+	}
+
+	// 没有权限读取的数据 (如非 root 读取其他用户的进程) 跳过并计数, 其余数据照常导出
+	incomplete := false
+	denied := func(field string, err error) bool {
+		if !errors.Is(err, fs.ErrPermission) {
+			return false
+		}
+		e.self.denials.WithLabelValues(processName, field).Inc()
+		logger.Debug(T("log.permission_denied"), "field", field, "error", err)
+		incomplete = true
+		return true
+	}
+
+	// 进程的 CPU 使用率
+	cpuOK := r.cpuErr == nil
+	if r.cpuErr != nil && !denied("cpu", r.cpuErr) {
+		logger.Warn(T("log.get_cpu_percent"), "error", r.cpuErr, "duration", e.clock.Now().Sub(start))
+		return r.cpuErr
+	}
+
+	// 进程的 mem 使用率
+	memOK := r.memPercentErr == nil
+	if r.memPercentErr != nil && !denied("memory_percent", r.memPercentErr) {
+		logger.Warn(T("log.get_mem_percent"), "error", r.memPercentErr, "duration", e.clock.Now().Sub(start))
+		return r.memPercentErr
+	}
+
+	// 进程的常驻内存
+	memInfo := r.memInfo
+	if memInfo == nil {
+		memInfo = &process.MemoryInfoStat{}
+	}
+	rssOK := r.memInfoErr == nil
+	if r.memInfoErr != nil && !denied("memory_rss", r.memInfoErr) {
+		logger.Warn(T("log.get_mem_info"), "error", r.memInfoErr, "duration", e.clock.Now().Sub(start))
+		return r.memInfoErr
+	}
+
+	// 更新上次更新时间
+	now := e.clock.Now()
+	e.lastUpdate[processName] = now
+	if j := e.targets[processName].Jitter; j > 0 {
+		e.delays[processName] = rand.N(j)
+	}
+	s := &Stats{
+		Process:       processName,
+		PID:           int32(pid),
+		CPUPercent:    r.cpuPercent,
+		MemoryPercent: float64(r.memPercent),
+		MemoryRSS:     memInfo.RSS,
+		MemoryVMS:     memInfo.VMS,
+		MemorySwap:    memInfo.Swap,
+		Time:          now,
+	}
+	sharedOK := false
+	if r.sharedErr == nil {
+		s.MemoryShared, sharedOK = r.shared, true
+	} else {
+		denied("memory_shared", r.sharedErr)
+	}
+	// smaps 中的 Swap 还包括共享内存交换出去的部分, 读取失败时使用 VmSwap
+	swapPssOK := false
+	if r.smaps {
+		if r.smapsErr == nil {
+			s.MemorySwap, s.MemorySwapPss, swapPssOK = r.swap, r.swapPss, true
+		} else {
+			denied("memory_swap_pss", r.smapsErr)
+		}
+	}
+	if r.timesErr == nil {
+		e.updateCPUTime(processName, s.PID, r.times.User, r.times.System)
+	} else {
+		denied("cpu_times", r.timesErr)
+	}
+	// include_children 时 CPU、内存、I/O 和文件描述符数包括所有后代进程
+	kids := r.kids
+	if r.ioErr == nil {
+		e.updateIO(processName, s.PID, r.io, r.kidsIO)
+	} else {
+		denied("io", r.ioErr)
+	}
+	// 文件描述符和状态只用于展示, 读取失败 (如没有权限) 时不影响其他指标
+	fdsOK := false
+	if r.fdsErr == nil {
+		s.NumFDs, fdsOK = r.fds, true
+	} else {
+		denied("num_fds", r.fdsErr)
+	}
+	threadsOK := false
+	if r.threadsErr == nil {
+		s.NumThreads, threadsOK = r.threads, true
+	} else {
+		denied("num_threads", r.threadsErr)
+	}
+	if r.ctxSwitchesErr == nil {
+		e.updateCtxSwitches(processName, s.PID, r.ctxSwitches.Voluntary, r.ctxSwitches.Involuntary)
+	} else {
+		denied("ctx_switches", r.ctxSwitchesErr)
+	}
+	if r.pageFaultsErr == nil {
+		e.updatePageFaults(processName, s.PID, r.pageFaults.MinorFaults, r.pageFaults.MajorFaults)
+	} else {
+		denied("page_faults", r.pageFaultsErr)
+	}
+	if r.maxFDsErr == nil {
+		e.maxFDs.WithLabelValues(processName).Set(r.maxFDs)
+	} else {
+		denied("max_fds", r.maxFDsErr)
+		e.maxFDs.DeleteLabelValues(processName)
+	}
+	if r.fdTypesErr == nil {
+		for t, n := range r.fdTypes {
+			e.openFiles.WithLabelValues(processName, t).Set(float64(n))
+		}
+	} else if denied("fd_types", r.fdTypesErr) {
+		e.openFiles.DeletePartialMatch(prometheus.Labels{"process": processName})
+	}
+	if r.connsErr == nil {
+		for state, n := range countConnStates(r.conns) {
+			e.connections.WithLabelValues(processName, state).Set(float64(n))
+		}
+		e.setListenPorts(processName, listenPorts(r.conns))
+	} else if denied("connections", r.connsErr) {
+		e.connections.DeletePartialMatch(prometheus.Labels{"process": processName})
+		e.deleteListenPorts(processName)
+	}
+	if r.affinityErr == nil {
+		e.setAffinity(processName, r.affinity)
+	} else {
+		e.deleteAffinity(processName)
+	}
+	if r.threadCPUErr == nil && r.threadCPU != nil {
+		e.updateCoreCPU(processName, s.PID, r.threadCPU)
+	} else if r.threadCPUErr != nil {
+		denied("cpu_core", r.threadCPUErr)
+	}
+	if r.priorityErr == nil {
+		e.priority.WithLabelValues(processName).Set(float64(r.priority))
+		e.nice.WithLabelValues(processName).Set(float64(r.nice))
+	} else {
+		e.priority.DeleteLabelValues(processName)
+		e.nice.DeleteLabelValues(processName)
+	}
+	if r.oomErr == nil {
+		e.oomScore.WithLabelValues(processName).Set(float64(r.oomScore))
+		e.oomScoreAdj.WithLabelValues(processName).Set(float64(r.oomScoreAdj))
+	} else {
+		denied("oom_score", r.oomErr)
+		e.oomScore.DeleteLabelValues(processName)
+		e.oomScoreAdj.DeleteLabelValues(processName)
+	}
+	if r.createdErr == nil {
+		e.startTime.WithLabelValues(processName).Set(float64(r.created) / 1000)
+		s.StartTime = time.UnixMilli(r.created)
+	} else {
+		denied("start_time", r.createdErr)
+		e.startTime.DeleteLabelValues(processName)
+	}
+	s.Status = stateName(r.status)
+	addTree(s, kids)
+	if mode := e.targets[processName].Aggregation; mode != "" && mode != AggregateFirst {
+		e.aggregate(ctx, processName, mode, s, pids)
+	}
+	e.stats[processName] = s
+
+	// include_children 时 group_cpu_seconds_total 也包括后代进程
+	groupPIDs := pids
+	if len(kids) > 0 {
+		groupPIDs = slices.Clone(pids)
+		for _, ps := range kids {
+			groupPIDs = append(groupPIDs, ps.PID)
+		}
+	}
+	e.updateGroupCPU(ctx, processName, groupPIDs)
+	e.updateStates(ctx, processName, groupPIDs)
+	if e.config.GPU {
+		e.updateGPU(processName, groupPIDs)
+	} else if len(e.gpus) > 0 {
+		e.deleteGPU(processName)
+	}
+	if rssOK {
+		e.updateGrowth(s)
+	}
+	if e.docker != nil {
+		e.setContainer(ctx, processName, int32(pid))
+	}
+	if e.kubelet != nil {
+		e.setPod(ctx, processName, int32(pid))
+	}
+	e.updateCgroup(processName, int32(pid))
+	e.updateSaturation(processName, int32(pid))
+	e.updateIOWait(processName, int32(pid))
+	e.updateChildren(processName, int32(pid))
+
+	s.Incomplete = incomplete
+	// 读取失败的指标直接删除, 而不是保留上一次的值
+	if cpuOK {
+		e.cpuUsage.WithLabelValues(processName).Set(s.CPUPercent * e.cpuScale)
+	} else {
+		e.cpuUsage.DeleteLabelValues(processName)
+	}
+	if (e.memUnit == UnitBytes && rssOK) || (e.memUnit != UnitBytes && memOK) {
+		e.memUsage.WithLabelValues(processName).Set(memValue(e.memUnit, s))
+	} else {
+		e.memUsage.DeleteLabelValues(processName)
+	}
+	e.setMemory(processName, s, rssOK, sharedOK, swapPssOK)
+	if fdsOK {
+		e.openFDs.WithLabelValues(processName).Set(float64(s.NumFDs))
+	} else {
+		e.openFDs.DeleteLabelValues(processName)
+	}
+	if threadsOK {
+		e.numThreads.WithLabelValues(processName).Set(float64(s.NumThreads))
+	} else {
+		e.numThreads.DeleteLabelValues(processName)
+	}
+	e.setDynamicLabels(processName, r.labels)
+	if r.binary != nil {
+		e.setBinary(processName, s.PID, r.binary)
+	} else {
+		if r.binaryErr != nil {
+			denied("binary", r.binaryErr)
+		}
+		e.deleteBinary(processName)
+	}
+	e.targetInfo.Delete(e.targetInfoLabels(processName, !incomplete))
+	e.targetInfo.With(e.targetInfoLabels(processName, incomplete)).Set(1)
+	// 获取进程的 pid
+	e.pidUsage.WithLabelValues(processName).Set(float64(pid))
+	e.self.collectDuration.WithLabelValues(processName).Set((r.duration + e.clock.Now().Sub(start)).Seconds())
+	logger.Debug(T("log.collected"), "duration", e.clock.Now().Sub(start))
+	return nil
+}
+
+// evalScripts 基于最近一次采集的数据执行所有脚本
+func (e *Exporter) evalScripts() {
+	for _, sc := range e.scripts {
+		for _, processName := range e.processNames {
+			s, ok := e.stats[processName]
+			if !ok {
+				sc.gauge.DeleteLabelValues(processName)
+				continue
+			}
+			v, ok, err := sc.eval(s)
+			if err != nil {
+				e.logger.Error(T("log.script"), "script", sc.name, "target", processName, "error", err)
+			}
+			if !ok {
+				sc.gauge.DeleteLabelValues(processName)
+				continue
+			}
+			sc.gauge.WithLabelValues(processName).Set(v)
+		}
+	}
+}
+
+// findPIDs 按进程表顺序返回所有匹配目标的 PID, 第一个即单进程指标监控的进程
+func (e *Exporter) findPIDs(ctx context.Context, j readJob, procs []procEntry) []int32 {
+	ctx, span := e.tracer.Start(ctx, "find-pid")
+	defer span.End()
+
+	var pids []int32
+	for _, pe := range procs {
+		if j.m.match(ctx, pe.p, pe.name, j.kernelThreads) {
+			pids = append(pids, pe.p.Pid)
+		}
+	}
+	if len(pids) == 0 {
+		e.logger.Warn(T("log.not_found"), "target", j.name)
+	}
+	if j.m.unit != "" {
+		pids = mainPIDFirst(ctx, j.m.unit, pids)
+	}
+	return pids
+}
+
+// SetActive 切换主备状态, 用于冗余部署时只让一个实例工作
+func (e *Exporter) SetActive(active bool) {
+	e.active.Store(active)
+	if active {
+		e.self.leader.Set(1)
+	} else {
+		e.self.leader.Set(0)
+	}
+}
+
+// Active 返回是否为主实例, 备用实例不应推送指标
+func (e *Exporter) Active() bool {
+	return e.active.Load()
+}
+
+// LastCycle 返回最近一次完成的采集周期的结束时间, 以及是否已经有过成功的周期
+func (e *Exporter) LastCycle() (time.Time, bool) {
+	ns := e.lastCycle.Load()
+	if ns == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, ns), e.succeeded.Load()
+}
+
+// Snapshot 按进程名排序返回每个监控目标最近一次采集到的数据, 没有找到的进程 PID 为 0
+func (e *Exporter) Snapshot() []Stats {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.snapshot()
+}
+
+func (e *Exporter) snapshot() []Stats {
+	res := make([]Stats, 0, len(e.processNames))
+	for _, name := range e.processNames {
+		if s, ok := e.stats[name]; ok {
+			res = append(res, *s)
+			continue
+		}
+		res = append(res, Stats{Process: name})
+	}
+	// 按名称排序, 使 API, CSV 和控制台的输出与配置顺序无关
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Process < res[j].Process })
+	return res
+}
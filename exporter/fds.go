@@ -0,0 +1,25 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"github.com/shirou/gopsutil/process"
+	"math"
+)
+
+// maxFDs 返回进程可以打开的文件描述符数的软限制 (RLIMIT_NOFILE), 没有限制时为 +Inf
+func maxFDs(ctx context.Context, p *process.Process) (float64, error) {
+	limits, err := p.RlimitWithContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, l := range limits {
+		if l.Resource == process.RLIMIT_NOFILE {
+			if l.Soft < 0 {
+				return math.Inf(1), nil
+			}
+			return float64(l.Soft), nil
+		}
+	}
+	return 0, errors.New("RLIMIT_NOFILE not found")
+}
@@ -0,0 +1,47 @@
+package exporter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// countFDTypes 按 /proc/<pid>/fd 中链接的目标统计进程打开的各类文件描述符
+func countFDTypes(pid int32) (map[string]int, error) {
+	dir := procPath(pid, "fd")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(fdTypes))
+	for _, t := range fdTypes {
+		counts[t] = 0
+	}
+	for _, entry := range entries {
+		target, err := os.Readlink(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			// 读取期间被关闭
+			continue
+		}
+		counts[fdType(target)]++
+	}
+	return counts, nil
+}
+
+// fdType 根据链接目标判断文件描述符的类型, 如 socket:[1234]、pipe:[1234]、anon_inode:[eventfd]
+func fdType(target string) string {
+	switch {
+	case strings.HasPrefix(target, "socket:"):
+		return "socket"
+	case strings.HasPrefix(target, "pipe:"):
+		return "pipe"
+	case strings.HasPrefix(target, "anon_inode:"):
+		return "anon_inode"
+	case strings.HasPrefix(target, "/dev/"):
+		return "device"
+	case strings.HasPrefix(target, "/"):
+		return "file"
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package exporter
+
+// countFDTypes 只在 Linux 上支持, 其他平台不导出分类统计
+func countFDTypes(pid int32) (map[string]int, error) {
+	return nil, nil
+}
@@ -0,0 +1,79 @@
+package exporter
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/prometheus/client_golang/prometheus"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// platformCollectors 返回只在当前平台可用的主机级指标, 指标名以 ns 为前缀
+func platformCollectors(ns string) []prometheus.Collector {
+	if _, err := hostForks(); err != nil {
+		return nil
+	}
+	return []prometheus.Collector{
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "host_forks_total",
+			Help:      T("help.host_forks_total"),
+		}, func() float64 {
+			n, _ := hostForks()
+			return float64(n)
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "host_zombies",
+			Help:      T("help.host_zombies"),
+		}, func() float64 {
+			n, _ := hostZombies()
+			return float64(n)
+		}),
+	}
+}
+
+// hostForks 读取 /proc/stat 中的 processes, 即开机以来创建的进程和线程总数
+func hostForks() (uint64, error) {
+	f, err := os.Open(filepath.Join(procRoot, "stat"))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if v, ok := strings.CutPrefix(sc.Text(), "processes "); ok {
+			return strconv.ParseUint(strings.TrimSpace(v), 10, 64)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return 0, err
+	}
+	return 0, os.ErrNotExist
+}
+
+// childPIDs 从 /proc/<pid>/task/*/children 读取进程的直接子进程
+func childPIDs(pid int32) ([]int32, error) {
+	files, err := filepath.Glob(procPath(pid, "task", "*", "children"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, os.ErrNotExist
+	}
+	var pids []int32
+	for _, name := range files {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		for _, f := range bytes.Fields(data) {
+			if n, err := strconv.ParseInt(string(f), 10, 32); err == nil {
+				pids = append(pids, int32(n))
+			}
+		}
+	}
+	return pids, nil
+}
@@ -0,0 +1,17 @@
+//go:build !linux
+
+package exporter
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func platformCollectors(ns string) []prometheus.Collector {
+	return nil
+}
+
+// childPIDs 只在 Linux 上支持
+func childPIDs(pid int32) ([]int32, error) {
+	return nil, errors.New("listing child processes is only supported on Linux")
+}
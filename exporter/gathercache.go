@@ -0,0 +1,36 @@
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"sync"
+	"time"
+)
+
+// cachedGatherer 串行化并发的抓取, ttl 内的抓取直接返回上一次 Gather 的结果,
+// HA 的两个 Prometheus 同时抓取时只遍历一次 /proc
+type cachedGatherer struct {
+	g     prometheus.Gatherer
+	ttl   time.Duration
+	clock Clock
+	hits  prometheus.Counter
+
+	mu  sync.Mutex
+	at  time.Time
+	mfs []*dto.MetricFamily
+	err error
+}
+
+// Gather 实现 prometheus.Gatherer. 返回的 MetricFamily 在多个请求间共享, 调用方不能修改
+func (c *cachedGatherer) Gather() ([]*dto.MetricFamily, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.clock.Now()
+	if !c.at.IsZero() && now.Sub(c.at) < c.ttl {
+		c.hits.Inc()
+		return c.mfs, c.err
+	}
+	c.mfs, c.err = c.g.Gather()
+	c.at = c.clock.Now()
+	return c.mfs, c.err
+}
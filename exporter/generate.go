@@ -0,0 +1,181 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// generated 是生成仪表盘和告警规则使用的指标名, 与 New 使用的前缀和单位一致
+type generated struct {
+	ns      string
+	cpu     string
+	mem     string
+	cpuUnit string
+	memUnit string
+	// 高 CPU 和内存使用率告警的阈值, 与单位一致
+	cpuHigh float64
+	memHigh float64
+	// process 标签匹配所有监控目标的选择器
+	selector string
+	targets  []string
+}
+
+func newGenerated(cfg Config) (*generated, error) {
+	g := &generated{ns: cfg.Namespace, targets: cfg.TargetNames()}
+	if g.ns == "" {
+		g.ns = namespace
+	}
+	if len(g.targets) == 0 {
+		return nil, fmt.Errorf("no targets configured")
+	}
+	cpuOpts, _, err := cpuGaugeOpts(cfg.Units.CPU)
+	if err != nil {
+		return nil, err
+	}
+	memOpts, err := memGaugeOpts(cfg.Units.Memory)
+	if err != nil {
+		return nil, err
+	}
+	g.cpu, g.mem = g.ns+"_"+cpuOpts.Name, g.ns+"_"+memOpts.Name
+	g.cpuUnit, g.cpuHigh = "percent", 90
+	if cfg.Units.CPU == UnitRatio {
+		g.cpuUnit, g.cpuHigh = "percentunit", 0.9
+	}
+	switch cfg.Units.Memory {
+	case UnitRatio:
+		g.memUnit, g.memHigh = "percentunit", 0.9
+	case UnitBytes:
+		// 常驻内存字节数没有通用的阈值, 不生成内存告警
+		g.memUnit = "bytes"
+	default:
+		g.memUnit, g.memHigh = "percent", 90
+	}
+	quoted := make([]string, len(g.targets))
+	for i, t := range g.targets {
+		quoted[i] = regexp.QuoteMeta(t)
+	}
+	g.selector = "{process=~" + strconv.Quote(strings.Join(quoted, "|")) + "}"
+	return g, nil
+}
+
+// GenerateDashboard 返回 Grafana 仪表盘的 JSON, process 变量的选项为配置中的监控目标
+func GenerateDashboard(cfg Config) ([]byte, error) {
+	g, err := newGenerated(cfg)
+	if err != nil {
+		return nil, err
+	}
+	sel := `{instance=~"$instance",process=~"$process"}`
+	type query struct{ expr, legend string }
+	panels := []struct {
+		title   string
+		unit    string
+		queries []query
+	}{
+		{T("dashboard.cpu"), g.cpuUnit, []query{{g.cpu + sel, "{{process}}"}}},
+		{T("dashboard.memory"), g.memUnit, []query{{g.mem + sel, "{{process}}"}}},
+		{T("dashboard.rss"), "bytes", []query{{g.ns + "_memory_rss_bytes" + sel, "{{process}}"}}},
+		{T("dashboard.up"), "short", []query{{g.ns + "_up" + sel, "{{process}}"}}},
+		{T("dashboard.restarts"), "short", []query{{"increase(" + g.ns + "_restarts_total" + sel + "[1h])", "{{process}}"}}},
+		{T("dashboard.fds"), "percentunit", []query{{g.ns + "_open_fds" + sel + " / " + g.ns + "_max_fds" + sel, "{{process}}"}}},
+		{T("dashboard.threads"), "short", []query{{g.ns + "_num_threads" + sel, "{{process}}"}}},
+		{T("dashboard.io"), "Bps", []query{
+			{"rate(" + g.ns + "_io_read_bytes_total" + sel + "[$__rate_interval])", "{{process}} read"},
+			{"rate(" + g.ns + "_io_write_bytes_total" + sel + "[$__rate_interval])", "{{process}} write"},
+		}},
+	}
+	datasource := map[string]any{"type": "prometheus", "uid": "${datasource}"}
+	list := make([]any, 0, len(panels))
+	for i, p := range panels {
+		targets := make([]any, 0, len(p.queries))
+		for j, q := range p.queries {
+			targets = append(targets, map[string]any{
+				"datasource":   datasource,
+				"expr":         q.expr,
+				"legendFormat": q.legend,
+				"refId":        string(rune('A' + j)),
+			})
+		}
+		list = append(list, map[string]any{
+			"id":          i + 1,
+			"type":        "timeseries",
+			"title":       p.title,
+			"datasource":  datasource,
+			"gridPos":     map[string]int{"h": 8, "w": 12, "x": i % 2 * 12, "y": i / 2 * 8},
+			"fieldConfig": map[string]any{"defaults": map[string]any{"unit": p.unit}, "overrides": []any{}},
+			"targets":     targets,
+		})
+	}
+	options := make([]any, 0, len(g.targets))
+	for _, t := range g.targets {
+		options = append(options, map[string]any{"text": t, "value": t, "selected": false})
+	}
+	all := map[string]any{"text": "All", "value": "$__all"}
+	dashboard := map[string]any{
+		"title":         T("dashboard.title"),
+		"tags":          []string{"process-exporter"},
+		"schemaVersion": 39,
+		"editable":      true,
+		"refresh":       "30s",
+		"time":          map[string]string{"from": "now-6h", "to": "now"},
+		"panels":        list,
+		"templating": map[string]any{"list": []any{
+			map[string]any{"name": "datasource", "label": "Data source", "type": "datasource", "query": "prometheus"},
+			map[string]any{
+				"name": "instance", "type": "query", "datasource": datasource, "refresh": 2,
+				"query": "label_values(" + g.ns + "_up, instance)", "multi": true, "includeAll": true, "current": all,
+			},
+			map[string]any{
+				"name": "process", "type": "custom", "query": strings.Join(g.targets, ","),
+				"options": options, "multi": true, "includeAll": true, "current": all,
+			},
+		}},
+	}
+	out, err := json.MarshalIndent(dashboard, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// GenerateRules 返回 Prometheus 告警规则的 YAML, 规则只选择配置中的监控目标
+func GenerateRules(cfg Config) ([]byte, error) {
+	g, err := newGenerated(cfg)
+	if err != nil {
+		return nil, err
+	}
+	type rule struct {
+		Alert       string            `yaml:"alert"`
+		Expr        string            `yaml:"expr"`
+		For         string            `yaml:"for,omitempty"`
+		Labels      map[string]string `yaml:"labels"`
+		Annotations map[string]string `yaml:"annotations"`
+	}
+	newRule := func(alert, expr, pending, severity, summary string) rule {
+		return rule{Alert: alert, Expr: expr, For: pending, Labels: map[string]string{"severity": severity},
+			Annotations: map[string]string{"summary": summary}}
+	}
+	rules := []rule{
+		newRule("ProcessDown", g.ns+"_up"+g.selector+" == 0", "1m", "critical", T("rules.down")),
+		newRule("ProcessRestarting", "increase("+g.ns+"_restarts_total"+g.selector+"[15m]) > 3", "", "warning", T("rules.restarting")),
+		newRule("ProcessHighCPU", g.cpu+g.selector+" > "+strconv.FormatFloat(g.cpuHigh, 'g', -1, 64), "5m", "warning", T("rules.high_cpu")),
+	}
+	if g.memHigh > 0 {
+		rules = append(rules, newRule("ProcessHighMemory", g.mem+g.selector+" > "+strconv.FormatFloat(g.memHigh, 'g', -1, 64), "5m", "warning", T("rules.high_memory")))
+	}
+	rules = append(rules, newRule("ProcessFDsExhausted", g.ns+"_open_fds"+g.selector+" / "+g.ns+"_max_fds"+g.selector+" > 0.9", "5m", "warning", T("rules.fds")))
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(map[string]any{"groups": []any{map[string]any{"name": "process-exporter", "rules": rules}}}); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
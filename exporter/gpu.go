@@ -0,0 +1,134 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// gpuUsage 是一个进程在一块 GPU 上的用量
+type gpuUsage struct {
+	// 显存 (字节)
+	memory uint64
+	// SM 使用率 (%), nvidia-smi 没有采样到时为 -1
+	util float64
+}
+
+// nvidiaSMI 执行 nvidia-smi 并返回输出的每一行, nvidia-smi 通过 NVML 查询数据
+func nvidiaSMI(ctx context.Context, args ...string) ([]string, error) {
+	out, err := exec.CommandContext(ctx, "nvidia-smi", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("nvidia-smi: %w", err)
+	}
+	return strings.Split(strings.TrimSpace(string(out)), "\n"), nil
+}
+
+// gpuProcesses 返回每个使用 GPU 的进程在各块 GPU (以序号为键) 上的显存和使用率
+func gpuProcesses(ctx context.Context) (map[int32]map[string]*gpuUsage, error) {
+	lines, err := nvidiaSMI(ctx, "--query-gpu=index,uuid", "--format=csv,noheader")
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]string)
+	for _, line := range lines {
+		if i, uuid, ok := strings.Cut(line, ","); ok {
+			index[strings.TrimSpace(uuid)] = strings.TrimSpace(i)
+		}
+	}
+
+	procs := make(map[int32]map[string]*gpuUsage)
+	usage := func(pid int32, gpu string) *gpuUsage {
+		if procs[pid] == nil {
+			procs[pid] = make(map[string]*gpuUsage)
+		}
+		if procs[pid][gpu] == nil {
+			procs[pid][gpu] = &gpuUsage{util: -1}
+		}
+		return procs[pid][gpu]
+	}
+	// 如 "1234, GPU-8b1c..., 2048", 显存单位为 MiB
+	lines, err = nvidiaSMI(ctx, "--query-compute-apps=pid,gpu_uuid,used_memory", "--format=csv,noheader,nounits")
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range lines {
+		f := strings.Split(line, ",")
+		if len(f) != 3 {
+			continue
+		}
+		pid, err1 := strconv.ParseInt(strings.TrimSpace(f[0]), 10, 32)
+		mib, err2 := strconv.ParseUint(strings.TrimSpace(f[2]), 10, 64)
+		gpu, ok := index[strings.TrimSpace(f[1])]
+		if err1 != nil || err2 != nil || !ok {
+			continue
+		}
+		usage(int32(pid), gpu).memory += mib << 20
+	}
+	// pmon 输出一次采样, 如 "    0   1234     C    35    10     -     -  python", 第 4 列为 SM 使用率
+	lines, err = nvidiaSMI(ctx, "pmon", "--count", "1", "--select", "u")
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range lines {
+		f := strings.Fields(line)
+		if len(f) < 4 || strings.HasPrefix(f[0], "#") {
+			continue
+		}
+		pid, err := strconv.ParseInt(f[1], 10, 32)
+		if err != nil {
+			continue
+		}
+		if sm, err := strconv.ParseFloat(f[3], 64); err == nil {
+			u := usage(int32(pid), f[0])
+			u.util = max(u.util, 0) + sm
+		}
+	}
+	return procs, nil
+}
+
+// updateGPU 把 pids 在每块 GPU 上的显存和使用率之和导出为 gpu_memory_bytes 和 gpu_utilization_percent,
+// 不再使用的 GPU 的序列随之删除
+func (e *Exporter) updateGPU(processName string, pids []int32) {
+	sum := make(map[string]*gpuUsage)
+	for _, pid := range pids {
+		for gpu, u := range e.gpuProcs[pid] {
+			s, ok := sum[gpu]
+			if !ok {
+				s = &gpuUsage{util: -1}
+				sum[gpu] = s
+			}
+			s.memory += u.memory
+			if u.util >= 0 {
+				s.util = max(s.util, 0) + u.util
+			}
+		}
+	}
+	for gpu := range e.gpus[processName] {
+		if _, ok := sum[gpu]; !ok {
+			e.gpuMemory.DeleteLabelValues(processName, gpu)
+			e.gpuUtil.DeleteLabelValues(processName, gpu)
+		}
+	}
+	seen := make(map[string]bool, len(sum))
+	for gpu, s := range sum {
+		seen[gpu] = true
+		e.gpuMemory.WithLabelValues(processName, gpu).Set(float64(s.memory))
+		if s.util >= 0 {
+			e.gpuUtil.WithLabelValues(processName, gpu).Set(s.util)
+		} else {
+			e.gpuUtil.DeleteLabelValues(processName, gpu)
+		}
+	}
+	e.gpus[processName] = seen
+}
+
+// deleteGPU 删除目标的所有 GPU 序列
+func (e *Exporter) deleteGPU(processName string) {
+	labels := prometheus.Labels{"process": processName}
+	e.gpuMemory.DeletePartialMatch(labels)
+	e.gpuUtil.DeletePartialMatch(labels)
+	delete(e.gpus, processName)
+}
@@ -0,0 +1,171 @@
+package exporter
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shirou/gopsutil/process"
+	"strconv"
+)
+
+// groupCPU 记录一个目标下每个 PID 上一次的 CPU 时间和磁盘 I/O 计数, 用于累加出单调递增的总量
+type groupCPU struct {
+	last map[int32]float64
+	io   map[int32]process.IOCountersStat
+
+	// 为 true 时下一次采集只记录基线, 用于从其他实例接管计数器之后
+	baseline bool
+}
+
+// updateGroupCPU 把所有匹配进程自上次采集以来的 CPU 时间和读写的字节数累加到 group_cpu_seconds_total
+// 和 group_io_*_bytes_total, 并把各自增长最多的 PID 作为 exemplar, 便于从尖峰直接定位到具体的进程.
+// 新出现的进程计入其启动以来的全部 CPU 时间, 已退出的进程最后一个周期的 CPU 时间会丢失
+func (e *Exporter) updateGroupCPU(ctx context.Context, processName string, pids []int32) {
+	g, ok := e.groupCPU[processName]
+	if !ok {
+		g = &groupCPU{last: make(map[int32]float64)}
+		e.groupCPU[processName] = g
+	}
+
+	cur := make(map[int32]float64, len(pids))
+	curIO := make(map[int32]process.IOCountersStat, len(pids))
+	var total, topDelta float64
+	var topPID int32
+	var read, write groupSum
+	for _, pid := range pids {
+		p, err := process.NewProcessWithContext(ctx, pid)
+		if err != nil {
+			continue
+		}
+		// 没有权限读取 I/O 时只跳过 I/O
+		if io, err := p.IOCountersWithContext(ctx); err == nil {
+			curIO[pid] = *io
+			prev, ok := g.io[pid]
+			if !ok && g.baseline {
+				prev = *io
+			}
+			read.add(pid, io.ReadBytes, prev.ReadBytes)
+			write.add(pid, io.WriteBytes, prev.WriteBytes)
+		}
+		times, err := p.TimesWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		seconds := times.User + times.System
+		cur[pid] = seconds
+
+		delta := seconds
+		if last, ok := g.last[pid]; ok {
+			delta = seconds - last
+		} else if g.baseline {
+			delta = 0
+		}
+		if delta < 0 {
+			delta = 0
+		}
+		total += delta
+		if delta > topDelta {
+			topDelta, topPID = delta, pid
+		}
+	}
+	g.last, g.io = cur, curIO
+	g.baseline = false
+
+	addWithExemplar(e.groupCPUSeconds.WithLabelValues(processName), total, topPID)
+	addWithExemplar(e.groupIORead.WithLabelValues(processName), read.total, read.topPID)
+	addWithExemplar(e.groupIOWrite.WithLabelValues(processName), write.total, write.topPID)
+}
+
+// groupSum 累加各 PID 的计数增量并记录增量最大的 PID
+type groupSum struct {
+	total, top float64
+	topPID     int32
+}
+
+// add 累加 PID 从 prev 到 cur 的增量, 计数变小 (PID 被复用) 时忽略
+func (s *groupSum) add(pid int32, cur, prev uint64) {
+	if cur < prev {
+		return
+	}
+	delta := float64(cur - prev)
+	s.total += delta
+	if delta > s.top {
+		s.top, s.topPID = delta, pid
+	}
+}
+
+// addWithExemplar 把 v 加到 c 上, pid 不为 0 时附带 pid 作为 exemplar
+func addWithExemplar(c prometheus.Counter, v float64, pid int32) {
+	if pid == 0 {
+		c.Add(v)
+		return
+	}
+	c.(prometheus.ExemplarAdder).AddWithExemplar(v, prometheus.Labels{"pid": strconv.Itoa(int(pid))})
+}
+
+// children 记录一个目标监控的进程上一次看到的子进程
+type children struct {
+	pid  int32
+	seen map[int32]bool
+}
+
+// updateChildren 把监控的进程自上次采集以来新出现的子进程数累加到 children_spawned_total.
+// 只能看到采集时仍在运行的子进程, 两次采集之间启动又退出的子进程需要结合 process_host_forks_total 判断
+func (e *Exporter) updateChildren(processName string, pid int32) {
+	pids, err := childPIDs(pid)
+	if err != nil {
+		return
+	}
+	cur := make(map[int32]bool, len(pids))
+	for _, p := range pids {
+		cur[p] = true
+	}
+	c, ok := e.children[processName]
+	if !ok || c.pid != pid {
+		// 第一次看到该进程时只记录基线
+		e.children[processName] = &children{pid: pid, seen: cur}
+		e.childrenSpawned.WithLabelValues(processName).Add(0)
+		return
+	}
+	spawned := 0
+	for p := range cur {
+		if !c.seen[p] {
+			spawned++
+		}
+	}
+	c.seen = cur
+	e.childrenSpawned.WithLabelValues(processName).Add(float64(spawned))
+}
+
+// updateChurn 比较本周期和上一周期匹配到的 PID, 把新出现和消失的进程数累加到 starts_total 和 exits_total.
+// first 为 true 表示第一次采集该目标, 此时只记录基线
+func (e *Exporter) updateChurn(processName string, prev, cur []int32, first bool) {
+	starts, exits := e.starts.WithLabelValues(processName), e.exits.WithLabelValues(processName)
+	if first {
+		return
+	}
+	old := make(map[int32]bool, len(prev))
+	for _, pid := range prev {
+		old[pid] = true
+	}
+	for _, pid := range cur {
+		if old[pid] {
+			delete(old, pid)
+			continue
+		}
+		starts.Inc()
+	}
+	exits.Add(float64(len(old)))
+}
+
+// updateRestarts 在监控的进程 (第一个匹配的进程) 的 PID 与上一次看到的不同时把 restarts_total 加一,
+// 中间进程消失了一段时间也算一次重启
+func (e *Exporter) updateRestarts(processName string, st *targetState, pid int32) {
+	restarts := e.restarts.WithLabelValues(processName)
+	if pid == 0 {
+		return
+	}
+	if st.lastPID != 0 && st.lastPID != pid {
+		restarts.Inc()
+	}
+	st.lastPID = pid
+}
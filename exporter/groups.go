@@ -0,0 +1,178 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"github.com/shirou/gopsutil/process"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// nameGroup 是编译后的 groups 规则
+type nameGroup struct {
+	cfg  GroupConfig
+	base *matcher
+	re   *regexp.Regexp
+}
+
+// validateGroup 检查 groups 中的一条规则
+func validateGroup(g GroupConfig) error {
+	switch {
+	case g.Match == "":
+		return fmt.Errorf("match is required")
+	case sanitizeName(g.Match) != g.Match:
+		return fmt.Errorf("match %q contains control characters", g.Match)
+	case (g.NameFromCmdline == "") == (g.NameFromEnv == ""):
+		return fmt.Errorf("exactly one of name_from_cmdline and name_from_env is required")
+	case g.Target.Probe != nil || g.Target.CheckCommand != "" || len(g.Target.Logs) > 0 || g.Target.Journal != nil:
+		return fmt.Errorf("target: probe, check_command, logs and journal are not supported")
+	}
+	if g.NameFromCmdline != "" {
+		if _, err := regexp.Compile(g.NameFromCmdline); err != nil {
+			return fmt.Errorf("name_from_cmdline: %w", err)
+		}
+	}
+	if err := validateAggregation(g.Target); err != nil {
+		return err
+	}
+	return validateLogFiles(g.Target)
+}
+
+// newNameGroups 编译 groups 规则, excludes 和 docker 的含义与 targetMatchers 相同
+func newNameGroups(groups []GroupConfig, excludes []string, docker *dockerClient) ([]*nameGroup, error) {
+	xs, err := excludeMatchers(excludes, docker)
+	if err != nil {
+		return nil, err
+	}
+	var gs []*nameGroup
+	for i, c := range groups {
+		if err := validateGroup(c); err != nil {
+			return nil, fmt.Errorf("groups[%d]: %w", i, err)
+		}
+		m, err := parseMatcher(c.Match)
+		if err != nil {
+			return nil, fmt.Errorf("groups[%d]: %w", i, err)
+		}
+		if m.container {
+			if docker == nil {
+				return nil, fmt.Errorf("groups[%d]: match %q requires docker discovery", i, c.Match)
+			}
+			m.docker = docker
+		}
+		m.excludes = xs
+		g := &nameGroup{cfg: c, base: m}
+		if c.NameFromCmdline != "" {
+			g.re = regexp.MustCompile(c.NameFromCmdline)
+		}
+		gs = append(gs, g)
+	}
+	return gs, nil
+}
+
+// groupTargets 返回 groups 中每条规则的目标设置, 用于校验标签名
+func groupTargets(groups []GroupConfig) []TargetConfig {
+	ts := make([]TargetConfig, 0, len(groups))
+	for i, g := range groups {
+		t := g.Target
+		t.Name = fmt.Sprintf("groups[%d]", i)
+		ts = append(ts, t)
+	}
+	return ts
+}
+
+// name 返回进程 p 所属的目标名, 不匹配或提取不到名称时返回空字符串
+func (g *nameGroup) name(ctx context.Context, p *process.Process, procName string, kernelThreads bool) string {
+	if !g.base.match(ctx, p, procName, kernelThreads) {
+		return ""
+	}
+	var name string
+	if g.re != nil {
+		cmdline, _ := p.CmdlineWithContext(ctx)
+		if m := g.re.FindStringSubmatch(cmdline); m != nil {
+			// 有捕获组时使用第一个捕获组, 否则使用整个匹配
+			name = m[0]
+			if len(m) > 1 {
+				name = m[1]
+			}
+		}
+	} else if environ, err := p.EnvironWithContext(ctx); err == nil {
+		for _, kv := range environ {
+			if v, ok := strings.CutPrefix(kv, g.cfg.NameFromEnv+"="); ok {
+				name = v
+				break
+			}
+		}
+	}
+	return sanitizeName(name)
+}
+
+// addGenerated 添加由 g 生成的名为 name 的目标, 调用方需持有 mutex
+func (e *Exporter) addGenerated(name string, g *nameGroup) {
+	t := g.cfg.Target
+	t.Name, t.Match = name, g.base.pattern
+	e.processNames = append(e.processNames, name)
+	e.targets[name] = t
+	e.matchers[name] = &matcher{pattern: g.base.pattern, group: g, groupName: name}
+	e.generated[name] = g
+}
+
+// dropGenerated 删除生成的目标及其所有序列, 调用方需持有 mutex
+func (e *Exporter) dropGenerated(name string) {
+	e.removeTarget(name)
+	e.processNames = slices.DeleteFunc(e.processNames, func(n string) bool { return n == name })
+	delete(e.targets, name)
+	delete(e.matchers, name)
+	delete(e.generated, name)
+}
+
+// discoverGroups 在距上次扫描超过 pid_rescan_interval 时扫描进程表, 为 groups 规则提取到的每个名称生成目标,
+// 并删除不再有进程的生成的目标. 配置中的同名目标优先, 一个进程只归入第一条匹配的规则
+func (e *Exporter) discoverGroups(ctx context.Context) {
+	e.mutex.Lock()
+	groups := e.nameGroups
+	rescan := e.config.PIDRescanInterval
+	if rescan <= 0 {
+		rescan = defaultPIDRescanInterval
+	}
+	due := len(groups) > 0 && e.clock.Now().Sub(e.groupsAt) >= rescan
+	kernelThreads := e.config.KernelThreads
+	e.mutex.Unlock()
+	if !due {
+		return
+	}
+
+	procs := e.processTable(ctx)
+	if procs == nil {
+		return
+	}
+	found := make(map[string]*nameGroup)
+	for _, pe := range procs {
+		for _, g := range groups {
+			if name := g.name(ctx, pe.p, pe.name, kernelThreads); name != "" {
+				if _, ok := found[name]; !ok {
+					found[name] = g
+				}
+				break
+			}
+		}
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	// 扫描期间 Reload 替换了规则时放弃本次结果
+	if !slices.Equal(groups, e.nameGroups) {
+		return
+	}
+	e.groupsAt = e.clock.Now()
+	for name := range e.generated {
+		if e.generated[name] != found[name] {
+			e.dropGenerated(name)
+		}
+	}
+	for name, g := range found {
+		if _, ok := e.matchers[name]; !ok {
+			e.addGenerated(name, g)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package exporter
+
+import (
+	"time"
+)
+
+// defaultGrowthWindow 是计算内存增长速度的默认时间窗口
+const defaultGrowthWindow = time.Hour
+
+// rssSample 是某次采集时的常驻内存
+type rssSample struct {
+	time time.Time
+	rss  float64
+}
+
+// growth 保存一个目标在时间窗口内的常驻内存, 用于估算内存泄漏的速度
+type growth struct {
+	pid     int32
+	samples []rssSample
+}
+
+// add 追加一次采集结果并丢弃窗口外的数据, 进程重启 (PID 变化) 后重新开始计算
+func (g *growth) add(pid int32, t time.Time, rss uint64, window time.Duration) {
+	if g.pid != pid {
+		g.pid = pid
+		g.samples = g.samples[:0]
+	}
+	g.samples = append(g.samples, rssSample{time: t, rss: float64(rss)})
+	cut := 0
+	for cut < len(g.samples) && t.Sub(g.samples[cut].time) > window {
+		cut++
+	}
+	g.samples = g.samples[cut:]
+}
+
+// slope 用最小二乘法拟合窗口内的数据, 返回每小时增长的字节数. 数据不足两个点时 ok 为 false
+func (g *growth) slope() (perHour float64, ok bool) {
+	n := float64(len(g.samples))
+	if n < 2 {
+		return 0, false
+	}
+	t0 := g.samples[0].time
+	var sx, sy, sxx, sxy float64
+	for _, s := range g.samples {
+		x := s.time.Sub(t0).Hours()
+		sx += x
+		sy += s.rss
+		sxx += x * x
+		sxy += x * s.rss
+	}
+	d := n*sxx - sx*sx
+	if d == 0 {
+		return 0, false
+	}
+	return (n*sxy - sx*sy) / d, true
+}
+
+// updateGrowth 记录 s 的常驻内存并更新 memory_growth_bytes_per_hour
+func (e *Exporter) updateGrowth(s *Stats) {
+	window := e.config.MemoryGrowthWindow
+	if window <= 0 {
+		window = defaultGrowthWindow
+	}
+	g, ok := e.growth[s.Process]
+	if !ok {
+		g = &growth{}
+		e.growth[s.Process] = g
+	}
+	g.add(s.PID, s.Time, s.MemoryRSS, window)
+	if v, ok := g.slope(); ok {
+		e.memGrowth.WithLabelValues(s.Process).Set(v)
+	} else {
+		e.memGrowth.DeleteLabelValues(s.Process)
+	}
+}
@@ -0,0 +1,147 @@
+package exporter
+
+import (
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// builtinGroups 是内置采集器在 collect[] 中的名称, 注册时按此顺序, 之后是 scripts 和 derived
+var builtinGroups = []string{"cpu", "memory", "pid", "info", "fds", "io", "threads", "connections", "churn", "cgroup", "gpu", "healthcheck", "logs", "journal", "users", "topn", "host", "textfile", "exporter"}
+
+// handlerOpts 是 Handler 和 ProbeHandler 输出指标时共用的选项, 开启 OpenMetrics 才能输出 exemplar
+var handlerOpts = promhttp.HandlerOpts{EnableOpenMetrics: true}
+
+// Handler 返回输出本 Exporter 指标的 http.Handler,
+// 它不关心请求路径, 可以挂载到已有 mux 的任意路径下.
+// 输出格式根据 Accept 请求头协商, Prometheus 请求 protobuf 时输出 protobuf 格式,
+// 请求 OpenMetrics 时输出 OpenMetrics 格式 (只有该格式和 protobuf 包含 exemplar).
+// 带有 collect[]=cpu&collect[]=memory 参数时只输出选中的采集器, 此时不包含 Registerer 上的其他指标.
+// 设置了 cache_ttl 时不带 collect[] 的抓取依次进行, ttl 内复用上一次的结果.
+// 备用实例只输出 exporter 自身的指标
+func (e *Exporter) Handler() http.Handler {
+	opts := handlerOpts
+	e.mutex.Lock()
+	ttl := e.config.CacheTTL
+	e.mutex.Unlock()
+	var g prometheus.Gatherer = e.gatherer
+	if ttl > 0 {
+		g = &cachedGatherer{g: e.gatherer, ttl: ttl, clock: e.clock, hits: e.self.cacheHits}
+	}
+	all := promhttp.HandlerFor(g, opts)
+	standbyReg := prometheus.NewRegistry()
+	standbyReg.MustRegister(e.self.collectors()...)
+	standby := promhttp.HandlerFor(standbyReg, opts)
+	h := e.self.instrument(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !e.active.Load() {
+			standby.ServeHTTP(w, r)
+			return
+		}
+		names := r.URL.Query()["collect[]"]
+		if len(names) == 0 {
+			all.ServeHTTP(w, r)
+			return
+		}
+		if e.collectOnScrape && e.active.Load() {
+			e.Update(r.Context())
+		}
+		reg := prometheus.NewRegistry()
+		for _, name := range names {
+			cs, ok := e.groups[name]
+			if !ok {
+				http.Error(w, fmt.Sprintf("unknown collector %q, available: %v", name, e.groupNames()), http.StatusBadRequest)
+				return
+			}
+			for _, c := range cs {
+				// 同一采集器被重复选择时忽略
+				if err := reg.Register(c); err != nil {
+					if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+						http.Error(w, err.Error(), http.StatusInternalServerError)
+						return
+					}
+				}
+			}
+		}
+		promhttp.HandlerFor(reg, opts).ServeHTTP(w, r)
+	}))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 响应内容随 Accept 和 Accept-Encoding 变化, 避免中间缓存返回错误的格式
+		w.Header().Add("Vary", "Accept")
+		w.Header().Add("Vary", "Accept-Encoding")
+		h.ServeHTTP(w, r)
+	})
+}
+
+// groupNames 返回 collect[] 可以使用的所有名称
+func (e *Exporter) groupNames() []string {
+	names := make([]string, 0, len(e.groups))
+	for name := range e.groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HealthyHandler 返回 /-/healthy 的 http.Handler: Healthy 返回 nil 时返回 200, 否则返回 503
+func (e *Exporter) HealthyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := e.Healthy(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "OK")
+	})
+}
+
+// ReadyHandler 返回 /-/ready 的 http.Handler: Ready 返回 nil 时返回 200, 否则返回 503
+func (e *Exporter) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := e.Ready(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "OK")
+	})
+}
+
+// Healthy 检查 exporter 是否存活: 后台采集时要求最近一个采集周期在 3 个周期内完成,
+// 还没有完成过采集或采集已经卡住时返回错误. 备用实例和抓取时采集时不在后台采集, 只要求 Run 仍在运行
+func (e *Exporter) Healthy() error {
+	if !e.active.Load() || e.collectOnScrape {
+		ns := e.heartbeat.Load()
+		if ns == 0 || e.clock.Now().Sub(time.Unix(0, ns)) >= 3*e.collectInterval {
+			return errors.New("collection loop is not running")
+		}
+		return nil
+	}
+	ns := e.lastCycle.Load()
+	if ns == 0 {
+		return errors.New("no collection cycle has completed yet")
+	}
+	if age := e.clock.Now().Sub(time.Unix(0, ns)); age >= 3*e.collectInterval {
+		return fmt.Errorf("last collection cycle completed %s ago", age.Round(time.Second))
+	}
+	return nil
+}
+
+// Ready 在 Healthy 的基础上还要求已经有过成功的采集周期, 抓取时采集时要求有过成功的抓取.
+// 备用实例不采集数据, 始终返回错误
+func (e *Exporter) Ready() error {
+	if !e.active.Load() {
+		return errors.New("standby instance does not collect")
+	}
+	if err := e.Healthy(); err != nil {
+		return err
+	}
+	if e.succeeded.Load() {
+		return nil
+	}
+	if e.collectOnScrape {
+		return errors.New("no scrape has succeeded yet")
+	}
+	return errors.New("no collection cycle has succeeded yet")
+}
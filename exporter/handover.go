@@ -0,0 +1,122 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"slices"
+	"sort"
+)
+
+// handoverState 是主实例定期交给备用实例的累计值, 以指标名为键
+type handoverState struct {
+	Counters map[string][]handoverSample `json:"counters"`
+}
+
+type handoverSample struct {
+	Labels map[string]string `json:"labels"`
+	Value  float64           `json:"value"`
+}
+
+// counterVecs 返回需要在主备切换时保留的累计指标
+func (e *Exporter) counterVecs() map[string]*prometheus.CounterVec {
+	return map[string]*prometheus.CounterVec{
+		"group_cpu_seconds_total":         e.groupCPUSeconds,
+		"group_io_read_bytes_total":       e.groupIORead,
+		"group_io_write_bytes_total":      e.groupIOWrite,
+		"cpu_user_seconds_total":          e.cpuUser,
+		"cpu_system_seconds_total":        e.cpuSystem,
+		"cpu_core_seconds_total":          e.coreSeconds,
+		"user_cpu_seconds_total":          e.userCPU,
+		"io_read_bytes_total":             e.ioReadBytes,
+		"io_write_bytes_total":            e.ioWriteBytes,
+		"io_reads_total":                  e.ioReads,
+		"io_writes_total":                 e.ioWrites,
+		"minor_page_faults_total":         e.minorFaults,
+		"major_page_faults_total":         e.majorFaults,
+		"voluntary_ctxt_switches_total":   e.voluntaryCtx,
+		"involuntary_ctxt_switches_total": e.involuntaryCtx,
+		"cpu_wait_seconds_total":          e.cpuWait,
+		"io_wait_seconds_total":           e.ioWait,
+		"children_spawned_total":          e.childrenSpawned,
+		"starts_total":                    e.starts,
+		"exits_total":                     e.exits,
+		"restarts_total":                  e.restarts,
+		"binary_changes_total":            e.binaryChanges,
+		"log_lines_total":                 e.logLines,
+		"logfile_written_bytes_total":     e.logFileWritten,
+		"journal_entries_total":           e.journalEntries,
+		"exporter_collect_errors_total":   e.self.collectErrors,
+	}
+}
+
+// ExportState 以 JSON 返回所有累计指标的当前值, 供备用实例在接管时通过 ImportState 恢复,
+// 避免主备切换时计数器归零打断告警
+func (e *Exporter) ExportState() ([]byte, error) {
+	st := handoverState{Counters: make(map[string][]handoverSample)}
+	for name, vec := range e.counterVecs() {
+		ch := make(chan prometheus.Metric)
+		go func() {
+			vec.Collect(ch)
+			close(ch)
+		}()
+		var samples []handoverSample
+		for m := range ch {
+			var pb dto.Metric
+			if err := m.Write(&pb); err != nil {
+				continue
+			}
+			s := handoverSample{Labels: make(map[string]string), Value: pb.GetCounter().GetValue()}
+			for _, lp := range pb.GetLabel() {
+				s.Labels[lp.GetName()] = lp.GetValue()
+			}
+			samples = append(samples, s)
+		}
+		sort.Slice(samples, func(i, j int) bool { return fmt.Sprint(samples[i].Labels) < fmt.Sprint(samples[j].Labels) })
+		st.Counters[name] = samples
+	}
+	return json.Marshal(st)
+}
+
+// ImportState 把 ExportState 导出的累计值加到本实例的计数器上, 应在开始采集前调用.
+// 之后第一次采集时已经在运行的进程的 CPU 时间只作为基线, 不会被重复计入. 本实例没有配置的目标的累计值被忽略
+func (e *Exporter) ImportState(data []byte) error {
+	var st handoverState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return fmt.Errorf("parsing handover state: %w", err)
+	}
+	vecs := e.counterVecs()
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	for name, samples := range st.Counters {
+		vec, ok := vecs[name]
+		if !ok {
+			continue
+		}
+		for _, s := range samples {
+			if p, ok := s.Labels["process"]; ok && !slices.Contains(e.processNames, p) {
+				// 本实例没有配置该目标, 恢复后会成为不再更新的残留序列
+				continue
+			}
+			c, err := vec.GetMetricWith(s.Labels)
+			if err != nil {
+				// 新版本去掉或修改了标签
+				continue
+			}
+			if s.Value > 0 {
+				c.Add(s.Value)
+			}
+		}
+	}
+	for _, name := range e.processNames {
+		e.groupCPU[name] = &groupCPU{last: make(map[int32]float64), baseline: true}
+		e.cpuTime[name] = &cpuTime{baseline: true}
+		e.coreCPU[name] = &coreCPU{baseline: true}
+		e.ioCount[name] = &ioCount{baseline: true}
+		e.ctxSwitches[name] = &ctxSwitches{baseline: true}
+		e.pageFaults[name] = &pageFaults{baseline: true}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package exporter
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"testing"
+	"time"
+)
+
+// handoverLabels 是 counterVecs 中每个累计指标的一组标签, 新增累计指标时需要补充
+var handoverLabels = map[string]prometheus.Labels{
+	"group_cpu_seconds_total":         {"process": "ho-a"},
+	"group_io_read_bytes_total":       {"process": "ho-a"},
+	"group_io_write_bytes_total":      {"process": "ho-a"},
+	"cpu_user_seconds_total":          {"process": "ho-a"},
+	"cpu_system_seconds_total":        {"process": "ho-a"},
+	"cpu_core_seconds_total":          {"process": "ho-a", "cpu": "0"},
+	"user_cpu_seconds_total":          {"user": "root"},
+	"io_read_bytes_total":             {"process": "ho-a"},
+	"io_write_bytes_total":            {"process": "ho-a"},
+	"io_reads_total":                  {"process": "ho-a"},
+	"io_writes_total":                 {"process": "ho-a"},
+	"minor_page_faults_total":         {"process": "ho-a"},
+	"major_page_faults_total":         {"process": "ho-a"},
+	"voluntary_ctxt_switches_total":   {"process": "ho-a"},
+	"involuntary_ctxt_switches_total": {"process": "ho-a"},
+	"cpu_wait_seconds_total":          {"process": "ho-a"},
+	"io_wait_seconds_total":           {"process": "ho-a"},
+	"children_spawned_total":          {"process": "ho-a"},
+	"starts_total":                    {"process": "ho-a"},
+	"exits_total":                     {"process": "ho-a"},
+	"restarts_total":                  {"process": "ho-a"},
+	"binary_changes_total":            {"process": "ho-a"},
+	"log_lines_total":                 {"process": "ho-a", "path": "/var/log/a.log", "pattern": "ERROR"},
+	"logfile_written_bytes_total":     {"process": "ho-a", "path": "/var/log/a.log"},
+	"journal_entries_total":           {"process": "ho-a", "unit": "a.service", "priority": "err"},
+	"exporter_collect_errors_total":   {"process": "ho-a", "error_type": "not_found"},
+}
+
+// ExportState 导出的每个累计指标都能由 ImportState 原样恢复
+func TestHandoverRoundTrip(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1700000000, 0))
+	cfg := Config{Processes: []string{"ho-a"}}
+	a := newClockExporter(t, clock, cfg)
+	want := make(map[string]float64)
+	for name, vec := range a.counterVecs() {
+		labels, ok := handoverLabels[name]
+		if !ok {
+			t.Fatalf("no labels for %s in handoverLabels", name)
+		}
+		want[name] = float64(len(want) + 1)
+		vec.With(labels).Add(want[name])
+	}
+	if len(want) != len(handoverLabels) {
+		t.Fatalf("counterVecs has %d counters, handoverLabels has %d", len(want), len(handoverLabels))
+	}
+	data, err := a.ExportState()
+	if err != nil {
+		t.Fatalf("ExportState: %v", err)
+	}
+
+	b := newClockExporter(t, clock, cfg)
+	if err := b.ImportState(data); err != nil {
+		t.Fatalf("ImportState: %v", err)
+	}
+	for name, vec := range b.counterVecs() {
+		if got := testutil.ToFloat64(vec.With(handoverLabels[name])); got != want[name] {
+			t.Errorf("%s = %v after ImportState, want %v", name, got, want[name])
+		}
+	}
+}
+
+// 本实例没有配置的目标的累计值不会被恢复
+func TestImportStateSkipsUnknownTargets(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1700000000, 0))
+	a := newClockExporter(t, clock, Config{Processes: []string{"ho-a", "ho-gone"}})
+	a.starts.WithLabelValues("ho-a").Add(2)
+	a.starts.WithLabelValues("ho-gone").Add(3)
+	a.userCPU.WithLabelValues("root").Add(4)
+	data, err := a.ExportState()
+	if err != nil {
+		t.Fatalf("ExportState: %v", err)
+	}
+
+	b := newClockExporter(t, clock, Config{Processes: []string{"ho-a"}})
+	if err := b.ImportState(data); err != nil {
+		t.Fatalf("ImportState: %v", err)
+	}
+	if n := testutil.CollectAndCount(b.starts); n != 1 {
+		t.Fatalf("got %d starts_total series, want 1", n)
+	}
+	if got := testutil.ToFloat64(b.starts.WithLabelValues("ho-a")); got != 2 {
+		t.Fatalf("starts_total = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(b.userCPU.WithLabelValues("root")); got != 4 {
+		t.Fatalf("user_cpu_seconds_total = %v, want 4", got)
+	}
+}
+
+// 主实例交给备用实例后累计值继续增长, 而不是从 0 开始
+func TestHandoverActiveToStandby(t *testing.T) {
+	const name = "ho-restart"
+	cmd := startNamed(t, name)
+	clock := NewFakeClock(time.Unix(1700000000, 0))
+	cfg := Config{Processes: []string{name}}
+	a := newClockExporter(t, clock, cfg)
+	ctx := context.Background()
+
+	a.Update(ctx)
+	stop(cmd)
+	cmd = startNamed(t, name)
+	clock.Advance(defaultPIDRescanInterval)
+	a.Update(ctx)
+	if got := testutil.ToFloat64(a.restarts.WithLabelValues(name)); got != 1 {
+		t.Fatalf("restarts_total = %v on the active instance, want 1", got)
+	}
+	data, err := a.ExportState()
+	if err != nil {
+		t.Fatalf("ExportState: %v", err)
+	}
+	a.SetActive(false)
+
+	b := newClockExporter(t, clock, cfg)
+	b.SetActive(false)
+	if err := b.ImportState(data); err != nil {
+		t.Fatalf("ImportState: %v", err)
+	}
+	b.SetActive(true)
+	b.Update(ctx)
+	if got := testutil.ToFloat64(b.restarts.WithLabelValues(name)); got != 1 {
+		t.Fatalf("restarts_total = %v after the handover, want 1", got)
+	}
+	stop(cmd)
+	startNamed(t, name)
+	clock.Advance(defaultPIDRescanInterval)
+	b.Update(ctx)
+	if got := testutil.ToFloat64(b.restarts.WithLabelValues(name)); got != 2 {
+		t.Fatalf("restarts_total = %v after a restart on the new active instance, want 2", got)
+	}
+}
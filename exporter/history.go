@@ -0,0 +1,41 @@
+package exporter
+
+import (
+	"time"
+)
+
+// sample 是某个采集周期结束时所有目标的数据
+type sample struct {
+	time  time.Time
+	stats []Stats
+}
+
+// history 按时间顺序保存最近 retention 时间内的采集结果
+type history struct {
+	retention time.Duration
+	samples   []sample
+}
+
+// add 追加一个周期的结果并丢弃过期的数据
+func (h *history) add(now time.Time, stats []Stats) {
+	if h.retention <= 0 {
+		return
+	}
+	h.samples = append(h.samples, sample{time: now, stats: stats})
+	cut := 0
+	for cut < len(h.samples) && now.Sub(h.samples[cut].time) > h.retention {
+		cut++
+	}
+	h.samples = h.samples[cut:]
+}
+
+// since 返回 t 之后的所有数据
+func (h *history) since(t time.Time) []sample {
+	var res []sample
+	for _, s := range h.samples {
+		if !s.time.Before(t) {
+			res = append(res, s)
+		}
+	}
+	return res
+}
@@ -0,0 +1,62 @@
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/load"
+	"github.com/shirou/gopsutil/mem"
+	"github.com/shirou/gopsutil/process"
+)
+
+// hostCollector 在抓取时导出少量主机级指标: CPU 时间、内存、负载和进程数,
+// 供资源很少的边缘设备在不部署 node_exporter 时使用
+type hostCollector struct {
+	cpu          *prometheus.Desc
+	memTotal     *prometheus.Desc
+	memAvailable *prometheus.Desc
+	load         *prometheus.Desc
+	procs        *prometheus.Desc
+}
+
+func newHostCollector(ns string) *hostCollector {
+	return &hostCollector{
+		cpu:          prometheus.NewDesc(prometheus.BuildFQName(ns, "host", "cpu_seconds_total"), T("help.host_cpu_seconds_total"), []string{"mode"}, nil),
+		memTotal:     prometheus.NewDesc(prometheus.BuildFQName(ns, "host", "memory_total_bytes"), T("help.host_memory_total_bytes"), nil, nil),
+		memAvailable: prometheus.NewDesc(prometheus.BuildFQName(ns, "host", "memory_available_bytes"), T("help.host_memory_available_bytes"), nil, nil),
+		load:         prometheus.NewDesc(prometheus.BuildFQName(ns, "host", "load_average"), T("help.host_load_average"), []string{"period"}, nil),
+		procs:        prometheus.NewDesc(prometheus.BuildFQName(ns, "host", "processes"), T("help.host_processes"), nil, nil),
+	}
+}
+
+func (c *hostCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.cpu
+	ch <- c.memTotal
+	ch <- c.memAvailable
+	ch <- c.load
+	ch <- c.procs
+}
+
+// Collect 读取失败的指标不输出, 例如 Windows 上没有负载
+func (c *hostCollector) Collect(ch chan<- prometheus.Metric) {
+	if times, err := cpu.Times(false); err == nil && len(times) > 0 {
+		t := times[0]
+		for mode, v := range map[string]float64{
+			"user": t.User, "nice": t.Nice, "system": t.System, "idle": t.Idle, "iowait": t.Iowait,
+			"irq": t.Irq, "softirq": t.Softirq, "steal": t.Steal,
+		} {
+			ch <- prometheus.MustNewConstMetric(c.cpu, prometheus.CounterValue, v, mode)
+		}
+	}
+	if vm, err := mem.VirtualMemory(); err == nil {
+		ch <- prometheus.MustNewConstMetric(c.memTotal, prometheus.GaugeValue, float64(vm.Total))
+		ch <- prometheus.MustNewConstMetric(c.memAvailable, prometheus.GaugeValue, float64(vm.Available))
+	}
+	if avg, err := load.Avg(); err == nil {
+		ch <- prometheus.MustNewConstMetric(c.load, prometheus.GaugeValue, avg.Load1, "1m")
+		ch <- prometheus.MustNewConstMetric(c.load, prometheus.GaugeValue, avg.Load5, "5m")
+		ch <- prometheus.MustNewConstMetric(c.load, prometheus.GaugeValue, avg.Load15, "15m")
+	}
+	if pids, err := process.Pids(); err == nil {
+		ch <- prometheus.MustNewConstMetric(c.procs, prometheus.GaugeValue, float64(len(pids)))
+	}
+}
@@ -0,0 +1,371 @@
+package exporter
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// 支持的语言
+const (
+	LocaleZH = "zh"
+	LocaleEN = "en"
+)
+
+var (
+	localeMu sync.RWMutex
+	locale   = LocaleZH
+)
+
+// messages 是所有指标说明和日志/命令行输出的翻译, 键为消息 ID
+var messages = map[string]map[string]string{
+	// 指标说明
+	"help.cpu_percent":                     {LocaleZH: "CPU使用率", LocaleEN: "CPU usage in percent"},
+	"help.cpu_ratio":                       {LocaleZH: "CPU使用率 (0-1)", LocaleEN: "CPU usage as a ratio (0-1)"},
+	"help.memory_percent":                  {LocaleZH: "内存使用率", LocaleEN: "Memory usage in percent"},
+	"help.memory_ratio":                    {LocaleZH: "内存使用率 (0-1)", LocaleEN: "Memory usage as a ratio (0-1)"},
+	"help.memory_bytes":                    {LocaleZH: "常驻内存字节数", LocaleEN: "Resident memory size in bytes"},
+	"help.pid":                             {LocaleZH: "进程pid", LocaleEN: "Process ID"},
+	"help.group_io_read_bytes_total":       {LocaleZH: "所有匹配进程读取的字节数之和, exemplar 为增长最多的 PID", LocaleEN: "Bytes read summed over all matching processes, with the dominating PID as exemplar"},
+	"help.group_io_write_bytes_total":      {LocaleZH: "所有匹配进程写入的字节数之和, exemplar 为增长最多的 PID", LocaleEN: "Bytes written summed over all matching processes, with the dominating PID as exemplar"},
+	"help.group_cpu_seconds_total":         {LocaleZH: "所有匹配进程的 CPU 时间之和 (秒), exemplar 为增长最多的 PID", LocaleEN: "CPU seconds summed over all matching processes, with the dominating PID as exemplar"},
+	"help.target_info":                     {LocaleZH: "监控目标的信息, incomplete=\"true\" 表示部分数据因权限不足无法读取, 其余标签为目标配置的静态和动态标签", LocaleEN: "Information about a target, incomplete=\"true\" means some data could not be read due to missing permissions, other labels are the static and dynamic labels configured for the target"},
+	"help.open_files":                      {LocaleZH: "按类型统计的打开的文件描述符数 (仅 Linux)", LocaleEN: "Open file descriptors by type (Linux only)"},
+	"help.short_lived_exits_total":         {LocaleZH: "运行不到两个采集周期就退出的任务数", LocaleEN: "Number of tasks that exited within two collection cycles of starting"},
+	"help.short_lived_cpu_seconds_total":   {LocaleZH: "运行不到两个采集周期就退出的任务消耗的 CPU 时间 (秒)", LocaleEN: "CPU seconds used by tasks that exited within two collection cycles of starting"},
+	"help.short_lived_read_bytes_total":    {LocaleZH: "运行不到两个采集周期就退出的任务从存储读取的字节数", LocaleEN: "Bytes read from storage by tasks that exited within two collection cycles of starting"},
+	"help.short_lived_write_bytes_total":   {LocaleZH: "运行不到两个采集周期就退出的任务向存储写入的字节数", LocaleEN: "Bytes written to storage by tasks that exited within two collection cycles of starting"},
+	"help.host_forks_total":                {LocaleZH: "主机开机以来创建的进程和线程总数", LocaleEN: "Total number of processes and threads created on the host since boot"},
+	"help.children_spawned_total":          {LocaleZH: "监控的进程新创建的子进程数 (采集时仍在运行的)", LocaleEN: "Number of new child processes of the monitored process seen at collection time"},
+	"help.starts_total":                    {LocaleZH: "目标匹配到的进程中新出现的进程数", LocaleEN: "Number of matching processes that appeared"},
+	"help.exits_total":                     {LocaleZH: "目标匹配到的进程中消失的进程数", LocaleEN: "Number of matching processes that disappeared"},
+	"help.healthcheck_up":                  {LocaleZH: "最近一次健康检查是否成功", LocaleEN: "Whether the last health check succeeded"},
+	"help.healthcheck_duration_seconds":    {LocaleZH: "最近一次健康检查的耗时", LocaleEN: "Duration of the last health check"},
+	"help.check_command_exit_code":         {LocaleZH: "最近一次检查命令的退出码, 无法执行或超时时为 -1", LocaleEN: "Exit code of the last check command, -1 if it could not run or timed out"},
+	"help.logfile_size_bytes":              {LocaleZH: "log_files 匹配到的日志文件的大小", LocaleEN: "Size of the log file matched by log_files"},
+	"help.logfile_written_bytes_total":     {LocaleZH: "exporter 启动以来日志文件增长的字节数, 截断或轮转后从 0 开始计算", LocaleEN: "Bytes the log file has grown by since the exporter started, counting from zero after truncation or rotation"},
+	"help.log_lines_total":                 {LocaleZH: "日志文件中匹配该模式的新增行数", LocaleEN: "Number of new log lines matching the pattern"},
+	"help.journal_entries_total":           {LocaleZH: "systemd unit 在 journal 中不低于配置优先级的日志条目数", LocaleEN: "Number of journal entries of the systemd unit at or above the configured priority"},
+	"help.memory_growth_bytes_per_hour":    {LocaleZH: "时间窗口内常驻内存的增长速度 (每小时字节数), 持续为正可能是内存泄漏", LocaleEN: "Growth rate of resident memory over the window in bytes per hour, persistently positive values may indicate a leak"},
+	"help.io_wait_seconds_total":           {LocaleZH: "进程的线程等待块设备 I/O 完成的时间 (秒, 仅 Linux, 需要开启 delay accounting)", LocaleEN: "Time the threads of the process spent waiting for block I/O to complete in seconds (Linux only, requires delay accounting)"},
+	"help.cpu_wait_seconds_total":          {LocaleZH: "进程所有线程在运行队列中等待 CPU 的时间 (秒, 仅 Linux)", LocaleEN: "Time all threads of the process spent waiting for a CPU on the run queue in seconds (Linux only)"},
+	"help.cpu_saturation_ratio":            {LocaleZH: "CPU 饱和度: 上个周期内等待 CPU 的时间占等待和运行时间之和的比例 (仅 Linux)", LocaleEN: "CPU saturation: share of run queue wait time in wait plus run time over the last cycle (Linux only)"},
+	"help.pid_cpu_percent":                 {LocaleZH: "aggregation 为 per_pid 的目标中每个进程的 CPU 使用率", LocaleEN: "CPU usage in percent of each process of a target with aggregation per_pid"},
+	"help.pid_memory_percent":              {LocaleZH: "aggregation 为 per_pid 的目标中每个进程的内存使用率", LocaleEN: "Memory usage in percent of each process of a target with aggregation per_pid"},
+	"help.pid_memory_rss_bytes":            {LocaleZH: "aggregation 为 per_pid 的目标中每个进程的常驻内存字节数", LocaleEN: "Resident memory size in bytes of each process of a target with aggregation per_pid"},
+	"help.cpu_user_seconds_total":          {LocaleZH: "监控的进程累计的用户态 CPU 时间 (秒)", LocaleEN: "User CPU seconds of the monitored process"},
+	"help.cpu_core_seconds_total":          {LocaleZH: "监控的进程在每个 CPU 上累计的 CPU 时间 (秒), 按线程最近一次运行的 CPU 近似统计 (仅 Linux)", LocaleEN: "CPU seconds of the monitored process on each CPU, approximated by the CPU each thread last ran on (Linux only)"},
+	"help.cpu_affinity_info":               {LocaleZH: "监控的进程允许运行的 CPU 列表, 如 0-3,8 (仅 Linux)", LocaleEN: "CPUs the monitored process is allowed to run on, e.g. 0-3,8 (Linux only)"},
+	"help.cpu_system_seconds_total":        {LocaleZH: "监控的进程累计的内核态 CPU 时间 (秒)", LocaleEN: "System CPU seconds of the monitored process"},
+	"help.io_read_bytes_total":             {LocaleZH: "监控的进程累计读取的字节数", LocaleEN: "Bytes read by the monitored process"},
+	"help.io_write_bytes_total":            {LocaleZH: "监控的进程累计写入的字节数", LocaleEN: "Bytes written by the monitored process"},
+	"help.io_reads_total":                  {LocaleZH: "监控的进程累计的读操作次数", LocaleEN: "Read operations of the monitored process"},
+	"help.io_writes_total":                 {LocaleZH: "监控的进程累计的写操作次数", LocaleEN: "Write operations of the monitored process"},
+	"help.open_fds":                        {LocaleZH: "打开的文件描述符数", LocaleEN: "Number of open file descriptors"},
+	"help.max_fds":                         {LocaleZH: "文件描述符数的软限制 (RLIMIT_NOFILE)", LocaleEN: "Soft limit on open file descriptors (RLIMIT_NOFILE)"},
+	"help.num_threads":                     {LocaleZH: "线程数", LocaleEN: "Number of threads"},
+	"help.voluntary_ctxt_switches_total":   {LocaleZH: "监控的进程累计的主动上下文切换次数", LocaleEN: "Voluntary context switches of the monitored process"},
+	"help.involuntary_ctxt_switches_total": {LocaleZH: "监控的进程累计的被动上下文切换次数", LocaleEN: "Involuntary context switches of the monitored process"},
+	"help.listen_ports":                    {LocaleZH: "监控的进程监听的 TCP 端口和绑定的 UDP 端口, 值始终为 1", LocaleEN: "TCP ports the monitored process listens on and UDP ports it has bound, always 1"},
+	"help.network_connections":             {LocaleZH: "按状态统计的 TCP 和 UDP 连接数", LocaleEN: "TCP and UDP connections by state"},
+	"help.start_time_seconds":              {LocaleZH: "监控的进程的启动时间 (Unix 时间戳, 秒)", LocaleEN: "Start time of the monitored process since unix epoch in seconds"},
+	"help.restarts_total":                  {LocaleZH: "监控的进程的 PID 变化的次数", LocaleEN: "Number of times the PID of the monitored process changed"},
+	"help.up":                              {LocaleZH: "目标是否匹配到了进程, 1 为运行中", LocaleEN: "Whether a process matching the target is running"},
+	"help.container_info":                  {LocaleZH: "监控的进程所在的容器, 值恒为 1", LocaleEN: "Container the monitored process runs in, always 1"},
+	"help.pod_info":                        {LocaleZH: "监控的进程所在的 Kubernetes pod, 值恒为 1", LocaleEN: "Kubernetes pod the monitored process runs in, always 1"},
+	"help.user_processes":                  {LocaleZH: "每个用户的进程数", LocaleEN: "Number of processes owned by each user"},
+	"help.user_memory_rss_bytes":           {LocaleZH: "每个用户所有进程的常驻内存之和 (字节)", LocaleEN: "Sum of resident memory of the processes owned by each user in bytes"},
+	"help.user_cpu_seconds_total":          {LocaleZH: "每个用户的进程使用的 CPU 时间 (秒)", LocaleEN: "CPU time used by the processes owned by each user in seconds"},
+	"help.top_cpu_usage_percent":           {LocaleZH: "主机上资源占用最高的进程在两次采集之间的 CPU 使用率 (%)", LocaleEN: "CPU usage percent between two collections of the top processes on the host"},
+	"help.top_memory_rss_bytes":            {LocaleZH: "主机上资源占用最高的进程的常驻内存 (字节)", LocaleEN: "Resident memory in bytes of the top processes on the host"},
+	"help.priority":                        {LocaleZH: "监控的进程的调度优先级, 普通进程为 20 + nice, 实时进程为负数 (仅 Linux)", LocaleEN: "Scheduling priority of the monitored process, 20 + nice for normal processes and negative for real-time ones (Linux only)"},
+	"help.nice":                            {LocaleZH: "监控的进程的 nice 值, -20 到 19 (仅 Linux)", LocaleEN: "Nice value of the monitored process, -20 to 19 (Linux only)"},
+	"help.oom_score":                       {LocaleZH: "监控的进程的 OOM 分数, 内存不足时内核优先杀死分数最高的进程 (仅 Linux)", LocaleEN: "OOM score of the monitored process, the kernel kills the highest-scoring process when out of memory (Linux only)"},
+	"help.oom_score_adj":                   {LocaleZH: "监控的进程的 oom_score_adj, -1000 到 1000 (仅 Linux)", LocaleEN: "oom_score_adj of the monitored process, -1000 to 1000 (Linux only)"},
+	"help.states":                          {LocaleZH: "目标匹配到的进程中处于各状态的进程数", LocaleEN: "Number of matched processes in each state"},
+	"help.host_cpu_seconds_total":          {LocaleZH: "主机所有 CPU 在各模式下累计的时间 (秒)", LocaleEN: "Seconds all CPUs of the host spent in each mode"},
+	"help.host_memory_total_bytes":         {LocaleZH: "主机的物理内存总字节数", LocaleEN: "Total physical memory of the host in bytes"},
+	"help.host_memory_available_bytes":     {LocaleZH: "主机上无需换出即可分配的内存字节数", LocaleEN: "Memory of the host available for allocation without swapping in bytes"},
+	"help.host_load_average":               {LocaleZH: "主机 1、5、15 分钟的平均负载", LocaleEN: "1, 5 and 15 minute load average of the host"},
+	"help.host_processes":                  {LocaleZH: "主机上的进程数", LocaleEN: "Number of processes on the host"},
+	"help.host_zombies":                    {LocaleZH: "主机上处于僵尸状态的进程数", LocaleEN: "Number of zombie processes on the host"},
+	"help.minor_page_faults_total":         {LocaleZH: "监控的进程累计的次缺页次数", LocaleEN: "Minor page faults of the monitored process"},
+	"help.major_page_faults_total":         {LocaleZH: "监控的进程累计的主缺页次数, 需要从磁盘或 swap 读入", LocaleEN: "Major page faults of the monitored process, which require reading from disk or swap"},
+	"help.cgroup_memory_limit_bytes":       {LocaleZH: "监控的进程所在 cgroup 的内存限制 (字节), 不限制时没有该序列", LocaleEN: "Memory limit in bytes of the cgroup of the monitored process, absent when unlimited"},
+	"help.cgroup_memory_usage_ratio":       {LocaleZH: "监控的进程所在 cgroup 的内存用量与限制之比", LocaleEN: "Ratio of memory usage to the limit of the cgroup of the monitored process"},
+	"help.cgroup_cpu_quota_cores":          {LocaleZH: "监控的进程所在 cgroup 的 CPU 配额 (核数), 不限制时没有该序列", LocaleEN: "CPU quota in cores of the cgroup of the monitored process, absent when unlimited"},
+	"help.cgroup_cpu_usage_ratio":          {LocaleZH: "监控的进程所在 cgroup 在两次采集之间的 CPU 用量与配额之比", LocaleEN: "Ratio of CPU usage between two collections to the quota of the cgroup of the monitored process"},
+	"help.gpu_memory_bytes":                {LocaleZH: "监控的进程在每块 GPU 上使用的显存 (字节)", LocaleEN: "GPU memory in bytes used by the monitored processes on each GPU"},
+	"help.gpu_utilization_percent":         {LocaleZH: "监控的进程在每块 GPU 上的 SM 使用率 (%)", LocaleEN: "SM utilization percent of the monitored processes on each GPU"},
+	"help.cpu_percent_histogram":           {LocaleZH: "每隔 sample_interval 采样的 CPU 使用率 (百分比) 的分布", LocaleEN: "Distribution of CPU usage percent sampled every sample_interval"},
+	"help.memory_rss_bytes_histogram":      {LocaleZH: "每隔 sample_interval 采样的常驻内存 (字节) 的分布", LocaleEN: "Distribution of resident memory in bytes sampled every sample_interval"},
+	"help.memory_rss_bytes":                {LocaleZH: "常驻内存字节数 (RSS)", LocaleEN: "Resident set size in bytes"},
+	"help.memory_vms_bytes":                {LocaleZH: "虚拟内存字节数 (VMS)", LocaleEN: "Virtual memory size in bytes"},
+	"help.memory_swap_bytes":               {LocaleZH: "交换到 swap 的字节数", LocaleEN: "Swapped-out memory in bytes"},
+	"help.binary_info":                     {LocaleZH: "监控的进程正在运行的可执行文件的路径和 sha256, 需要 -collector.binary-hash", LocaleEN: "Path and sha256 of the executable the monitored process is running, requires -collector.binary-hash"},
+	"help.binary_changes_total":            {LocaleZH: "磁盘上的可执行文件与正在运行的不同 (升级后没有重启) 的次数", LocaleEN: "Number of times the executable on disk was found to differ from the running one (upgraded but not restarted)"},
+	"help.memory_swap_pss_bytes":           {LocaleZH: "交换到 swap 的字节数按共享的进程数均摊后的值, 来自 smaps, 需要 -collector.smaps", LocaleEN: "Swapped-out memory in bytes divided among the processes sharing it, from smaps, requires -collector.smaps"},
+	"help.memory_shared_bytes":             {LocaleZH: "共享内存字节数, 仅 Linux", LocaleEN: "Shared memory in bytes, Linux only"},
+	"help.script":                          {LocaleZH: "脚本 %s 计算的派生指标", LocaleEN: "Derived metric computed by script %s"},
+	"help.derived":                         {LocaleZH: "派生指标 %s", LocaleEN: "Derived metric %s"},
+
+	"help.scrapes_total":                     {LocaleZH: "指标接口被抓取的次数", LocaleEN: "Total number of scrapes of the metrics endpoint"},
+	"help.scrapes_in_flight":                 {LocaleZH: "正在处理的抓取请求数", LocaleEN: "Number of scrapes currently being served"},
+	"help.scrape_duration_seconds":           {LocaleZH: "处理抓取请求的耗时", LocaleEN: "Duration of scrapes of the metrics endpoint"},
+	"help.permission_denials_total":          {LocaleZH: "因权限不足无法读取的进程数据次数", LocaleEN: "Total number of per-process reads denied due to missing permissions"},
+	"help.taskstats_dropped_total":           {LocaleZH: "因接收缓冲区溢出而丢失 taskstats 消息的次数", LocaleEN: "Number of times taskstats messages were lost due to receive buffer overflow"},
+	"help.target_timeouts_total":             {LocaleZH: "因采集周期超过期限而跳过目标的次数", LocaleEN: "Number of times a target was skipped because the collection cycle ran past its deadline"},
+	"help.collector_timeouts_total":          {LocaleZH: "第三方采集器在抓取中超过期限的次数", LocaleEN: "Number of scrapes in which a collector ran past its deadline"},
+	"help.collect_duration_seconds":          {LocaleZH: "最近一次采集该目标的耗时 (秒)", LocaleEN: "Duration of the last collection of the target in seconds"},
+	"help.scrape_cache_hits_total":           {LocaleZH: "在 cache_ttl 内复用上一次结果的抓取次数", LocaleEN: "Number of scrapes served from the previous result within cache_ttl"},
+	"help.process_table_scans_total":         {LocaleZH: "完整扫描进程表查找监控目标的 PID 的次数", LocaleEN: "Number of full process table scans to find the PIDs of targets"},
+	"help.alert_notifications_total":         {LocaleZH: "按结果统计的发送告警通知的次数", LocaleEN: "Number of alert notifications sent, by result"},
+	"help.textfile_mtime_seconds":            {LocaleZH: "textfile 目录中每个被读取的文件的修改时间", LocaleEN: "Modification time of each file read from the textfile directory"},
+	"help.textfile_scrape_error":             {LocaleZH: "读取 textfile 目录时是否有文件无法读取或解析", LocaleEN: "Whether a file in the textfile directory could not be read or parsed"},
+	"help.textfile_metric":                   {LocaleZH: "从 textfile 目录读取的指标", LocaleEN: "Metric read from the textfile directory"},
+	"help.collect_errors_total":              {LocaleZH: "采集该目标失败的次数, 按原因 (not_found、permission_denied、other) 区分, 没有匹配到进程的周期也计入 not_found", LocaleEN: "Number of failed collections of the target by error_type (not_found, permission_denied, other), cycles without a matching process count as not_found"},
+	"help.last_collect_timestamp_seconds":    {LocaleZH: "最近一个采集周期结束的时间 (Unix 时间戳, 秒)", LocaleEN: "Time the last collection cycle finished since unix epoch in seconds"},
+	"help.build_info":                        {LocaleZH: "exporter 的版本信息, 值恒为 1", LocaleEN: "Build information of the exporter, always 1"},
+	"help.leader":                            {LocaleZH: "是否为主实例, 备用实例为 0 且不采集数据", LocaleEN: "Whether this instance is the active one, 0 on a standby that does not collect"},
+	"help.collection_cycles_total":           {LocaleZH: "采集周期的次数", LocaleEN: "Total number of collection cycles by result"},
+	"help.collection_cycle_duration_seconds": {LocaleZH: "采集周期的耗时", LocaleEN: "Duration of collection cycles"},
+
+	// 日志
+	"log.get_process":       {LocaleZH: "获取进程失败", LocaleEN: "Error getting process"},
+	"log.get_cpu_percent":   {LocaleZH: "获取 CPU 使用率失败", LocaleEN: "Error getting CPU percent"},
+	"log.get_mem_percent":   {LocaleZH: "获取内存使用率失败", LocaleEN: "Error getting mem percent"},
+	"log.get_mem_info":      {LocaleZH: "获取内存信息失败", LocaleEN: "Error getting mem info"},
+	"log.get_processes":     {LocaleZH: "获取进程列表失败", LocaleEN: "Error getting processes"},
+	"log.not_found":         {LocaleZH: "未找到进程", LocaleEN: "Process not found"},
+	"log.collected":         {LocaleZH: "采集完成", LocaleEN: "Collected process"},
+	"log.gather":            {LocaleZH: "收集指标失败", LocaleEN: "Error gathering metrics"},
+	"log.script":            {LocaleZH: "执行脚本失败", LocaleEN: "Error evaluating script"},
+	"log.derived":           {LocaleZH: "计算派生指标失败", LocaleEN: "Error evaluating derived metric"},
+	"log.sd_notify":         {LocaleZH: "发送 systemd 通知失败", LocaleEN: "Error notifying systemd"},
+	"log.permission_denied": {LocaleZH: "没有权限读取进程数据, 跳过该项", LocaleEN: "Permission denied reading process data, skipping"},
+	"log.taskstats":         {LocaleZH: "接收 taskstats 失败, 停止统计短命进程", LocaleEN: "Error receiving taskstats, no longer accounting short-lived processes"},
+	"log.probe_failed":      {LocaleZH: "健康检查失败", LocaleEN: "Health check failed"},
+	"log.tail":              {LocaleZH: "读取日志文件失败", LocaleEN: "Error tailing log file"},
+	"log.journal":           {LocaleZH: "journalctl 已退出, 稍后重新启动", LocaleEN: "journalctl exited, restarting later"},
+	"log.dump":              {LocaleZH: "已写入快照", LocaleEN: "Wrote snapshot dump"},
+	"log.series_expired":    {LocaleZH: "目标的进程消失超过 series_ttl, 已删除其序列", LocaleEN: "Target process gone for longer than series_ttl, series deleted"},
+	"log.reloaded":          {LocaleZH: "已重新加载配置", LocaleEN: "Configuration reloaded"},
+	"log.reload_restart":    {LocaleZH: "部分修改的设置需要重启才能生效", LocaleEN: "Some changed settings only take effect after a restart"},
+	"log.aggregate_skip":    {LocaleZH: "跳过无法读取的匹配进程", LocaleEN: "Skipping unreadable matched process"},
+	"log.container_name":    {LocaleZH: "查询容器名失败", LocaleEN: "Error getting container name"},
+	"log.kubelet":           {LocaleZH: "从 kubelet 查询 pod 失败", LocaleEN: "Error looking up pod from kubelet"},
+	"log.textfile":          {LocaleZH: "读取 textfile 目录失败", LocaleEN: "Error reading the textfile directory"},
+	"log.gpu":               {LocaleZH: "通过 nvidia-smi 查询 GPU 用量失败", LocaleEN: "Error querying GPU usage with nvidia-smi"},
+	"log.alert_firing":      {LocaleZH: "告警触发", LocaleEN: "Alert firing"},
+	"log.alert_resolved":    {LocaleZH: "告警恢复", LocaleEN: "Alert resolved"},
+	"log.alert_eval":        {LocaleZH: "求值告警规则失败", LocaleEN: "Failed to evaluate alert rule"},
+	"log.alert_webhook":     {LocaleZH: "发送告警通知失败", LocaleEN: "Failed to send alert notification"},
+	"log.level_changed":     {LocaleZH: "日志级别已修改", LocaleEN: "Log level changed"},
+
+	// 命令行
+	"landing.version":                     {LocaleZH: "版本", LocaleEN: "Version"},
+	"landing.targets":                     {LocaleZH: "监控目标", LocaleEN: "Targets"},
+	"landing.target":                      {LocaleZH: "目标", LocaleEN: "Target"},
+	"landing.match":                       {LocaleZH: "匹配规则", LocaleEN: "Match"},
+	"landing.last_collection":             {LocaleZH: "最近采集时间", LocaleEN: "Last collection"},
+	"landing.status":                      {LocaleZH: "状态", LocaleEN: "Status"},
+	"cli.usage":                           {LocaleZH: "用法: %s [参数] <进程1> <进程2> ... <进程N>", LocaleEN: "Usage: %s [flags] <process1> <process2> ... <processN>"},
+	"cli.load_config":                     {LocaleZH: "加载配置失败", LocaleEN: "Error loading config"},
+	"cli.telemetry_path":                  {LocaleZH: "指标路径必须以 / 开头", LocaleEN: "Telemetry path must start with /"},
+	"cli.new_exporter":                    {LocaleZH: "创建 exporter 失败", LocaleEN: "Error creating exporter"},
+	"cli.start_server":                    {LocaleZH: "启动 HTTP 服务失败", LocaleEN: "Error starting HTTP server"},
+	"cli.started":                         {LocaleZH: "exporter 已启动", LocaleEN: "Exporter started"},
+	"cli.stopped":                         {LocaleZH: "exporter 已停止", LocaleEN: "Exporter stopped"},
+	"cli.service":                         {LocaleZH: "管理服务失败: %v", LocaleEN: "Error managing service: %v"},
+	"service.unsupported":                 {LocaleZH: "service 子命令仅支持 Windows, 其他平台请使用 systemd 等服务管理器", LocaleEN: "The service subcommand is only supported on Windows, use systemd or another service manager elsewhere"},
+	"service.usage":                       {LocaleZH: "用法: %s service install [参数...] | uninstall | start | stop", LocaleEN: "Usage: %s service install [flags...] | uninstall | start | stop"},
+	"service.description":                 {LocaleZH: "采集进程 CPU 和内存使用情况的 Prometheus exporter", LocaleEN: "Prometheus exporter for process CPU and memory usage"},
+	"cli.taskstats":                       {LocaleZH: "启动 taskstats 采集失败", LocaleEN: "Error starting taskstats collector"},
+	"cli.dump":                            {LocaleZH: "写入快照失败", LocaleEN: "Error writing snapshot dump"},
+	"cli.leader":                          {LocaleZH: "已获得锁, 切换为主实例", LocaleEN: "Acquired lock, now the active instance"},
+	"cli.standby":                         {LocaleZH: "锁已被其他实例持有, 继续作为备用实例", LocaleEN: "Lock held by another instance, staying on standby"},
+	"cli.handover_load":                   {LocaleZH: "恢复上一个主实例的状态失败", LocaleEN: "Error loading state of the previous active instance"},
+	"cli.handover_loaded":                 {LocaleZH: "已恢复上一个主实例的状态", LocaleEN: "Loaded state of the previous active instance"},
+	"cli.handover_save":                   {LocaleZH: "保存交接状态失败", LocaleEN: "Error saving handover state"},
+	"cli.reload":                          {LocaleZH: "重新加载配置失败", LocaleEN: "Error reloading configuration"},
+	"cli.grpc":                            {LocaleZH: "gRPC 服务异常退出", LocaleEN: "gRPC server stopped unexpectedly"},
+	"cli.grpc_started":                    {LocaleZH: "gRPC 服务已启动", LocaleEN: "gRPC server started"},
+	"cli.procfs":                          {LocaleZH: "无法使用 -path.procfs 指定的 procfs", LocaleEN: "Cannot use the procfs given by -path.procfs"},
+	"cli.otlp":                            {LocaleZH: "初始化 OTLP 指标推送失败", LocaleEN: "Error setting up OTLP metrics export"},
+	"cli.remote_write":                    {LocaleZH: "通过 remote_write 推送指标失败", LocaleEN: "Error pushing metrics over remote_write"},
+	"cli.once":                            {LocaleZH: "单次采集失败", LocaleEN: "Error running a single collection"},
+	"cli.shutdown":                        {LocaleZH: "等待进行中的请求完成超时, 强制关闭", LocaleEN: "Timed out waiting for in-flight requests, closing anyway"},
+	"cli.tracing":                         {LocaleZH: "初始化链路追踪失败", LocaleEN: "Error setting up tracing"},
+	"cli.dry_run":                         {LocaleZH: "解析监控目标失败", LocaleEN: "Error resolving targets"},
+	"dryrun.header":                       {LocaleZH: "目标\t匹配规则\t监控的PID\t其他匹配的PID", LocaleEN: "TARGET\tMATCH\tMONITORED PID\tOTHER MATCHES"},
+	"dryrun.unmatched":                    {LocaleZH: "以下目标没有匹配到任何进程: %s", LocaleEN: "Targets that matched nothing: %s"},
+	"cli.healthcheck":                     {LocaleZH: "健康检查失败: %v", LocaleEN: "Health check failed: %v"},
+	"cli.list":                            {LocaleZH: "列出进程失败: %s", LocaleEN: "Error listing processes: %s"},
+	"checkconfig.header":                  {LocaleZH: "目标\t匹配规则\t聚合方式\t采集间隔\t标签", LocaleEN: "TARGET\tMATCH\tAGGREGATION\tINTERVAL\tLABELS"},
+	"checkconfig.problem":                 {LocaleZH: "错误: %s", LocaleEN: "error: %s"},
+	"checkconfig.ok":                      {LocaleZH: "%s 检查通过, 共 %d 个目标", LocaleEN: "%s is valid, %d targets"},
+	"cli.check_config":                    {LocaleZH: "检查配置失败: %s", LocaleEN: "Error checking config: %s"},
+	"cli.check_config_usage":              {LocaleZH: "用法: check-config [-config.file] config.yaml", LocaleEN: "Usage: check-config [-config.file] config.yaml"},
+	"cli.generate":                        {LocaleZH: "生成失败: %s", LocaleEN: "Error generating output: %s"},
+	"cli.generate_usage":                  {LocaleZH: "用法: generate dashboard|rules [-config.file config.yaml] [进程名...]", LocaleEN: "Usage: generate dashboard|rules [-config.file config.yaml] [process...]"},
+	"dashboard.title":                     {LocaleZH: "进程监控", LocaleEN: "Processes"},
+	"dashboard.cpu":                       {LocaleZH: "CPU 使用率", LocaleEN: "CPU usage"},
+	"dashboard.memory":                    {LocaleZH: "内存使用率", LocaleEN: "Memory usage"},
+	"dashboard.rss":                       {LocaleZH: "常驻内存", LocaleEN: "Resident memory"},
+	"dashboard.up":                        {LocaleZH: "进程是否存在", LocaleEN: "Process up"},
+	"dashboard.restarts":                  {LocaleZH: "最近 1 小时的重启次数", LocaleEN: "Restarts in the last hour"},
+	"dashboard.fds":                       {LocaleZH: "文件描述符使用率", LocaleEN: "File descriptor usage"},
+	"dashboard.threads":                   {LocaleZH: "线程数", LocaleEN: "Threads"},
+	"dashboard.io":                        {LocaleZH: "磁盘读写", LocaleEN: "Disk I/O"},
+	"rules.down":                          {LocaleZH: "{{ $labels.instance }} 上的 {{ $labels.process }} 没有运行", LocaleEN: "{{ $labels.process }} is not running on {{ $labels.instance }}"},
+	"rules.restarting":                    {LocaleZH: "{{ $labels.process }} 在 15 分钟内重启了 {{ $value }} 次", LocaleEN: "{{ $labels.process }} restarted {{ $value }} times in 15 minutes"},
+	"rules.high_cpu":                      {LocaleZH: "{{ $labels.process }} 的 CPU 使用率持续偏高", LocaleEN: "{{ $labels.process }} has high CPU usage"},
+	"rules.high_memory":                   {LocaleZH: "{{ $labels.process }} 的内存使用率持续偏高", LocaleEN: "{{ $labels.process }} has high memory usage"},
+	"rules.fds":                           {LocaleZH: "{{ $labels.process }} 的文件描述符即将用尽", LocaleEN: "{{ $labels.process }} is running out of file descriptors"},
+	"ui.title":                            {LocaleZH: "进程概览", LocaleEN: "Process overview"},
+	"ui.updated":                          {LocaleZH: "更新于", LocaleEN: "Updated at"},
+	"ui.error":                            {LocaleZH: "刷新失败", LocaleEN: "Refresh failed"},
+	"ui.memory":                           {LocaleZH: "内存 (RSS)", LocaleEN: "Memory (RSS)"},
+	"ui.fds":                              {LocaleZH: "文件描述符", LocaleEN: "FDs"},
+	"ui.threads":                          {LocaleZH: "线程数", LocaleEN: "Threads"},
+	"ui.uptime":                           {LocaleZH: "运行时间", LocaleEN: "Uptime"},
+	"ui.down":                             {LocaleZH: "未运行", LocaleEN: "down"},
+	"debug.last_cycle":                    {LocaleZH: "最近一个采集周期结束于", LocaleEN: "Last cycle finished at"},
+	"debug.last_attempt":                  {LocaleZH: "最近一次尝试", LocaleEN: "Last attempt"},
+	"debug.next_collection":               {LocaleZH: "下一次采集", LocaleEN: "Next collection"},
+	"debug.throttled":                     {LocaleZH: "等待中", LocaleEN: "throttled"},
+	"debug.failures":                      {LocaleZH: "连续失败", LocaleEN: "Consecutive failures"},
+	"debug.last_error":                    {LocaleZH: "最近一次错误", LocaleEN: "Last error"},
+	"flag.web.enable-admin-api":           {LocaleZH: "允许通过 POST 和 DELETE /api/v1/targets 增删监控目标, 建议同时通过 -web.config.file 启用认证", LocaleEN: "Allow adding and removing targets via POST and DELETE /api/v1/targets, preferably together with authentication via -web.config.file"},
+	"flag.web.enable-pprof":               {LocaleZH: "在 /debug/pprof/ 提供 Go 的 pprof 性能分析接口", LocaleEN: "Expose Go pprof profiling handlers at /debug/pprof/"},
+	"list.header":                         {LocaleZH: "PID\t进程名\t用户\t命令行", LocaleEN: "PID\tNAME\tUSER\tCMDLINE"},
+	"top.title":                           {LocaleZH: "进程监控  %s  (Ctrl-C 退出)", LocaleEN: "Process monitor  %s  (Ctrl-C to quit)"},
+	"report.header":                       {LocaleZH: "进程	PID	CPU%	MEM%	RSS(MiB)	FDS	状态", LocaleEN: "PROCESS	PID	CPU%	MEM%	RSS(MiB)	FDS	STATUS"},
+	"report.down":                         {LocaleZH: "未运行", LocaleEN: "down"},
+	"report.summary":                      {LocaleZH: "%d/%d 个目标正在运行", LocaleEN: "%d/%d targets up"},
+	"flag.plugin":                         {LocaleZH: "加载的采集器 Go plugin 路径, 可重复指定", LocaleEN: "Path of a collector Go plugin to load, repeatable"},
+	"flag.web.listen-address":             {LocaleZH: "HTTP 服务的监听地址, 如 :9100、127.0.0.1:9100、[fe80::1%eth0]:9100 或 unix:///run/process-exporter.sock", LocaleEN: "Address to listen on for HTTP, e.g. :9100, 127.0.0.1:9100, [fe80::1%eth0]:9100 or unix:///run/process-exporter.sock"},
+	"flag.web.telemetry-path":             {LocaleZH: "输出指标的 HTTP 路径", LocaleEN: "Path under which to expose metrics"},
+	"flag.collector.interval":             {LocaleZH: "采集周期的间隔, 覆盖配置文件, 默认 5s", LocaleEN: "Interval between collection cycles, overrides the config file, 5s by default"},
+	"flag.web.config.file":                {LocaleZH: "启用 TLS 或 basic auth 的 web 配置文件路径, 格式见 exporter-toolkit", LocaleEN: "Path to a web configuration file enabling TLS or basic auth, see exporter-toolkit"},
+	"flag.discover.docker":                {LocaleZH: "查询监控的进程所在的 Docker 容器, 并允许用 container: 规则按容器名匹配", LocaleEN: "Look up the Docker container of monitored processes and allow matching targets by container name with container:"},
+	"flag.discover.docker-socket":         {LocaleZH: "Docker API 的 unix socket", LocaleEN: "Unix socket of the Docker API"},
+	"flag.discover.systemd-units":         {LocaleZH: "以逗号分隔的 systemd unit, 每个 unit 作为一个监控目标, 监控其主进程和所有子进程", LocaleEN: "Comma-separated systemd units to monitor as targets, with their main and child processes"},
+	"flag.discover.kubelet-url":           {LocaleZH: "kubelet 的地址, 如 https://127.0.0.1:10250, 设置后查询监控的进程所在的 pod", LocaleEN: "Kubelet URL such as https://127.0.0.1:10250 to look up the pod of monitored processes"},
+	"flag.discover.kubelet-insecure":      {LocaleZH: "访问 kubelet 时不校验其服务证书", LocaleEN: "Do not verify the kubelet serving certificate"},
+	"flag.collector.by-user":              {LocaleZH: "按所属用户聚合主机上的所有进程, 导出 process_user_* 指标", LocaleEN: "Aggregate all processes on the host by owning user as process_user_* metrics"},
+	"flag.collector.topn":                 {LocaleZH: "大于 0 时导出主机上 CPU 使用率或常驻内存最高的 N 个进程", LocaleEN: "Export the top N processes on the host by CPU or memory when greater than 0"},
+	"flag.collector.topn.sort":            {LocaleZH: "前 N 个进程的排序方式: cpu 或 memory", LocaleEN: "Sort the top N processes by cpu or memory"},
+	"flag.collector.sample-interval":      {LocaleZH: "大于 0 时每隔该时间采样一次 CPU 使用率和常驻内存并导出为直方图, 如 1s", LocaleEN: "If positive, sample CPU usage and resident memory at this interval and export them as histograms, e.g. 1s"},
+	"flag.collector.gpu":                  {LocaleZH: "通过 nvidia-smi (NVML) 查询监控的进程使用的 GPU 显存和使用率", LocaleEN: "Query GPU memory and utilization of monitored processes with nvidia-smi (NVML)"},
+	"flag.web.systemd-socket":             {LocaleZH: "使用 systemd socket 激活传入的 socket, 忽略 -web.listen-address", LocaleEN: "Use the socket passed by systemd socket activation instead of -web.listen-address"},
+	"flag.web.listen-family":              {LocaleZH: "监听的地址族: dual (IPv4 和 IPv6), ipv4, ipv6 或 unix (监听地址为 socket 路径)", LocaleEN: "Address family to listen on: dual (IPv4 and IPv6), ipv4, ipv6 or unix (listen address is a socket path)"},
+	"flag.process.exclude":                {LocaleZH: "匹配监控目标时排除的进程, 规则与 -process.match 相同, 如 cmdline:grep .*, 可重复指定", LocaleEN: "Processes to skip when matching targets, same rules as -process.match, e.g. cmdline:grep .*, repeatable"},
+	"flag.process.match":                  {LocaleZH: "按规则匹配的监控目标, 如 name:nginx.* 或 cmdline-glob:*app.jar*, 可重复指定", LocaleEN: "Target matched by a rule, e.g. name:nginx.* or cmdline-glob:*app.jar*, repeatable"},
+	"flag.config.file":                    {LocaleZH: "YAML 配置文件路径", LocaleEN: "Path to the YAML configuration file"},
+	"flag.units.cpu":                      {LocaleZH: "CPU 指标单位: percent 或 ratio, 覆盖配置文件", LocaleEN: "CPU metric unit: percent or ratio, overrides the config file"},
+	"flag.units.memory":                   {LocaleZH: "内存指标单位: percent, ratio 或 bytes, 覆盖配置文件", LocaleEN: "Memory metric unit: percent, ratio or bytes, overrides the config file"},
+	"flag.log.format":                     {LocaleZH: "日志格式: logfmt 或 json", LocaleEN: "Log format: logfmt or json"},
+	"flag.log.level":                      {LocaleZH: "日志级别: debug, info, warn 或 error", LocaleEN: "Log level: debug, info, warn or error"},
+	"flag.log.dedup-interval":             {LocaleZH: "在该时间窗口内重复的相同日志只输出一次, 0 表示不合并", LocaleEN: "Collapse identical log lines repeated within this window, 0 disables"},
+	"flag.log.eventlog":                   {LocaleZH: "同时以该来源名写入 Windows 事件日志, 为空时不写入 (仅 Windows)", LocaleEN: "Also write logs to the Windows event log under this source name, empty disables (Windows only)"},
+	"flag.tracing.endpoint":               {LocaleZH: "OTLP gRPC 链路追踪地址 (host:port), 为空时不上报 span", LocaleEN: "OTLP gRPC endpoint (host:port) to send collection spans to, empty disables tracing"},
+	"flag.grpc.listen-address":            {LocaleZH: "提供 gRPC 流式接口 WatchProcessStats 的监听地址, 为空时不启用", LocaleEN: "Address to serve the WatchProcessStats gRPC streaming API on, disabled when empty"},
+	"flag.otlp.endpoint":                  {LocaleZH: "通过 OTLP 推送指标的地址, 如 otel-collector:4317, 为空时不推送", LocaleEN: "OTLP endpoint to push metrics to, such as otel-collector:4317, disabled when empty"},
+	"flag.otlp.protocol":                  {LocaleZH: "OTLP 协议: grpc 或 http/protobuf", LocaleEN: "OTLP protocol: grpc or http/protobuf"},
+	"flag.otlp.insecure":                  {LocaleZH: "连接 OTLP 地址时不使用 TLS", LocaleEN: "Connect to the OTLP endpoint without TLS"},
+	"flag.otlp.interval":                  {LocaleZH: "推送 OTLP 指标的间隔, 为 0 时与采集周期相同", LocaleEN: "Interval between OTLP pushes, the collection interval when 0"},
+	"flag.otlp.disable-scrape":            {LocaleZH: "只通过 OTLP 推送, 不提供指标的抓取接口", LocaleEN: "Only push metrics over OTLP and do not serve the scrape endpoint"},
+	"flag.remote-write.url":               {LocaleZH: "Prometheus remote_write 地址, 如 https://prometheus.example.com/api/v1/write, 为空时不推送", LocaleEN: "Prometheus remote_write URL such as https://prometheus.example.com/api/v1/write, disabled when empty"},
+	"flag.remote-write.interval":          {LocaleZH: "推送 remote_write 的间隔, 为 0 时与采集周期相同", LocaleEN: "Interval between remote_write pushes, the collection interval when 0"},
+	"flag.remote-write.job":               {LocaleZH: "推送的序列上的 job 标签", LocaleEN: "Job label of the pushed series"},
+	"flag.remote-write.bearer-token-file": {LocaleZH: "推送时使用的 bearer token 文件", LocaleEN: "File with the bearer token for remote_write"},
+	"flag.remote-write.tls.ca-file":       {LocaleZH: "校验 remote_write 服务端证书的 CA 文件", LocaleEN: "CA file to verify the remote_write server certificate"},
+	"flag.remote-write.tls.cert-file":     {LocaleZH: "remote_write 客户端证书文件", LocaleEN: "Client certificate file for remote_write"},
+	"flag.remote-write.tls.key-file":      {LocaleZH: "remote_write 客户端私钥文件", LocaleEN: "Client key file for remote_write"},
+	"flag.remote-write.tls.insecure-skip-verify": {LocaleZH: "不校验 remote_write 服务端证书", LocaleEN: "Do not verify the remote_write server certificate"},
+	"flag.once":                         {LocaleZH: "只采集一次, 推送到 -pushgateway.url 或按 -format 打印后退出", LocaleEN: "Collect once, then push to -pushgateway.url or print in -format and exit"},
+	"flag.format":                       {LocaleZH: "-once 打印的格式: text (Prometheus 文本格式)、table (表格) 或 json", LocaleEN: "Output format of -once: text (Prometheus text format), table or json"},
+	"flag.pushgateway.url":              {LocaleZH: "-once 时推送指标的 Pushgateway 地址, 如 http://pushgateway:9091", LocaleEN: "Pushgateway URL such as http://pushgateway:9091 to push to with -once"},
+	"flag.pushgateway.job":              {LocaleZH: "推送到 Pushgateway 时的 job 名称", LocaleEN: "Job name used when pushing to the Pushgateway"},
+	"flag.pushgateway.grouping":         {LocaleZH: "推送到 Pushgateway 时的分组标签, 如 instance=db1,batch=nightly, 默认 instance 为主机名", LocaleEN: "Grouping labels such as instance=db1,batch=nightly used when pushing to the Pushgateway, instance defaults to the hostname"},
+	"flag.web.shutdown-timeout":         {LocaleZH: "收到 SIGTERM 后等待进行中的抓取完成的最长时间", LocaleEN: "Maximum time to wait for in-flight scrapes after SIGTERM"},
+	"flag.version":                      {LocaleZH: "打印版本信息后退出", LocaleEN: "Print version information and exit"},
+	"flag.service.install":              {LocaleZH: "以其余参数注册为 Windows 服务后退出, 与 service install 相同", LocaleEN: "Install a Windows service with the remaining flags and exit, same as service install"},
+	"flag.service.run":                  {LocaleZH: "以 Windows 服务运行, 由服务管理器启动时会自动检测", LocaleEN: "Run as a Windows service, detected automatically when started by the service manager"},
+	"flag.tracing.insecure":             {LocaleZH: "连接链路追踪地址时不使用 TLS", LocaleEN: "Connect to the tracing endpoint without TLS"},
+	"flag.collector.taskstats":          {LocaleZH: "通过 netlink taskstats 统计短命进程的 CPU 和 IO (仅 Linux, 需要 CAP_NET_ADMIN)", LocaleEN: "Account CPU and IO of short-lived processes via netlink taskstats (Linux only, needs CAP_NET_ADMIN)"},
+	"flag.dump.dir":                     {LocaleZH: "收到 SIGUSR1 或 POST /-/dump 时写入快照文件的目录", LocaleEN: "Directory to write snapshot dumps to on SIGUSR1 or POST /-/dump"},
+	"flag.leader.lock-file":             {LocaleZH: "冗余部署时用于选主的锁文件, 只有获得锁的实例采集数据, 为空时不选主", LocaleEN: "Lock file for leader election between redundant instances, only the holder collects, empty disables"},
+	"flag.state.file":                   {LocaleZH: "定期写入累计的计数器、启动时从中恢复的状态文件, 重新部署后计数器不归零; 与 -leader.lock-file 一起使用时等同于 -leader.state-file", LocaleEN: "State file counters are periodically written to and restored from at startup so they survive redeploys; same as -leader.state-file with -leader.lock-file"},
+	"flag.leader.state-file":            {LocaleZH: "主实例定期写入累计值、备用实例接管时读取的状态文件, 为空时不交接", LocaleEN: "State file the active instance periodically writes its counters to and a standby reads on takeover, empty disables"},
+	"flag.collector.cache-ttl":          {LocaleZH: "大于 0 时并发的抓取依次进行, 该时间内的抓取复用上一次的结果, 如 2s", LocaleEN: "If positive, serialize concurrent scrapes and serve scrapes within this duration from the previous result, e.g. 2s"},
+	"flag.collector.on-scrape":          {LocaleZH: "在每次抓取时采集, 而不是每 5 秒在后台采集一次", LocaleEN: "Collect on every scrape instead of every 5 seconds in the background"},
+	"flag.dry-run":                      {LocaleZH: "只打印配置的目标在当前进程表中匹配到的进程, 不启动服务", LocaleEN: "Print which processes the configured targets match and exit without starting the server"},
+	"flag.healthcheck.timeout":          {LocaleZH: "健康检查请求的超时时间", LocaleEN: "Timeout of the health check request"},
+	"flag.list.match":                   {LocaleZH: "只列出匹配该监控目标的进程", LocaleEN: "Only list processes matched by this target"},
+	"flag.top.interval":                 {LocaleZH: "刷新间隔", LocaleEN: "Refresh interval"},
+	"flag.report.console":               {LocaleZH: "定期在控制台打印各目标的概要", LocaleEN: "Periodically print a per-target summary to the console"},
+	"flag.report.interval":              {LocaleZH: "控制台概要的打印间隔", LocaleEN: "Interval of the console summary"},
+	"flag.metrics.native-histograms":    {LocaleZH: "为直方图指标同时提供 native histogram, 仅 protobuf 格式可见", LocaleEN: "Also expose histograms as native histograms, visible in the protobuf format only"},
+	"flag.collector.smaps":              {LocaleZH: "从 /proc/<pid>/smaps_rollup 读取 swap (包括共享内存), 并导出 process_memory_swap_pss_bytes", LocaleEN: "Read swap from /proc/<pid>/smaps_rollup (including shared memory) and export process_memory_swap_pss_bytes"},
+	"flag.collector.binary-hash":        {LocaleZH: "计算监控的进程的可执行文件的 sha256, 并检测磁盘上的文件被替换而进程没有重启", LocaleEN: "Hash the executables of monitored processes and detect binaries replaced on disk without a restart"},
+	"flag.collector.textfile.directory": {LocaleZH: "抓取时读取该目录中的 *.prom 文件并与进程指标一起输出, 为空时不读取", LocaleEN: "Directory to read *.prom files from on each scrape and expose alongside the process metrics, disabled when empty"},
+	"flag.collector.host":               {LocaleZH: "导出主机的 CPU 时间、内存、负载和进程数, 小型设备上可以不再部署 node_exporter", LocaleEN: "Export host CPU time, memory, load average and process count so small devices can skip node_exporter"},
+	"flag.collector.per-cpu":            {LocaleZH: "按 CPU 导出监控的进程的 CPU 时间 (仅 Linux)", LocaleEN: "Export CPU time of monitored processes per CPU (Linux only)"},
+	"flag.path.procfs":                  {LocaleZH: "读取进程信息的 procfs 挂载点, 在容器中监控主机进程时指向挂载进来的主机 /proc, 如 /host/proc", LocaleEN: "procfs mount point to read processes from, point it at the host /proc mounted into the container (e.g. /host/proc) to monitor host processes"},
+	"flag.kernel-threads":               {LocaleZH: "允许匹配和监控内核线程, 目标可以写成 [kswapd0] 的形式", LocaleEN: "Allow matching and monitoring kernel threads, targets may be written as [kswapd0]"},
+	"flag.metrics.series-ttl":           {LocaleZH: "大于 0 时目标的进程消失超过该时间后删除其所有序列, 如 1h", LocaleEN: "If positive, delete all series of a target whose process has been gone for this long, e.g. 1h"},
+	"flag.metrics.namespace":            {LocaleZH: "监控目标指标名的前缀, 默认为 process", LocaleEN: "Prefix of the target metric names, process by default"},
+	"flag.metrics.legacy-names":         {LocaleZH: "过渡期间继续以旧的名称 Cpuinfo、Meminfo 和 Pidinfo 导出", LocaleEN: "Keep exporting the old names Cpuinfo, Meminfo and Pidinfo during the transition"},
+	"flag.locale":                       {LocaleZH: "指标说明和日志的语言: zh 或 en", LocaleEN: "Language of metric help and log messages: zh or en"},
+}
+
+// SetLocale 设置指标说明和日志使用的语言, 需要在 New 之前调用才会影响指标说明
+func SetLocale(l string) error {
+	if l != LocaleZH && l != LocaleEN {
+		return fmt.Errorf("unsupported locale %q, want %s or %s", l, LocaleZH, LocaleEN)
+	}
+	localeMu.Lock()
+	locale = l
+	localeMu.Unlock()
+	return nil
+}
+
+// DetectLocale 根据 LC_ALL / LC_MESSAGES / LANG 环境变量推断语言, 未设置时为中文
+func DetectLocale() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		v := os.Getenv(env)
+		if v == "" {
+			continue
+		}
+		if strings.HasPrefix(v, "zh") {
+			return LocaleZH
+		}
+		if v == "C" || v == "POSIX" {
+			break
+		}
+		return LocaleEN
+	}
+	return LocaleZH
+}
+
+// T 返回当前语言下 id 对应的消息, 并用 args 格式化
+func T(id string, args ...interface{}) string {
+	localeMu.RLock()
+	l := locale
+	localeMu.RUnlock()
+
+	msg, ok := messages[id][l]
+	if !ok {
+		msg, ok = messages[id][LocaleZH]
+	}
+	if !ok {
+		msg = id
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
@@ -0,0 +1,53 @@
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shirou/gopsutil/process"
+)
+
+// ioCount 记录一个目标上一次采集时监控的进程及其磁盘 I/O 计数
+type ioCount struct {
+	pid int32
+	io  process.IOCountersStat
+	// include_children 时各后代进程的计数
+	children map[int32]process.IOCountersStat
+
+	// 为 true 时下一次采集只记录基线, 用于从其他实例接管计数器之后
+	baseline bool
+}
+
+// updateIO 把监控的进程自上次采集以来读写的字节数和次数累加到 io_*_total,
+// 与 updateCPUTime 一样, 进程重启后计入新进程启动以来的全部 I/O. children 为后代进程的计数,
+// 新出现的后代进程同样计入其全部 I/O
+func (e *Exporter) updateIO(processName string, pid int32, cur *process.IOCountersStat, children map[int32]process.IOCountersStat) {
+	last, ok := e.ioCount[processName]
+	e.ioCount[processName] = &ioCount{pid: pid, io: *cur, children: children}
+	add := func(vec *prometheus.CounterVec, cur, prev uint64) {
+		c := vec.WithLabelValues(processName)
+		if cur >= prev {
+			c.Add(float64(cur - prev))
+		}
+	}
+	addIO := func(cur, prev process.IOCountersStat) {
+		add(e.ioReadBytes, cur.ReadBytes, prev.ReadBytes)
+		add(e.ioWriteBytes, cur.WriteBytes, prev.WriteBytes)
+		add(e.ioReads, cur.ReadCount, prev.ReadCount)
+		add(e.ioWrites, cur.WriteCount, prev.WriteCount)
+	}
+	var prev process.IOCountersStat
+	if ok && last.pid == pid {
+		prev = last.io
+	} else if ok && last.baseline {
+		prev = *cur
+	}
+	addIO(*cur, prev)
+	for c, io := range children {
+		var prev process.IOCountersStat
+		if ok && last.pid == pid {
+			prev = last.children[c]
+		} else if ok && last.baseline {
+			prev = io
+		}
+		addIO(io, prev)
+	}
+}
@@ -0,0 +1,94 @@
+package exporter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// journalRestartDelay 是 journalctl 退出后重新启动前的等待时间
+const journalRestartDelay = 10 * time.Second
+
+// journalPriorities 是 syslog 优先级的名称, 下标即 journal 中 PRIORITY 字段的值
+var journalPriorities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// journalWatch 通过 journalctl -f 跟踪一个 systemd unit 的日志, 统计不低于 priority 的条目数
+type journalWatch struct {
+	target   string
+	unit     string
+	priority string
+}
+
+func newJournalWatch(target string, cfg JournalConfig) (*journalWatch, error) {
+	if cfg.Unit == "" {
+		return nil, fmt.Errorf("journal for %s: unit is required", target)
+	}
+	w := &journalWatch{target: target, unit: cfg.Unit, priority: cfg.Priority}
+	if w.priority == "" {
+		w.priority = "err"
+	}
+	for _, p := range journalPriorities {
+		if p == w.priority {
+			return w, nil
+		}
+	}
+	return nil, fmt.Errorf("journal for %s: unknown priority %q", target, cfg.Priority)
+}
+
+// follow 运行 journalctl 直到 ctx 被取消, 对每个条目调用 entry, 参数为优先级名称
+func (w *journalWatch) follow(ctx context.Context, entry func(priority string)) error {
+	cmd := exec.CommandContext(ctx, "journalctl", "--follow", "--lines=0", "--output=json",
+		"--unit="+w.unit, "--priority="+w.priority)
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	sc := bufio.NewScanner(out)
+	sc.Buffer(make([]byte, 64<<10), 1<<20)
+	for sc.Scan() {
+		var e struct {
+			Priority string `json:"PRIORITY"`
+		}
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			continue
+		}
+		name := e.Priority
+		var n int
+		if _, err := fmt.Sscan(e.Priority, &n); err == nil && n >= 0 && n < len(journalPriorities) {
+			name = journalPriorities[n]
+		}
+		entry(name)
+	}
+	return cmd.Wait()
+}
+
+// runJournals 在后台跟踪所有配置的 unit, journalctl 意外退出时稍后重新启动
+func (e *Exporter) runJournals(ctx context.Context) {
+	for _, w := range e.journals {
+		go func(w *journalWatch) {
+			for {
+				err := w.follow(ctx, func(priority string) {
+					e.journalEntries.WithLabelValues(w.target, w.unit, priority).Inc()
+				})
+				if ctx.Err() != nil {
+					return
+				}
+				e.logger.Warn(T("log.journal"), "target", w.target, "unit", w.unit, "error", err)
+				t := e.clock.NewTicker(journalRestartDelay)
+				select {
+				case <-ctx.Done():
+					t.Stop()
+					return
+				case <-t.C():
+					t.Stop()
+				}
+			}
+		}(w)
+	}
+}
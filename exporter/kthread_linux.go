@@ -0,0 +1,32 @@
+package exporter
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+)
+
+// pfKthread 是 /proc/<pid>/stat 中 flags 字段表示内核线程的位
+const pfKthread = 0x00200000
+
+// isKernelThread 判断 pid 是否为内核线程, 内核线程没有 cmdline 和 exe
+func isKernelThread(pid int32) bool {
+	data, err := os.ReadFile(procPath(pid, "stat"))
+	if err != nil {
+		return false
+	}
+	// 进程名可能包含空格和括号, 从最后一个 ')' 之后开始解析: state ppid pgrp session tty_nr tpgid flags ...
+	i := bytes.LastIndexByte(data, ')')
+	if i < 0 {
+		return false
+	}
+	fields := bytes.Fields(data[i+1:])
+	if len(fields) < 7 {
+		return false
+	}
+	flags, err := strconv.ParseUint(string(fields[6]), 10, 64)
+	if err != nil {
+		return false
+	}
+	return flags&pfKthread != 0
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package exporter
+
+// isKernelThread 只在 Linux 上能够识别内核线程
+func isKernelThread(pid int32) bool {
+	return false
+}
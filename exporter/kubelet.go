@@ -0,0 +1,146 @@
+//go:build !nokubelet
+
+package exporter
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// 以 DaemonSet 运行时 service account 的令牌和 CA 证书
+	serviceAccountToken = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	serviceAccountCA    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+
+	// 遇到未知的容器时重新获取 pod 列表的最短间隔
+	kubeletRefreshInterval = 10 * time.Second
+)
+
+// podRef 是一个容器所属的 pod
+type podRef struct {
+	namespace, pod, container string
+}
+
+// kubeletClient 通过 kubelet 的 /pods 接口把容器 ID 对应到 pod
+type kubeletClient struct {
+	url    string
+	client *http.Client
+
+	mu         sync.Mutex
+	containers map[string]podRef
+	fetched    time.Time
+}
+
+// newKubeletClient 创建访问 url (如 https://127.0.0.1:10250) 的客户端. kubelet 的服务证书通常是自签名的,
+// insecure 为 true 时不校验证书, 否则使用系统和 service account 的 CA
+func newKubeletClient(url string, insecure bool) (*kubeletClient, error) {
+	cfg := &tls.Config{InsecureSkipVerify: insecure}
+	if !insecure {
+		if pool, err := x509.SystemCertPool(); err == nil {
+			if ca, err := os.ReadFile(serviceAccountCA); err == nil {
+				pool.AppendCertsFromPEM(ca)
+			}
+			cfg.RootCAs = pool
+		}
+	}
+	return &kubeletClient{
+		url:        strings.TrimSuffix(url, "/"),
+		client:     &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}, Timeout: 5 * time.Second},
+		containers: make(map[string]podRef),
+	}, nil
+}
+
+// lookup 返回容器 ID 所属的 pod, 缓存中没有时重新获取 pod 列表
+func (k *kubeletClient) lookup(ctx context.Context, id string) (podRef, bool, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if ref, ok := k.containers[id]; ok {
+		return ref, true, nil
+	}
+	if time.Since(k.fetched) < kubeletRefreshInterval {
+		return podRef{}, false, nil
+	}
+	k.fetched = time.Now()
+	containers, err := k.fetch(ctx)
+	if err != nil {
+		return podRef{}, false, err
+	}
+	k.containers = containers
+	ref, ok := containers[id]
+	return ref, ok, nil
+}
+
+// fetch 获取节点上的 pod 列表, 返回容器 ID 到 pod 的映射
+func (k *kubeletClient) fetch(ctx context.Context) (map[string]podRef, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.url+"/pods", nil)
+	if err != nil {
+		return nil, err
+	}
+	if token, err := os.ReadFile(serviceAccountToken); err == nil {
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("listing pods: %s", resp.Status)
+	}
+	var list struct {
+		Items []struct {
+			Metadata struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+			Status struct {
+				ContainerStatuses []struct {
+					Name        string `json:"name"`
+					ContainerID string `json:"containerID"`
+				} `json:"containerStatuses"`
+			} `json:"status"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("listing pods: %w", err)
+	}
+	containers := make(map[string]podRef)
+	for _, pod := range list.Items {
+		for _, c := range pod.Status.ContainerStatuses {
+			// 如 containerd://<id> 或 docker://<id>
+			_, id, _ := strings.Cut(c.ContainerID, "://")
+			if id != "" {
+				containers[id] = podRef{namespace: pod.Metadata.Namespace, pod: pod.Metadata.Name, container: c.Name}
+			}
+		}
+	}
+	return containers, nil
+}
+
+// setPod 导出监控的进程所在的 pod, pod 变化时删除旧的序列
+func (e *Exporter) setPod(ctx context.Context, processName string, pid int32) {
+	var ref podRef
+	if id := containerID(pid); id != "" {
+		var err error
+		if ref, _, err = e.kubelet.lookup(ctx, id); err != nil {
+			e.logger.Debug(T("log.kubelet"), "target", processName, "container_id", id, "error", err)
+		}
+	}
+	if e.pods[processName] == ref {
+		return
+	}
+	e.podInfo.DeletePartialMatch(prometheus.Labels{"process": processName})
+	e.pods[processName] = ref
+	if ref.pod != "" {
+		e.podInfo.WithLabelValues(processName, ref.namespace, ref.pod, ref.container).Set(1)
+	}
+}
@@ -0,0 +1,23 @@
+//go:build nokubelet
+
+package exporter
+
+import (
+	"context"
+	"errors"
+)
+
+// podRef 是一个容器所属的 pod
+type podRef struct {
+	namespace, pod, container string
+}
+
+// kubeletClient 在使用 nokubelet 标签构建时不可用
+type kubeletClient struct{}
+
+// newKubeletClient 在使用 nokubelet 标签构建时总是返回错误
+func newKubeletClient(url string, insecure bool) (*kubeletClient, error) {
+	return nil, errors.New("built without kubelet support (nokubelet)")
+}
+
+func (e *Exporter) setPod(ctx context.Context, processName string, pid int32) {}
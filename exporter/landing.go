@@ -0,0 +1,109 @@
+package exporter
+
+import (
+	"html/template"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var landingTemplate = template.Must(template.New("landing").Funcs(template.FuncMap{
+	"T": func(key string) string { return T(key) },
+	"pids": func(pids []int32) string {
+		s := make([]string, len(pids))
+		for i, pid := range pids {
+			s[i] = strconv.Itoa(int(pid))
+		}
+		return strings.Join(s, ", ")
+	},
+	"time": func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.Format(time.RFC3339)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Process Exporter</title></head>
+<body>
+<h1>Process Exporter</h1>
+<p>{{T "landing.version"}}: {{.Version}}</p>
+<ul>
+<li><a href="{{.MetricsPath}}">{{.MetricsPath}}</a></li>
+<li><a href="/-/healthy">/-/healthy</a></li>
+<li><a href="/-/ready">/-/ready</a></li>
+<li><a href="/debug/state">/debug/state</a></li>
+<li><a href="/ui/">/ui/</a></li>
+<li><a href="/debug/targets">/debug/targets</a></li>
+<li><a href="/api/v1/config">/api/v1/config</a></li>
+</ul>
+<h2>{{T "landing.targets"}}</h2>
+<table border="1" cellpadding="4">
+<tr><th>{{T "landing.target"}}</th><th>{{T "landing.match"}}</th><th>PID</th><th>{{T "landing.last_collection"}}</th><th>{{T "landing.status"}}</th></tr>
+{{range .Targets}}<tr><td>{{.Target}}</td><td>{{.Match}}</td><td>{{pids .PIDs}}</td><td>{{time .LastCollection}}</td><td>{{if .LastError}}{{.LastError}}{{else if .LastAttempt}}OK{{else}}-{{end}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+var targetsTemplate = template.Must(landingTemplate.New("targets").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Process Exporter - {{T "landing.targets"}}</title></head>
+<body>
+<h1>{{T "landing.targets"}}</h1>
+<p>goroutines: {{.Goroutines}}, {{T "debug.last_cycle"}}: {{time .LastCycle}}</p>
+<table border="1" cellpadding="4">
+<tr><th>{{T "landing.target"}}</th><th>{{T "landing.match"}}</th><th>PID</th><th>{{T "debug.last_attempt"}}</th><th>{{T "landing.last_collection"}}</th><th>{{T "debug.next_collection"}}</th><th>{{T "debug.failures"}}</th><th>{{T "debug.last_error"}}</th></tr>
+{{range .Targets}}<tr><td>{{.Target}}</td><td>{{.Match}}</td><td>{{pids .PIDs}}</td><td>{{time .LastAttempt}}</td><td>{{time .LastCollection}}</td><td>{{time .NextCollection}}{{if .Throttled}} ({{T "debug.throttled"}}){{end}}</td><td>{{.Failures}}</td><td>{{if .LastError}}{{time .LastErrorAt}}: {{.LastError}}{{else}}-{{end}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// TargetsPageHandler 返回 /debug/targets 的 http.Handler, 以 HTML 输出与 /debug/state 相同的内部目标表,
+// 包括每个目标最近一次的错误 (即使之后已经成功), 用于排查卡住的采集
+func (e *Exporter) TargetsPageHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		e.mutex.Lock()
+		views := e.stateViews()
+		e.mutex.Unlock()
+		var last *time.Time
+		if at, _ := e.LastCycle(); !at.IsZero() {
+			last = &at
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		targetsTemplate.Execute(w, struct {
+			Goroutines int
+			LastCycle  *time.Time
+			Targets    []targetStateView
+		}{runtime.NumGoroutine(), last, views})
+	})
+}
+
+// LandingHandler 返回 / 的 http.Handler, 以 HTML 列出版本、指标等接口的链接和各监控目标当前的 PID 及采集状态,
+// 便于在浏览器中检查部署. 其他路径返回 404
+func (e *Exporter) LandingHandler(version, metricsPath string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		e.mutex.Lock()
+		views := e.stateViews()
+		e.mutex.Unlock()
+		// 最近一次采集成功时不显示之前的错误
+		for i, v := range views {
+			if v.LastErrorAt != nil && v.LastAttempt != nil && v.LastAttempt.After(*v.LastErrorAt) {
+				views[i].LastError = ""
+			}
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		landingTemplate.Execute(w, struct {
+			Version     string
+			MetricsPath string
+			Targets     []targetStateView
+		}{version, metricsPath, views})
+	})
+}
@@ -0,0 +1,37 @@
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// legacyCollector 以旧的名称重新导出 vec 中的所有序列, 供依赖 Cpuinfo 等旧名称的看板过渡使用
+type legacyCollector struct {
+	vec  *prometheus.GaugeVec
+	desc *prometheus.Desc
+}
+
+func newLegacyCollector(vec *prometheus.GaugeVec, name, help string) *legacyCollector {
+	return &legacyCollector{vec: vec, desc: prometheus.NewDesc(name, help, []string{"process"}, nil)}
+}
+
+// Describe 实现 prometheus.Collector
+func (c *legacyCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect 实现 prometheus.Collector
+func (c *legacyCollector) Collect(ch chan<- prometheus.Metric) {
+	metrics := make(chan prometheus.Metric)
+	go func() {
+		c.vec.Collect(metrics)
+		close(metrics)
+	}()
+	for m := range metrics {
+		var pb dto.Metric
+		if err := m.Write(&pb); err != nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, pb.GetGauge().GetValue(), pb.GetLabel()[0].GetValue())
+	}
+}
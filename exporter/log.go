@@ -0,0 +1,227 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 日志格式
+const (
+	LogFormatLogfmt = "logfmt"
+	LogFormatJSON   = "json"
+)
+
+// LogOpts 是创建日志所需的参数
+type LogOpts struct {
+	// logfmt 或 json
+	Format string
+
+	// 日志级别, 可在运行时修改. 为空时为 info
+	Level *slog.LevelVar
+
+	// 在该时间窗口内重复出现的相同日志只输出一次, 下次输出时带上被合并的次数. 为 0 时不合并
+	DedupInterval time.Duration
+
+	// 不为空时同时以该来源名写入 Windows 事件日志, 仅 Windows 可用
+	EventLogSource string
+}
+
+// NewLogger 创建写入 w 的日志
+func NewLogger(w io.Writer, opts LogOpts) (*slog.Logger, error) {
+	hopts := &slog.HandlerOptions{}
+	if opts.Level != nil {
+		hopts.Level = opts.Level
+	}
+	var h slog.Handler
+	switch opts.Format {
+	case "", LogFormatLogfmt:
+		h = slog.NewTextHandler(w, hopts)
+	case LogFormatJSON:
+		h = slog.NewJSONHandler(w, hopts)
+	default:
+		return nil, fmt.Errorf("unsupported log format %q, want %s or %s", opts.Format, LogFormatLogfmt, LogFormatJSON)
+	}
+	if opts.EventLogSource != "" {
+		eh, err := newEventLogHandler(opts.EventLogSource, hopts)
+		if err != nil {
+			return nil, fmt.Errorf("opening event log: %w", err)
+		}
+		h = teeHandler{h, eh}
+	}
+	if opts.DedupInterval > 0 {
+		h = &dedupHandler{
+			next:     h,
+			interval: opts.DedupInterval,
+			state:    &dedupState{entries: make(map[string]*dedupEntry)},
+		}
+	}
+	return slog.New(h), nil
+}
+
+// teeHandler 把日志同时交给多个 handler
+type teeHandler []slog.Handler
+
+func (t teeHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	for _, h := range t {
+		if h.Enabled(ctx, l) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	res := make(teeHandler, len(t))
+	for i, h := range t {
+		res[i] = h.WithAttrs(attrs)
+	}
+	return res
+}
+
+func (t teeHandler) WithGroup(name string) slog.Handler {
+	res := make(teeHandler, len(t))
+	for i, h := range t {
+		res[i] = h.WithGroup(name)
+	}
+	return res
+}
+
+func (t teeHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range t {
+		if h.Enabled(ctx, r.Level) {
+			errs = append(errs, h.Handle(ctx, r.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// dedupHandler 合并时间窗口内重复的日志, 例如进程长期不存在时每个周期都会出现的 "未找到进程"
+type dedupHandler struct {
+	next     slog.Handler
+	interval time.Duration
+	state    *dedupState
+
+	// With/WithGroup 附加的属性, 作为去重键的一部分
+	prefix string
+}
+
+type dedupState struct {
+	mu      sync.Mutex
+	entries map[string]*dedupEntry
+	swept   time.Time
+}
+
+type dedupEntry struct {
+	first      time.Time
+	suppressed int
+}
+
+func (h *dedupHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.next.Enabled(ctx, l)
+}
+
+func (h *dedupHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	b.WriteString(h.prefix)
+	for _, a := range attrs {
+		b.WriteString(" " + a.String())
+	}
+	return &dedupHandler{next: h.next.WithAttrs(attrs), interval: h.interval, state: h.state, prefix: b.String()}
+}
+
+func (h *dedupHandler) WithGroup(name string) slog.Handler {
+	return &dedupHandler{next: h.next.WithGroup(name), interval: h.interval, state: h.state, prefix: h.prefix + " " + name + "."}
+}
+
+func (h *dedupHandler) Handle(ctx context.Context, r slog.Record) error {
+	now := r.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	var b strings.Builder
+	b.WriteString(r.Level.String() + " " + r.Message + h.prefix)
+	r.Attrs(func(a slog.Attr) bool {
+		// 耗时每次都不同, 不参与去重
+		if a.Key != "duration" {
+			b.WriteString(" " + a.String())
+		}
+		return true
+	})
+	key := b.String()
+
+	h.state.mu.Lock()
+	e := h.state.entries[key]
+	if e != nil && now.Sub(e.first) < h.interval {
+		e.suppressed++
+		h.state.mu.Unlock()
+		return nil
+	}
+	suppressed := 0
+	if e != nil {
+		suppressed = e.suppressed
+	}
+	h.state.entries[key] = &dedupEntry{first: now}
+	h.state.sweep(now, h.interval)
+	h.state.mu.Unlock()
+
+	if suppressed > 0 {
+		r = r.Clone()
+		r.AddAttrs(slog.Int("repeated", suppressed))
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// sweep 清理长时间没有再出现的日志, 避免 entries 无限增长
+func (s *dedupState) sweep(now time.Time, interval time.Duration) {
+	if now.Sub(s.swept) < interval {
+		return
+	}
+	s.swept = now
+	for key, e := range s.entries {
+		if now.Sub(e.first) >= 2*interval {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// LogLevelHandler 返回查看和修改日志级别的 http.Handler:
+// GET 返回当前级别, PUT 以请求体 (如 "debug") 或 level 参数设置新级别
+func LogLevelHandler(level *slog.LevelVar, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			text := r.URL.Query().Get("level")
+			if text == "" {
+				body, err := io.ReadAll(io.LimitReader(r.Body, 64))
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				text = string(body)
+			}
+			var l slog.Level
+			if err := l.UnmarshalText([]byte(strings.TrimSpace(text))); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			old := level.Level()
+			level.Set(l)
+			logger.Info(T("log.level_changed"), "from", old, "to", l)
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		fmt.Fprintln(w, level.Level())
+	})
+}
@@ -0,0 +1,61 @@
+package exporter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// validateLogFiles 检查目标的 log_files 中的通配符
+func validateLogFiles(t TargetConfig) error {
+	for _, pattern := range t.LogFiles {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("target %s: log_files %q: %w", t.Name, pattern, err)
+		}
+	}
+	return nil
+}
+
+// updateLogFiles 导出每个目标的 log_files 匹配到的文件的大小, 并累计写入的字节数.
+// 第一次看到的文件只记录大小; 文件变小 (被截断或轮转为新文件) 时按从 0 开始增长计算. 不再匹配的文件删除其序列
+func (e *Exporter) updateLogFiles() {
+	for _, name := range e.processNames {
+		prev := e.logFiles[name]
+		sizes := make(map[string]int64)
+		for _, pattern := range e.targets[name].LogFiles {
+			// 已在加载配置时校验过
+			paths, _ := filepath.Glob(pattern)
+			for _, path := range paths {
+				fi, err := os.Stat(path)
+				if err != nil || !fi.Mode().IsRegular() {
+					continue
+				}
+				size := fi.Size()
+				last, ok := prev[path]
+				// 文件名可能不是合法的 UTF-8
+				written := e.logFileWritten.WithLabelValues(name, sanitizeName(path))
+				switch {
+				case !ok:
+					written.Add(0)
+				case size >= last:
+					written.Add(float64(size - last))
+				default:
+					written.Add(float64(size))
+				}
+				e.logFileSize.WithLabelValues(name, sanitizeName(path)).Set(float64(size))
+				sizes[path] = size
+			}
+		}
+		for path := range prev {
+			if _, ok := sizes[path]; !ok {
+				e.logFileSize.DeleteLabelValues(name, sanitizeName(path))
+				e.logFileWritten.DeleteLabelValues(name, sanitizeName(path))
+			}
+		}
+		if len(sizes) == 0 {
+			delete(e.logFiles, name)
+			continue
+		}
+		e.logFiles[name] = sizes
+	}
+}
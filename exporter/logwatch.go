@@ -0,0 +1,130 @@
+package exporter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+)
+
+// maxLogReadPerCycle 限制每个周期从一个日志文件读取的字节数, 避免日志暴增时拖慢采集
+const maxLogReadPerCycle = 4 << 20
+
+// logWatch 在每个采集周期读取日志文件新增的内容, 并统计匹配各个模式的行数
+type logWatch struct {
+	target   string
+	path     string
+	patterns []*regexp.Regexp
+
+	info    os.FileInfo
+	offset  int64
+	partial []byte
+}
+
+func newLogWatch(target string, cfg LogWatchConfig) (*logWatch, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("log watch for %s: path is required", target)
+	}
+	w := &logWatch{target: target, path: cfg.Path}
+	for _, p := range cfg.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("log watch for %s: %w", target, err)
+		}
+		w.patterns = append(w.patterns, re)
+	}
+	return w, nil
+}
+
+// newLogWatches 创建 targets 中配置的所有日志跟踪, old 中目标、路径和模式都相同的跟踪继续使用, 以保留读取位置
+func (e *Exporter) newLogWatches(targets []TargetConfig, old []*logWatch) ([]*logWatch, error) {
+	prev := make(map[string]*logWatch, len(old))
+	for _, w := range old {
+		prev[w.key()] = w
+	}
+	var watches []*logWatch
+	for _, t := range targets {
+		for _, lc := range t.Logs {
+			w, err := newLogWatch(t.Name, lc)
+			if err != nil {
+				return nil, err
+			}
+			if p, ok := prev[w.key()]; ok {
+				w = p
+			}
+			for _, re := range w.patterns {
+				e.logLines.WithLabelValues(t.Name, lc.Path, re.String())
+			}
+			watches = append(watches, w)
+		}
+	}
+	return watches, nil
+}
+
+// key 标识一个日志跟踪的配置
+func (w *logWatch) key() string {
+	key := w.target + "\x00" + w.path
+	for _, re := range w.patterns {
+		key += "\x00" + re.String()
+	}
+	return key
+}
+
+// poll 读取上次之后追加的完整行, 对每一行调用 match. 第一次读取时从文件末尾开始,
+// 文件被轮转 (换成了另一个文件) 或被截断时从头开始读取
+func (w *logWatch) poll(match func(pattern string)) error {
+	f, err := os.Open(w.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	switch {
+	case w.info == nil:
+		w.offset = info.Size()
+	case !os.SameFile(w.info, info) || info.Size() < w.offset:
+		w.offset = 0
+		w.partial = nil
+	}
+	w.info = info
+	if info.Size() == w.offset {
+		return nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(io.NewSectionReader(f, w.offset, info.Size()-w.offset), maxLogReadPerCycle))
+	if err != nil {
+		return err
+	}
+	w.offset += int64(len(data))
+	data = append(w.partial, data...)
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		w.partial = data
+		return nil
+	}
+	w.partial = append([]byte(nil), data[end+1:]...)
+	for _, line := range bytes.Split(data[:end], []byte("\n")) {
+		for _, re := range w.patterns {
+			if re.Match(line) {
+				match(re.String())
+			}
+		}
+	}
+	return nil
+}
+
+// pollLogs 读取所有日志文件新增的内容
+func (e *Exporter) pollLogs() {
+	for _, w := range e.logWatches {
+		err := w.poll(func(pattern string) {
+			e.logLines.WithLabelValues(w.target, w.path, pattern).Inc()
+		})
+		if err != nil {
+			e.logger.Warn(T("log.tail"), "target", w.target, "path", w.path, "error", err)
+		}
+	}
+}
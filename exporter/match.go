@@ -0,0 +1,185 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"github.com/shirou/gopsutil/process"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// matcher 描述监控目标如何匹配进程. 不带前缀 (或带 exact: 前缀) 时按进程名精确匹配;
+// name: 和 cmdline: 后跟正则表达式, name-glob: 和 cmdline-glob: 后跟通配符 (* 和 ?),
+// 分别与进程名或以空格连接的完整命令行整体匹配; container: 后跟正则表达式, 与进程所在的 Docker 容器名匹配;
+// unit: 后跟 systemd unit 名, 匹配该 unit 的 cgroup 中的所有进程
+type matcher struct {
+	// 带前缀的规范写法, 用作 match 标签
+	pattern string
+
+	exact   string
+	re      *regexp.Regexp
+	cmdline bool
+
+	// container: 规则通过 docker 查询容器名
+	container bool
+	docker    *dockerClient
+
+	// unit: 规则的 unit 名
+	unit string
+
+	// 匹配该目标时排除的进程, 来自 exclude 配置
+	excludes []*matcher
+
+	// 由 groups 规则生成的目标匹配该规则提取到的名称为 groupName 的进程
+	group     *nameGroup
+	groupName string
+}
+
+var selfPID = int32(os.Getpid())
+
+func parseMatcher(s string) (*matcher, error) {
+	kind, expr, _ := strings.Cut(s, ":")
+	m := &matcher{pattern: s}
+	switch kind {
+	case "exact":
+		m.exact = expr
+	case "name", "cmdline":
+		re, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("target %s: %w", s, err)
+		}
+		m.re, m.cmdline = re, kind == "cmdline"
+	case "container":
+		re, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("target %s: %w", s, err)
+		}
+		m.re, m.container = re, true
+	case "unit":
+		m.unit = expr
+	case "name-glob", "cmdline-glob":
+		m.re, m.cmdline = regexp.MustCompile("^"+globRegexp(expr)+"$"), kind == "cmdline-glob"
+	default:
+		// 进程名本身可能带有冒号, 如 kworker/0:1
+		m.pattern, m.exact = "exact:"+s, s
+	}
+	return m, nil
+}
+
+// globRegexp 把通配符转换为正则表达式, * 匹配任意字符串 (包括 /), ? 匹配任意一个字符
+func globRegexp(glob string) string {
+	var b strings.Builder
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	return b.String()
+}
+
+// match 判断进程名为 name 的进程 p 是否匹配.
+// 内核线程只有在 kernelThreads 为 true 时才会被匹配, 此时精确匹配也可以写成 ps 中的 [kswapd0] 形式
+func (m *matcher) match(ctx context.Context, p *process.Process, name string, kernelThreads bool) bool {
+	// exporter 自己的命令行中就带有匹配规则, 不能匹配自己
+	if p.Pid == selfPID {
+		return false
+	}
+	if m.group != nil {
+		return m.group.name(ctx, p, name, kernelThreads) == m.groupName
+	}
+	for _, x := range m.excludes {
+		if x.match(ctx, p, name, true) {
+			return false
+		}
+	}
+	if m.unit != "" {
+		return inUnit(p.Pid, m.unit)
+	}
+	if m.re == nil {
+		if !sameName(m.exact, name) && m.exact != "["+name+"]" {
+			return false
+		}
+		if isKernelThread(p.Pid) {
+			return kernelThreads
+		}
+		return sameName(m.exact, name)
+	}
+	s := name
+	if m.container {
+		// 没有启用 Docker 发现 (如 list 子命令) 或不在容器中的进程不匹配
+		id := containerID(p.Pid)
+		if m.docker == nil || id == "" {
+			return false
+		}
+		var err error
+		if s, err = m.docker.containerName(ctx, id); err != nil {
+			return false
+		}
+	} else if m.cmdline {
+		// 读取失败 (如进程已经退出) 时按空命令行处理
+		s, _ = p.CmdlineWithContext(ctx)
+	}
+	// Windows 上 name: 规则也可以不写 .exe 后缀
+	if !m.re.MatchString(s) && (m.cmdline || m.container || !m.re.MatchString(trimExe(s))) {
+		return false
+	}
+	return kernelThreads || !isKernelThread(p.Pid)
+}
+
+// targetMatchers 返回每个监控目标的匹配规则, 目标配置了 match 时使用它, 否则目标名本身就是匹配规则.
+// container: 规则需要启用 Docker 发现, 即 docker 不为空. excludes 中的规则对所有目标生效
+func targetMatchers(names []string, targets map[string]TargetConfig, excludes []string, docker *dockerClient) (map[string]*matcher, error) {
+	xs, err := excludeMatchers(excludes, docker)
+	if err != nil {
+		return nil, err
+	}
+	matchers := make(map[string]*matcher, len(names))
+	for _, name := range names {
+		pattern := name
+		if t, ok := targets[name]; ok && t.Match != "" {
+			pattern = t.Match
+		}
+		// 匹配规则会用作 match 标签
+		if sanitizeName(pattern) != pattern {
+			return nil, fmt.Errorf("target %s: match %q contains control characters", name, pattern)
+		}
+		m, err := parseMatcher(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if m.container {
+			if docker == nil {
+				return nil, fmt.Errorf("target %s: match %q requires docker discovery", name, pattern)
+			}
+			m.docker = docker
+		}
+		m.excludes = xs
+		matchers[name] = m
+	}
+	return matchers, nil
+}
+
+// excludeMatchers 解析 exclude 中的规则
+func excludeMatchers(excludes []string, docker *dockerClient) ([]*matcher, error) {
+	var xs []*matcher
+	for _, pattern := range excludes {
+		x, err := parseMatcher(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("exclude: %w", err)
+		}
+		if x.container {
+			if docker == nil {
+				return nil, fmt.Errorf("exclude %q requires docker discovery", pattern)
+			}
+			x.docker = docker
+		}
+		xs = append(xs, x)
+	}
+	return xs, nil
+}
@@ -0,0 +1,30 @@
+package exporter
+
+// setMemory 导出进程的常驻内存、虚拟内存、交换到 swap 和共享内存的字节数,
+// 以及开启 smaps 时的 SwapPss, 读取失败的指标直接删除
+func (e *Exporter) setMemory(processName string, s *Stats, infoOK, sharedOK, swapPssOK bool) {
+	if infoOK {
+		e.memRSS.WithLabelValues(processName).Set(float64(s.MemoryRSS))
+		e.memVMS.WithLabelValues(processName).Set(float64(s.MemoryVMS))
+		e.memSwap.WithLabelValues(processName).Set(float64(s.MemorySwap))
+	} else {
+		e.memRSS.DeleteLabelValues(processName)
+		e.memVMS.DeleteLabelValues(processName)
+		e.memSwap.DeleteLabelValues(processName)
+	}
+	if sharedOK {
+		e.memShared.WithLabelValues(processName).Set(float64(s.MemoryShared))
+	} else {
+		e.memShared.DeleteLabelValues(processName)
+	}
+	if swapPssOK {
+		e.memSwapPss.WithLabelValues(processName).Set(float64(s.MemorySwapPss))
+	} else {
+		e.memSwapPss.DeleteLabelValues(processName)
+	}
+}
+
+// deleteMemory 删除目标的所有内存字节数指标
+func (e *Exporter) deleteMemory(processName string) {
+	e.setMemory(processName, nil, false, false, false)
+}
@@ -0,0 +1,53 @@
+package exporter
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+// ProbeHandler 返回 /probe 的 http.Handler, 实现 Prometheus 的 multi-target 模式:
+// 每次请求为 process 参数指定的目标 (进程名或 name:nginx.* 等匹配规则) 单独创建一个 Exporter 采集一次并输出,
+// 监控哪些进程由 Prometheus 的抓取配置决定. 配置文件中同名的 targets 设置和 units 等全局设置仍然生效,
+// 与单个目标无关的 groups、告警、脚本、top_n、按用户和主机的指标以及 textfile 不输出. 备用实例返回 503
+func (e *Exporter) ProbeHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !e.Active() {
+			http.Error(w, "standby instance", http.StatusServiceUnavailable)
+			return
+		}
+		target := r.URL.Query().Get("process")
+		if target == "" {
+			http.Error(w, "missing process parameter", http.StatusBadRequest)
+			return
+		}
+		e.mutex.Lock()
+		cfg := e.config
+		e.mutex.Unlock()
+		cfg.Processes = []string{target}
+		var targets []TargetConfig
+		for _, t := range cfg.Targets {
+			if t.Name == target {
+				targets = append(targets, t)
+			}
+		}
+		cfg.Targets = targets
+		// 派生指标、告警和脚本依赖所有进程的数据, 其余的与目标无关, 对单个目标都没有意义
+		cfg.Derived, cfg.Alerts, cfg.Scripts, cfg.Groups = nil, nil, nil, nil
+		cfg.TopN, cfg.ByUser, cfg.Host, cfg.TextfileDirectory = 0, false, false, ""
+
+		reg := prometheus.NewRegistry()
+		if _, err := New(Opts{
+			Config:     cfg,
+			Registerer: reg,
+			Logger:     e.logger.With("probe", target),
+
+			CollectOnScrape: true,
+		}); err != nil {
+			http.Error(w, fmt.Sprintf("invalid process %q: %s", target, err), http.StatusBadRequest)
+			return
+		}
+		promhttp.HandlerFor(reg, handlerOpts).ServeHTTP(w, r)
+	})
+}
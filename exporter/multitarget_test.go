@@ -0,0 +1,53 @@
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func probeRequest(e *Exporter, process string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	e.ProbeHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/probe?process="+process, nil))
+	return w
+}
+
+// 针对其他目标的告警和与目标无关的设置不影响 /probe
+func TestProbeIgnoresGlobalFeatures(t *testing.T) {
+	e, err := New(Opts{
+		Config: Config{
+			Processes:    []string{"mt-alerted"},
+			Alerts:       []AlertConfig{{Name: "MtDown", Target: "mt-alerted", Down: true}, {Name: "MtAnyDown", Down: true}},
+			AlertWebhook: WebhookConfig{URL: "http://127.0.0.1:1/"},
+			TopN:         3,
+			TopNSort:     TopNSortMemory,
+			ByUser:       true,
+		},
+		Registerer: prometheus.NewRegistry(),
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	w := probeRequest(e, "mt-other")
+	if w.Code != http.StatusOK {
+		t.Fatalf("probe = %d %s, want %d", w.Code, w.Body, http.StatusOK)
+	}
+	for _, name := range []string{"process_top_memory_rss_bytes", "process_user_"} {
+		if strings.Contains(w.Body.String(), name) {
+			t.Errorf("probe output contains %s", name)
+		}
+	}
+}
+
+func TestProbeStandby(t *testing.T) {
+	e, err := New(Opts{Config: Config{Processes: []string{"mt-target"}}, Registerer: prometheus.NewRegistry()})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	e.SetActive(false)
+	if w := probeRequest(e, "mt-target"); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("probe on standby = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
@@ -0,0 +1,33 @@
+package exporter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// sanitizeName 把来自进程表的名称 (进程名、命令行、用户名) 规范为可以安全用作标签值和输出到终端的字符串.
+// 进程可以随意设置自己的名称, 其中非法的 UTF-8 字节 (会导致整个抓取失败)、换行等控制字符、终端转义序列
+// 和改变显示方向的 Unicode 字符都替换为 U+FFFD
+func sanitizeName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || unicode.Is(unicode.Bidi_Control, r) {
+			return utf8.RuneError
+		}
+		return r
+	}, strings.ToValidUTF8(s, string(utf8.RuneError)))
+}
+
+// validateTargetName 检查监控目标名是否可以用作标签值, 目标名来自命令行和配置文件, 不合法时直接报错而不是静默修改
+func validateTargetName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("empty target name")
+	case !utf8.ValidString(name):
+		return fmt.Errorf("target name %q is not valid UTF-8", name)
+	case sanitizeName(name) != name:
+		return fmt.Errorf("target name %q contains control characters", name)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// hostileNames 是进程可以给自己设置的各种异常名称
+var hostileNames = []string{
+	"nginx worker",
+	"evil\nprocess_cpu_percent{process=\"sshd\"} 100",
+	"tab\there",
+	"\x1b[2J\x1b[Hclear",
+	"bad\xff\xfeutf8",
+	"quote\"back\\slash",
+	"rlo\u202egnp.exe",
+	"null\x00byte",
+	"",
+}
+
+func TestSanitizeName(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"nginx", "nginx"},
+		{"nginx worker", "nginx worker"},
+		{"java (main)", "java (main)"},
+		{"中文进程", "中文进程"},
+		{"[kworker/0:1]", "[kworker/0:1]"},
+		{"a\nb", "a\ufffdb"},
+		{"a\tb", "a\ufffdb"},
+		{"\x1b[31mred", "\ufffd[31mred"},
+		{"bad\xff\xfe", "bad\ufffd"},
+		{"rlo\u202egnp.exe", "rlo\ufffdgnp.exe"},
+		{"del\x7f", "del\ufffd"},
+		{"c1\u0085", "c1\ufffd"},
+	}
+	for _, tt := range tests {
+		if got := sanitizeName(tt.in); got != tt.want {
+			t.Errorf("sanitizeName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeNameIsSafe(t *testing.T) {
+	for _, name := range hostileNames {
+		got := sanitizeName(name)
+		if !utf8.ValidString(got) {
+			t.Errorf("sanitizeName(%q) = %q is not valid UTF-8", name, got)
+		}
+		if strings.ContainsAny(got, "\n\r\t\x00\x1b") {
+			t.Errorf("sanitizeName(%q) = %q contains control characters", name, got)
+		}
+		if sanitizeName(got) != got {
+			t.Errorf("sanitizeName(%q) is not idempotent", name)
+		}
+	}
+}
+
+func TestValidateTargetName(t *testing.T) {
+	for _, name := range []string{"nginx", "nginx worker", "[kworker/0:1]", "中文进程", "quote\"back\\slash"} {
+		if err := validateTargetName(name); err != nil {
+			t.Errorf("validateTargetName(%q) = %v, want nil", name, err)
+		}
+	}
+	for _, name := range []string{"", "a\nb", "bad\xff", "\x1b[2J", "rlo\u202e"} {
+		if err := validateTargetName(name); err == nil {
+			t.Errorf("validateTargetName(%q) = nil, want error", name)
+		}
+	}
+}
+
+func TestNewRejectsHostileTargetName(t *testing.T) {
+	_, err := New(Opts{
+		Config:     Config{Processes: []string{"ok", "bad\xff"}},
+		Registerer: prometheus.NewRegistry(),
+	})
+	if err == nil {
+		t.Fatal("New with an invalid UTF-8 target name succeeded")
+	}
+}
+
+// 规范化后的名称用作标签值时, 每个名称都对应一条完整的序列, 不会注入额外的序列
+func TestSanitizedLabelExposition(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	g := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_gauge", Help: "test"}, []string{"process"})
+	reg.MustRegister(g)
+	want := make(map[string]bool)
+	for _, name := range hostileNames {
+		v := sanitizeName(name)
+		want[v] = true
+		g.WithLabelValues(v).Set(1)
+	}
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	var buf bytes.Buffer
+	enc := expfmt.NewEncoder(&buf, expfmt.FmtText)
+	for _, mf := range mfs {
+		if err := enc.Encode(mf); err != nil {
+			t.Fatalf("Encode: %v", err)
+		}
+	}
+
+	var parser expfmt.TextParser
+	parsed, err := parser.TextToMetricFamilies(&buf)
+	if err != nil {
+		t.Fatalf("parsing exposition: %v\n%s", err, buf.String())
+	}
+	if len(parsed) != 1 {
+		t.Fatalf("got %d metric families, want 1", len(parsed))
+	}
+	got := make(map[string]bool)
+	for _, m := range parsed["test_gauge"].GetMetric() {
+		got[m.GetLabel()[0].GetValue()] = true
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d series, want %d", len(got), len(want))
+	}
+	for v := range want {
+		if !got[v] {
+			t.Errorf("series with process=%q missing after round trip", v)
+		}
+	}
+}
+
+func TestSanitizedJSON(t *testing.T) {
+	for _, name := range hostileNames {
+		in := ProcessInfo{PID: 1, Name: sanitizeName(name), Cmdline: sanitizeName(name + " --flag")}
+		data, err := json.Marshal(in)
+		if err != nil {
+			t.Fatalf("Marshal(%q): %v", name, err)
+		}
+		var out ProcessInfo
+		if err := json.Unmarshal(data, &out); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", data, err)
+		}
+		if out != in {
+			t.Errorf("JSON round trip of %q = %+v, want %+v", name, out, in)
+		}
+	}
+}
+
+// 主机上名称不是合法 UTF-8 的进程不会让 top_n 的 WithLabelValues panic
+func TestTopNHostileName(t *testing.T) {
+	cmd := startNamed(t, "bad\xff\xfe")
+	e, err := New(Opts{
+		Config:     Config{Processes: []string{"ok"}, TopN: 100000, TopNSort: TopNSortMemory},
+		Registerer: prometheus.NewRegistry(),
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	e.updateTopN(context.Background())
+
+	pid := strconv.Itoa(cmd.Process.Pid)
+	for _, labels := range labelValues(t, e.topRSS) {
+		if labels["pid"] == pid {
+			if want := sanitizeName("bad\xff\xfe"); labels["name"] != want {
+				t.Errorf("name = %q, want %q", labels["name"], want)
+			}
+			return
+		}
+	}
+	t.Skip("process not visible in the process table")
+}
+
+// labelValues 返回 c 输出的每条序列的标签
+func labelValues(t *testing.T, c prometheus.Collector) []map[string]string {
+	t.Helper()
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(c)
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	var res []map[string]string
+	for _, mf := range mfs {
+		for _, m := range mf.GetMetric() {
+			labels := make(map[string]string)
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			res = append(res, labels)
+		}
+	}
+	return res
+}
+
+// 可执行文件的路径不是合法 UTF-8 时不会让 binary_info 的 WithLabelValues panic
+func TestBinaryHostilePath(t *testing.T) {
+	e, err := New(Opts{Config: Config{Processes: []string{"ok"}}, Registerer: prometheus.NewRegistry()})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	path := "/opt/bad\xff\xfe/bin"
+	e.setBinary("ok", 1, &binaryRead{path: path, running: "abc", onDisk: "abc"})
+	series := labelValues(t, e.binaryInfo)
+	if len(series) != 1 || series[0]["path"] != sanitizeName(path) {
+		t.Fatalf("binary_info series = %v, want path %q", series, sanitizeName(path))
+	}
+}
+
+// log_files 匹配到的文件名不是合法 UTF-8 时不会让 logfile_* 的 WithLabelValues panic
+func TestLogFilesHostilePath(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bad\xff\xfe.log")
+	if err := os.WriteFile(path, []byte("line\n"), 0o644); err != nil {
+		t.Skipf("cannot create a file with an invalid UTF-8 name: %v", err)
+	}
+	e, err := New(Opts{
+		Config:     Config{Targets: []TargetConfig{{Name: "ok", LogFiles: []string{filepath.Join(dir, "*.log")}}}},
+		Registerer: prometheus.NewRegistry(),
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	e.updateLogFiles()
+	series := labelValues(t, e.logFileSize)
+	if len(series) != 1 || series[0]["path"] != sanitizeName(path) {
+		t.Fatalf("logfile_size_bytes series = %v, want path %q", series, sanitizeName(path))
+	}
+
+	os.Remove(path)
+	e.updateLogFiles()
+	if series := labelValues(t, e.logFileWritten); len(series) != 0 {
+		t.Fatalf("logfile_written_bytes_total series = %v after the file was removed, want none", series)
+	}
+}
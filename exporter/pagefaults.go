@@ -0,0 +1,28 @@
+package exporter
+
+// pageFaults 记录一个目标上一次采集时监控的进程及其缺页次数
+type pageFaults struct {
+	pid          int32
+	minor, major uint64
+
+	// 为 true 时下一次采集只记录基线, 用于从其他实例接管计数器之后
+	baseline bool
+}
+
+// updatePageFaults 把监控的进程自上次采集以来的次缺页和主缺页次数累加到
+// minor_page_faults_total 和 major_page_faults_total, 进程重启的处理与 updateCPUTime 相同
+func (e *Exporter) updatePageFaults(processName string, pid int32, minor, major uint64) {
+	var lastMinor, lastMajor uint64
+	if last, ok := e.pageFaults[processName]; ok && last.pid == pid {
+		lastMinor, lastMajor = last.minor, last.major
+	} else if ok && last.baseline {
+		lastMinor, lastMajor = minor, major
+	}
+	e.pageFaults[processName] = &pageFaults{pid: pid, minor: minor, major: major}
+	if minor >= lastMinor {
+		e.minorFaults.WithLabelValues(processName).Add(float64(minor - lastMinor))
+	}
+	if major >= lastMajor {
+		e.majorFaults.WithLabelValues(processName).Add(float64(major - lastMajor))
+	}
+}
@@ -0,0 +1,60 @@
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"strconv"
+)
+
+// threadCPU 是一个线程累计的 CPU 时间 (秒) 和它最近一次运行所在的 CPU
+type threadCPU struct {
+	cpu     int
+	seconds float64
+}
+
+// coreCPU 记录一个目标上一次采集时监控的进程各线程的 CPU 时间
+type coreCPU struct {
+	pid     int32
+	threads map[int32]threadCPU
+
+	// 为 true 时下一次采集只记录基线, 用于从其他实例接管计数器之后
+	baseline bool
+}
+
+// updateCoreCPU 把各线程自上次采集以来的 CPU 时间累加到它最近一次运行的 CPU 上.
+// 内核不记录线程在每个 CPU 上的时间, 线程在两次采集之间迁移时会计入迁移后的 CPU, 因此只是近似值;
+// 进程绑定了 CPU 时是准确的. 与 cpu_user_seconds_total 一样, 进程重启后计入新进程的全部 CPU 时间
+func (e *Exporter) updateCoreCPU(processName string, pid int32, threads map[int32]threadCPU) {
+	last, ok := e.coreCPU[processName]
+	e.coreCPU[processName] = &coreCPU{pid: pid, threads: threads}
+	if ok && last.baseline {
+		return
+	}
+	var prev map[int32]threadCPU
+	if ok && last.pid == pid {
+		prev = last.threads
+	}
+	for tid, t := range threads {
+		d := t.seconds
+		if p, seen := prev[tid]; seen {
+			d = t.seconds - p.seconds
+		}
+		if d > 0 {
+			e.coreSeconds.WithLabelValues(processName, strconv.Itoa(t.cpu)).Add(d)
+		}
+	}
+}
+
+// setAffinity 导出监控的进程允许运行的 CPU 列表, 变化时删除旧的序列
+func (e *Exporter) setAffinity(processName, cpus string) {
+	if prev, ok := e.affinity[processName]; ok && prev != cpus {
+		e.affinityInfo.DeleteLabelValues(processName, prev)
+	}
+	e.affinity[processName] = cpus
+	e.affinityInfo.WithLabelValues(processName, cpus).Set(1)
+}
+
+// deleteAffinity 删除目标的 CPU 亲和性信息
+func (e *Exporter) deleteAffinity(processName string) {
+	e.affinityInfo.DeletePartialMatch(prometheus.Labels{"process": processName})
+	delete(e.affinity, processName)
+}
@@ -0,0 +1,70 @@
+package exporter
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// readThreadCPU 读取 /proc/<pid>/task/*/stat 中每个线程的 utime、stime 和最近一次运行所在的 CPU (processor)
+func readThreadCPU(pid int32) (map[int32]threadCPU, error) {
+	dirs, err := filepath.Glob(procPath(pid, "task", "*"))
+	if err != nil {
+		return nil, err
+	}
+	if len(dirs) == 0 {
+		return nil, os.ErrNotExist
+	}
+	threads := make(map[int32]threadCPU, len(dirs))
+	for _, dir := range dirs {
+		tid, err := strconv.ParseInt(filepath.Base(dir), 10, 32)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "stat"))
+		if err != nil {
+			// 线程已经退出
+			continue
+		}
+		// utime、stime 和 processor 是第 14、15 和 39 个字段
+		i := bytes.LastIndexByte(data, ')')
+		if i < 0 {
+			continue
+		}
+		fields := bytes.Fields(data[i+1:])
+		if len(fields) < 37 {
+			continue
+		}
+		utime, _ := strconv.ParseUint(string(fields[11]), 10, 64)
+		stime, _ := strconv.ParseUint(string(fields[12]), 10, 64)
+		cpu, err := strconv.Atoi(string(fields[36]))
+		if err != nil {
+			continue
+		}
+		threads[int32(tid)] = threadCPU{cpu: cpu, seconds: float64(utime+stime) / userHZ}
+	}
+	return threads, nil
+}
+
+// readAffinity 返回 /proc/<pid>/status 中的 Cpus_allowed_list, 即进程允许运行的 CPU, 如 0-3,8
+func readAffinity(pid int32) (string, error) {
+	f, err := os.Open(procPath(pid, "status"))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if v, ok := strings.CutPrefix(sc.Text(), "Cpus_allowed_list:"); ok {
+			return strings.TrimSpace(v), nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return "", err
+	}
+	return "", errors.New("status has no Cpus_allowed_list")
+}
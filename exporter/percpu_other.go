@@ -0,0 +1,17 @@
+//go:build !linux
+
+package exporter
+
+import (
+	"errors"
+)
+
+// readThreadCPU 只在 Linux 上支持
+func readThreadCPU(pid int32) (map[int32]threadCPU, error) {
+	return nil, errors.New("per-CPU usage is only available on Linux")
+}
+
+// readAffinity 只在 Linux 上支持
+func readAffinity(pid int32) (string, error) {
+	return "", errors.New("CPU affinity is only available on Linux")
+}
@@ -0,0 +1,29 @@
+package exporter
+
+import (
+	"context"
+	"github.com/shirou/gopsutil/process"
+	"time"
+)
+
+// defaultPIDRescanInterval 是两次完整扫描进程表之间的默认最长间隔
+const defaultPIDRescanInterval = 30 * time.Second
+
+// cachedPIDs 检查上一周期匹配到的 PID 是否仍然存在且仍然匹配, 全部有效时可以跳过扫描进程表.
+// 没有缓存 (如目标的进程还没有启动) 时返回 false
+func cachedPIDs(ctx context.Context, j readJob) bool {
+	if len(j.cached) == 0 {
+		return false
+	}
+	for _, pid := range j.cached {
+		p, err := process.NewProcessWithContext(ctx, pid)
+		if err != nil {
+			return false
+		}
+		name, err := p.NameWithContext(ctx)
+		if err != nil || !j.m.match(ctx, p, name, j.kernelThreads) {
+			return false
+		}
+	}
+	return true
+}
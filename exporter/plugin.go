@@ -0,0 +1,25 @@
+//go:build !noplugin
+
+package exporter
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// LoadPlugin 打开 path 指向的 Go plugin, 并用其导出的 NewCollector 创建采集器
+func LoadPlugin(path string) (Collector, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening plugin %s: %w", path, err)
+	}
+	sym, err := p.Lookup(PluginSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", path, err)
+	}
+	factory, ok := sym.(func() (Collector, error))
+	if !ok {
+		return nil, fmt.Errorf("plugin %s: %s has type %T, want func() (exporter.Collector, error)", path, PluginSymbol, sym)
+	}
+	return factory()
+}
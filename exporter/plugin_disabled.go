@@ -0,0 +1,10 @@
+//go:build noplugin
+
+package exporter
+
+import "fmt"
+
+// LoadPlugin 在使用 noplugin 标签构建时总是返回错误
+func LoadPlugin(path string) (Collector, error) {
+	return nil, fmt.Errorf("plugin %s: built without plugin support (noplugin)", path)
+}
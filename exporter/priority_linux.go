@@ -0,0 +1,49 @@
+package exporter
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// readPriority 返回 /proc/<pid>/stat 中进程的调度优先级和 nice 值 (第 18、19 个字段).
+// 普通进程的优先级为 20 + nice, 实时进程为负数
+func readPriority(pid int32) (priority, nice int64, err error) {
+	fields, err := statFields(pid)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(fields) < 17 {
+		return 0, 0, errors.New("stat has no priority")
+	}
+	if priority, err = strconv.ParseInt(string(fields[15]), 10, 64); err != nil {
+		return 0, 0, err
+	}
+	if nice, err = strconv.ParseInt(string(fields[16]), 10, 64); err != nil {
+		return 0, 0, err
+	}
+	return priority, nice, nil
+}
+
+// readOOMScore 返回 /proc/<pid>/oom_score 和 oom_score_adj, 内存不足时 OOM killer 优先杀死分数最高的进程
+func readOOMScore(pid int32) (score, adj int64, err error) {
+	dir := procPath(pid)
+	if score, err = readInt(filepath.Join(dir, "oom_score")); err != nil {
+		return 0, 0, err
+	}
+	if adj, err = readInt(filepath.Join(dir, "oom_score_adj")); err != nil {
+		return 0, 0, err
+	}
+	return score, adj, nil
+}
+
+// readInt 读取只包含一个整数的文件
+func readInt(name string) (int64, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(bytes.TrimSpace(data)), 10, 64)
+}
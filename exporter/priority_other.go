@@ -0,0 +1,17 @@
+//go:build !linux
+
+package exporter
+
+import (
+	"errors"
+)
+
+// readPriority 只在 Linux 上支持
+func readPriority(pid int32) (priority, nice int64, err error) {
+	return 0, 0, errors.New("priority is only available on Linux")
+}
+
+// readOOMScore 只在 Linux 上支持
+func readOOMScore(pid int32) (score, adj int64, err error) {
+	return 0, 0, errors.New("oom score is only available on Linux")
+}
@@ -0,0 +1,156 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// defaultProbeTimeout 是健康检查默认的超时时间, 应小于采集周期
+const defaultProbeTimeout = 3 * time.Second
+
+// probe 是一个目标的健康检查
+type probe struct {
+	target  string
+	kind    string
+	cfg     ProbeConfig
+	command string
+	timeout time.Duration
+	client  *http.Client
+}
+
+func newProbe(target string, cfg ProbeConfig) (*probe, error) {
+	p := &probe{target: target, cfg: cfg, timeout: cfg.Timeout}
+	if p.timeout <= 0 {
+		p.timeout = defaultProbeTimeout
+	}
+	switch {
+	case cfg.TCP != "" && cfg.HTTP != "":
+		return nil, fmt.Errorf("probe for %s: only one of tcp and http may be set", target)
+	case cfg.TCP != "":
+		p.kind = "tcp"
+	case cfg.HTTP != "":
+		p.kind = "http"
+		p.client = &http.Client{Timeout: p.timeout}
+	default:
+		return nil, fmt.Errorf("probe for %s: one of tcp and http is required", target)
+	}
+	return p, nil
+}
+
+// newCommandProbe 创建执行 check_command 的健康检查
+func newCommandProbe(target, command string, timeout time.Duration) *probe {
+	if timeout <= 0 {
+		timeout = defaultProbeTimeout
+	}
+	return &probe{target: target, kind: "command", command: command, timeout: timeout}
+}
+
+// run 执行一次健康检查, 返回耗时和失败原因
+func (p *probe) run(ctx context.Context) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	start := time.Now()
+	if p.kind == "command" {
+		shell, flag := "/bin/sh", "-c"
+		if runtime.GOOS == "windows" {
+			shell, flag = "cmd", "/C"
+		}
+		err := exec.CommandContext(ctx, shell, flag, p.command).Run()
+		return time.Since(start), err
+	}
+	if p.kind == "tcp" {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", p.cfg.TCP)
+		if err != nil {
+			return time.Since(start), err
+		}
+		conn.Close()
+		return time.Since(start), nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, string(p.cfg.HTTP), nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return time.Since(start), err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	resp.Body.Close()
+	d := time.Since(start)
+	if p.cfg.ExpectedStatus != 0 && resp.StatusCode != p.cfg.ExpectedStatus {
+		return d, fmt.Errorf("got status %d, want %d", resp.StatusCode, p.cfg.ExpectedStatus)
+	}
+	if p.cfg.ExpectedStatus == 0 && (resp.StatusCode < 200 || resp.StatusCode > 299) {
+		return d, fmt.Errorf("got status %d, want 2xx", resp.StatusCode)
+	}
+	return d, nil
+}
+
+// newProbes 创建 targets 中配置的所有健康检查和检查命令
+func newProbes(targets []TargetConfig) ([]*probe, error) {
+	var probes []*probe
+	for _, t := range targets {
+		if t.Probe != nil {
+			p, err := newProbe(t.Name, *t.Probe)
+			if err != nil {
+				return nil, err
+			}
+			probes = append(probes, p)
+		}
+		if t.CheckCommand != "" {
+			probes = append(probes, newCommandProbe(t.Name, string(t.CheckCommand), t.CheckTimeout))
+		}
+	}
+	return probes, nil
+}
+
+// runProbes 并发执行所有健康检查, 不持有 mutex, 避免慢的检查阻塞抓取和其他接口
+func (e *Exporter) runProbes(ctx context.Context, probes []*probe) {
+	if len(probes) == 0 {
+		return
+	}
+	ctx, span := e.tracer.Start(ctx, "probes")
+	defer span.End()
+
+	var wg sync.WaitGroup
+	for _, p := range probes {
+		wg.Add(1)
+		go func(p *probe) {
+			defer wg.Done()
+			d, err := p.run(ctx)
+			up := 1.0
+			if err != nil {
+				up = 0
+				e.logger.Warn(T("log.probe_failed"), "target", p.target, "type", p.kind, "error", err, "duration", d)
+			}
+			e.probeUp.WithLabelValues(p.target, p.kind).Set(up)
+			if p.kind == "command" {
+				e.checkExitCode.WithLabelValues(p.target).Set(float64(exitCode(err)))
+			}
+			e.probeDuration.WithLabelValues(p.target, p.kind).Set(d.Seconds())
+		}(p)
+	}
+	wg.Wait()
+}
+
+// exitCode 返回命令的退出码, 命令无法启动或超时被杀死时为 -1
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
@@ -0,0 +1,36 @@
+package exporter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// procRoot 是读取进程信息的 procfs, 在容器中运行时可以通过 SetProcfs 指向挂载进来的主机的 /proc
+var procRoot = "/proc"
+
+// SetProcfs 让 exporter (包括 gopsutil) 从 path 读取进程信息, 需要在 New 之前调用.
+// path 属于其他 PID namespace (如容器中挂载的主机 /proc) 时, 其中的 PID 都是该 namespace 中的 PID,
+// 因此 exporter 自己的 PID 也改为从 path/self 读取, 以便继续跳过自己
+func SetProcfs(path string) error {
+	if _, err := os.Stat(filepath.Join(path, "stat")); err != nil {
+		return fmt.Errorf("procfs %s: %w", path, err)
+	}
+	procRoot = path
+	// gopsutil 每次读取时从 HOST_PROC 取得 procfs 的路径
+	if err := os.Setenv("HOST_PROC", path); err != nil {
+		return err
+	}
+	if self, err := os.Readlink(filepath.Join(path, "self")); err == nil {
+		if pid, err := strconv.Atoi(self); err == nil {
+			selfPID = int32(pid)
+		}
+	}
+	return nil
+}
+
+// procPath 返回 procfs 中进程 pid 的文件路径, 如 procPath(pid, "stat")
+func procPath(pid int32, elem ...string) string {
+	return filepath.Join(append([]string{procRoot, strconv.Itoa(int(pid))}, elem...)...)
+}
@@ -0,0 +1,253 @@
+package exporter
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"net/http"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// Reload 应用新的配置而不重启: 开始采集新增的目标, 删除已移除目标的所有序列,
+// 更新目标的匹配规则、聚合方式、采集间隔、静态标签的取值、健康检查、日志跟踪和 groups 规则. 其他需要重启才能生效的设置发生变化时记录警告日志.
+// 新配置不合法时返回错误, 继续使用原来的配置
+func (e *Exporter) Reload(cfg Config) error {
+	names := cfg.TargetNames()
+	for _, name := range names {
+		if err := validateTargetName(name); err != nil {
+			return err
+		}
+	}
+	targets := targetConfigs(cfg.Targets)
+	infoLabelNames, err := targetLabelNames(targets, cfg.Groups)
+	if err != nil {
+		return err
+	}
+	for _, t := range targets {
+		if err := validateAggregation(t); err != nil {
+			return err
+		}
+		if err := validateLogFiles(t); err != nil {
+			return err
+		}
+	}
+	if err := validateTopN(cfg); err != nil {
+		return err
+	}
+	matchers, err := targetMatchers(names, targets, cfg.Exclude, e.docker)
+	if err != nil {
+		return err
+	}
+	probes, err := newProbes(cfg.Targets)
+	if err != nil {
+		return err
+	}
+	nameGroups, err := newNameGroups(cfg.Groups, cfg.Exclude, e.docker)
+	if err != nil {
+		return err
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	// process_target_info 的标签名在创建时确定
+	if !slices.Equal(infoLabelNames, e.infoLabelNames) {
+		return fmt.Errorf("changing the static label names of targets requires a restart")
+	}
+	watches, err := e.newLogWatches(cfg.Targets, e.logWatches)
+	if err != nil {
+		return err
+	}
+	if changed := restartRequired(e.config, cfg); len(changed) > 0 {
+		e.logger.Warn(T("log.reload_restart"), "settings", changed)
+	}
+
+	keep := make(map[string]bool, len(names))
+	for _, name := range names {
+		keep[name] = true
+	}
+	// groups 规则和 exclude 不变时保留生成的目标
+	groupsChanged := !reflect.DeepEqual(e.config.Groups, cfg.Groups) || !slices.Equal(e.config.Exclude, cfg.Exclude)
+	for _, name := range e.processNames {
+		if !keep[name] && (groupsChanged || e.generated[name] == nil) {
+			e.removeTarget(name)
+		}
+	}
+	// 不再配置的健康检查和日志跟踪的序列也一并删除
+	kinds := make(map[[2]string]bool)
+	for _, p := range probes {
+		kinds[[2]string{p.target, p.kind}] = true
+	}
+	for _, p := range e.probes {
+		if !kinds[[2]string{p.target, p.kind}] {
+			e.probeUp.DeleteLabelValues(p.target, p.kind)
+			e.probeDuration.DeleteLabelValues(p.target, p.kind)
+			if p.kind == "command" {
+				e.checkExitCode.DeleteLabelValues(p.target)
+			}
+		}
+	}
+	kept := make(map[*logWatch]bool, len(watches))
+	for _, w := range watches {
+		kept[w] = true
+	}
+	for _, w := range e.logWatches {
+		if !kept[w] {
+			e.logLines.DeletePartialMatch(prometheus.Labels{"process": w.target, "path": w.path})
+		}
+	}
+
+	// 匹配规则或静态标签的取值变化后删除旧的序列, 下个周期立即以新的取值重新创建
+	for _, name := range names {
+		old, ok := e.matchers[name]
+		if ok && (old.pattern != matchers[name].pattern || !reflect.DeepEqual(targets[name].Labels, e.targets[name].Labels) ||
+			!reflect.DeepEqual(targets[name].EnvLabels, e.targets[name].EnvLabels) ||
+			!reflect.DeepEqual(targets[name].CmdlineLabels, e.targets[name].CmdlineLabels)) {
+			e.targetInfo.DeletePartialMatch(prometheus.Labels{"process": name})
+			delete(e.lastUpdate, name)
+		}
+	}
+
+	e.config = cfg
+	e.processNames = names
+	// 不再按 pid 导出的目标删除其序列
+	for name := range e.perPID {
+		if e.generated[name] == nil && targets[name].Aggregation != AggregatePerPID {
+			e.deletePerPID(name)
+		}
+	}
+	e.targets = targets
+	e.matchers = matchers
+	generated := e.generated
+	e.generated = make(map[string]*nameGroup)
+	if groupsChanged {
+		e.nameGroups = nameGroups
+		e.groupsAt = time.Time{}
+	} else {
+		for name, g := range generated {
+			// 同名的目标加入了配置时改为使用配置
+			if !keep[name] {
+				e.addGenerated(name, g)
+			}
+		}
+	}
+	// 匹配规则和 exclude 可能变化, 下个周期重新扫描进程表
+	for _, st := range e.state {
+		st.scannedAt = time.Time{}
+	}
+	e.probes = probes
+	e.logWatches = watches
+	e.logger.Info(T("log.reloaded"), "targets", len(names))
+	return nil
+}
+
+// removeTarget 删除目标的所有序列和内部状态, 调用方需持有 mutex.
+// journal 不随 Reload 变化, 其序列保留到重启
+func (e *Exporter) removeTarget(name string) {
+	e.deleteTarget(name)
+	delete(e.state, name)
+}
+
+// deleteTarget 删除目标的所有序列和除 state 以外的内部状态, 调用方需持有 mutex
+func (e *Exporter) deleteTarget(name string) {
+	labels := prometheus.Labels{"process": name}
+	for _, vec := range []interface {
+		DeletePartialMatch(prometheus.Labels) int
+	}{
+		e.cpuUsage, e.memUsage, e.pidUsage, e.up, e.targetInfo, e.cpuWait, e.cpuSaturation, e.memGrowth, e.openFiles,
+		e.cpuUser, e.cpuSystem, e.coreSeconds, e.affinityInfo, e.ioReadBytes, e.ioWriteBytes, e.ioReads, e.ioWrites, e.ioWait, e.openFDs, e.maxFDs,
+		e.numThreads, e.voluntaryCtx, e.involuntaryCtx, e.minorFaults, e.majorFaults, e.connections, e.listenPorts, e.states, e.priority, e.nice, e.oomScore, e.oomScoreAdj,
+		e.containerInfo, e.podInfo, e.binaryInfo, e.binaryChanges, e.cgroupMemLimit, e.cgroupMemRatio, e.cgroupCPUQuota, e.cgroupCPURatio,
+		e.gpuMemory, e.gpuUtil, e.cpuHist, e.rssHist,
+		e.groupCPUSeconds, e.groupIORead, e.groupIOWrite, e.probeUp, e.probeDuration, e.checkExitCode, e.logLines, e.logFileSize, e.logFileWritten,
+		e.starts, e.exits, e.startTime, e.restarts, e.childrenSpawned, e.self.denials, e.self.targetTimeouts,
+		e.self.collectDuration, e.self.collectErrors,
+	} {
+		vec.DeletePartialMatch(labels)
+	}
+	for _, sc := range e.scripts {
+		sc.gauge.DeletePartialMatch(labels)
+	}
+	e.deletePerPID(name)
+	e.deleteMemory(name)
+	delete(e.lastUpdate, name)
+	delete(e.delays, name)
+	delete(e.stats, name)
+	delete(e.prevStats, name)
+	delete(e.growth, name)
+	delete(e.groupCPU, name)
+	delete(e.cpuTime, name)
+	delete(e.coreCPU, name)
+	delete(e.affinity, name)
+	delete(e.ioCount, name)
+	delete(e.ctxSwitches, name)
+	delete(e.pageFaults, name)
+	delete(e.cgroupCPU, name)
+	delete(e.gpus, name)
+	delete(e.dynamicLabels, name)
+	delete(e.containers, name)
+	delete(e.pods, name)
+	delete(e.binaries, name)
+	delete(e.listening, name)
+	delete(e.children, name)
+	delete(e.sched, name)
+	delete(e.ioDelay, name)
+	delete(e.logFiles, name)
+}
+
+// restartRequired 返回 old 和 cfg 之间只有重启才能生效的设置中发生了变化的部分
+func restartRequired(old, cfg Config) []string {
+	var changed []string
+	for name, eq := range map[string]bool{
+		"locale":             old.Locale == cfg.Locale,
+		"units":              old.Units == cfg.Units,
+		"namespace":          old.Namespace == cfg.Namespace,
+		"legacy_names":       old.LegacyNames == cfg.LegacyNames,
+		"history_retention":  old.HistoryRetention == cfg.HistoryRetention,
+		"docker":             old.Docker == cfg.Docker && old.DockerSocket == cfg.DockerSocket,
+		"kubelet":            old.KubeletURL == cfg.KubeletURL && old.KubeletInsecure == cfg.KubeletInsecure,
+		"collect_interval":   old.CollectInterval == cfg.CollectInterval,
+		"sample_interval":    old.SampleInterval == cfg.SampleInterval,
+		"host":               old.Host == cfg.Host,
+		"textfile_directory": old.TextfileDirectory == cfg.TextfileDirectory,
+		"alerts":             reflect.DeepEqual(old.Alerts, cfg.Alerts) && old.AlertWebhook == cfg.AlertWebhook,
+		"scrape_timeout":     old.ScrapeTimeout == cfg.ScrapeTimeout,
+		"cache_ttl":          old.CacheTTL == cfg.CacheTTL,
+		"scripts":            reflect.DeepEqual(old.Scripts, cfg.Scripts),
+		"derived":            reflect.DeepEqual(old.Derived, cfg.Derived),
+		"journal":            reflect.DeepEqual(journalConfigs(old.Targets), journalConfigs(cfg.Targets)),
+	} {
+		if !eq {
+			changed = append(changed, name)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
+
+func journalConfigs(targets []TargetConfig) map[string]JournalConfig {
+	m := make(map[string]JournalConfig)
+	for _, t := range targets {
+		if t.Journal != nil {
+			m[t.Name] = *t.Journal
+		}
+	}
+	return m
+}
+
+// ReloadHandler 返回 /-/reload 的 http.Handler, 收到 POST 请求时调用 reload 重新加载配置
+func ReloadHandler(reload func() error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := reload(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintln(w, "OK")
+	})
+}
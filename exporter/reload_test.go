@@ -0,0 +1,47 @@
+package exporter
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"slices"
+	"testing"
+	"time"
+)
+
+// Reload 开始采集新增的目标并删除已移除目标的序列
+func TestReload(t *testing.T) {
+	startNamed(t, "rl-old")
+	startNamed(t, "rl-new")
+	clock := NewFakeClock(time.Unix(1700000000, 0))
+	e := newClockExporter(t, clock, Config{Processes: []string{"rl-old"}})
+	ctx := context.Background()
+
+	e.Update(ctx)
+	if got := testutil.ToFloat64(e.up.WithLabelValues("rl-old")); got != 1 {
+		t.Fatalf("up{rl-old} = %v, want 1", got)
+	}
+	if err := e.Reload(Config{Processes: []string{"rl-new"}}); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	clock.Advance(CollectInterval)
+	e.Update(ctx)
+	series := labelValues(t, e.up)
+	if len(series) != 1 || series[0]["process"] != "rl-new" {
+		t.Fatalf("up series after Reload = %v, want only rl-new", series)
+	}
+	if got := testutil.ToFloat64(e.up.WithLabelValues("rl-new")); got != 1 {
+		t.Fatalf("up{rl-new} = %v, want 1", got)
+	}
+}
+
+// 新配置不合法时 Reload 返回错误并继续使用原来的配置
+func TestReloadInvalid(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1700000000, 0))
+	e := newClockExporter(t, clock, Config{Processes: []string{"rl-keep"}})
+	if err := e.Reload(Config{Targets: []TargetConfig{{Name: "rl-bad", Aggregation: "median"}}}); err == nil {
+		t.Fatal("Reload with an unknown aggregation succeeded")
+	}
+	if !slices.Equal(e.processNames, []string{"rl-keep"}) {
+		t.Fatalf("targets = %v after a failed Reload, want [rl-keep]", e.processNames)
+	}
+}
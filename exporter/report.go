@@ -0,0 +1,100 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// ANSI 颜色
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorBold   = "\033[1m"
+)
+
+// highCPUPercent 以上的 CPU 使用率会以黄色显示
+const highCPUPercent = 80
+
+// ColorEnabled 判断 f 是否是终端且没有设置 NO_COLOR
+func ColorEnabled(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// WriteReport 以对齐的表格输出每个监控目标的概要, color 为 true 时用颜色标出未运行和高 CPU 的目标
+func WriteReport(w io.Writer, stats []Stats, color bool) {
+	rows := [][]string{strings.Split(T("report.header"), "\t")}
+	colors := []string{colorBold}
+	up := 0
+	for _, s := range stats {
+		if s.PID == 0 {
+			rows = append(rows, []string{s.Process, "-", "-", "-", "-", "-", T("report.down")})
+			colors = append(colors, colorRed)
+			continue
+		}
+		up++
+		rows = append(rows, []string{
+			s.Process,
+			fmt.Sprint(s.PID),
+			fmt.Sprintf("%.1f", s.CPUPercent),
+			fmt.Sprintf("%.1f", s.MemoryPercent),
+			fmt.Sprintf("%.1f", float64(s.MemoryRSS)/(1<<20)),
+			fmt.Sprint(s.NumFDs),
+			s.Status,
+		})
+		if s.CPUPercent >= highCPUPercent {
+			colors = append(colors, colorYellow)
+		} else {
+			colors = append(colors, colorGreen)
+		}
+	}
+
+	// 按显示宽度对齐, 颜色控制符不计入宽度
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			if n := displayWidth(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	for r, row := range rows {
+		var b strings.Builder
+		for i, cell := range row {
+			pad := strings.Repeat(" ", widths[i]-displayWidth(cell))
+			if i == 0 {
+				b.WriteString(cell + pad)
+			} else {
+				// 数值列右对齐
+				b.WriteString("  " + pad + cell)
+			}
+		}
+		line := b.String()
+		if color {
+			line = colors[r] + line + colorReset
+		}
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintln(w, T("report.summary", up, len(stats)))
+}
+
+// displayWidth 返回 s 在终端中占用的列数, 中日韩文字和全角字符占两列
+func displayWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) || (r >= 0xff01 && r <= 0xff60) {
+			n += 2
+			continue
+		}
+		n++
+	}
+	return n
+}
@@ -0,0 +1,105 @@
+package exporter
+
+import (
+	"context"
+	"github.com/shirou/gopsutil/process"
+	"sort"
+)
+
+// Resolution 是一个监控目标在当前进程表中的匹配结果
+type Resolution struct {
+	Target string
+	Match  string
+	// 按进程表顺序排列的所有匹配的 PID, 第一个即实际监控的进程
+	PIDs []int32
+}
+
+// Resolve 用当前进程表解析 cfg 中的所有监控目标, 结果按目标名排序, 不会创建或修改任何指标
+func Resolve(ctx context.Context, cfg Config) ([]Resolution, error) {
+	processes, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int32]string, len(processes))
+	for _, p := range processes {
+		name, err := p.NameWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		names[p.Pid] = name
+	}
+
+	targets := cfg.TargetNames()
+	var docker *dockerClient
+	if cfg.Docker {
+		if docker, err = newDockerClient(cfg.DockerSocket); err != nil {
+			return nil, err
+		}
+	}
+	matchers, err := targetMatchers(targets, targetConfigs(cfg.Targets), cfg.Exclude, docker)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]Resolution, 0, len(targets))
+	for _, target := range targets {
+		m := matchers[target]
+		r := Resolution{Target: target, Match: m.pattern}
+		for _, p := range processes {
+			if name, ok := names[p.Pid]; ok && m.match(ctx, p, name, cfg.KernelThreads) {
+				r.PIDs = append(r.PIDs, p.Pid)
+			}
+		}
+		if m.unit != "" {
+			r.PIDs = mainPIDFirst(ctx, m.unit, r.PIDs)
+		}
+		res = append(res, r)
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Target < res[j].Target })
+	return res, nil
+}
+
+// ProcessInfo 是进程表中的一个进程
+type ProcessInfo struct {
+	PID     int32
+	Name    string
+	User    string
+	Cmdline string
+}
+
+// ListProcesses 按 PID 顺序返回进程表中的所有进程, target 不为空时只返回匹配该监控目标的进程.
+// 内核线程也会列出, 它们没有命令行, Cmdline 与 ps 一样显示为 [name].
+// 返回的名称和命令行经过 sanitizeName 处理, 可以直接输出到终端
+func ListProcesses(ctx context.Context, target string) ([]ProcessInfo, error) {
+	var m *matcher
+	if target != "" {
+		var err error
+		if m, err = parseMatcher(target); err != nil {
+			return nil, err
+		}
+	}
+	processes, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]ProcessInfo, 0, len(processes))
+	for _, p := range processes {
+		name, err := p.NameWithContext(ctx)
+		if err != nil {
+			// 进程可能已经退出
+			continue
+		}
+		if m != nil && !m.match(ctx, p, name, true) {
+			continue
+		}
+		info := ProcessInfo{PID: p.Pid, Name: name}
+		info.User, _ = p.UsernameWithContext(ctx)
+		info.Cmdline, _ = p.CmdlineWithContext(ctx)
+		if info.Cmdline == "" && isKernelThread(p.Pid) {
+			info.Cmdline = "[" + name + "]"
+		}
+		info.Name, info.User, info.Cmdline = sanitizeName(info.Name), sanitizeName(info.User), sanitizeName(info.Cmdline)
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].PID < infos[j].PID })
+	return infos, nil
+}
@@ -0,0 +1,72 @@
+package exporter
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shirou/gopsutil/process"
+	"time"
+)
+
+// 采样直方图的桶: CPU 使用率 (多核时可以超过 100) 和常驻内存 (1MiB 到 256GiB)
+var (
+	sampleCPUBuckets = []float64{1, 5, 10, 25, 50, 75, 100, 150, 200, 400, 800}
+	sampleRSSBuckets = prometheus.ExponentialBuckets(1<<20, 4, 10)
+)
+
+// cpuSample 是上一次采样时监控的进程的累计 CPU 时间
+type cpuSample struct {
+	pid     int32
+	seconds float64
+	at      time.Time
+}
+
+// runSampler 每隔 sample_interval 对每个目标监控的进程采样一次 CPU 使用率和常驻内存并计入直方图,
+// 用于发现两次抓取之间短暂的尖峰. 它使用上一周期找到的 PID, 采样时不持有互斥锁
+func (e *Exporter) runSampler(ctx context.Context) {
+	last := make(map[string]cpuSample)
+	t := e.clock.NewTicker(e.sampleInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C():
+		}
+		if !e.active.Load() {
+			continue
+		}
+		e.mutex.Lock()
+		pids := make(map[string]int32, len(e.processNames))
+		for _, name := range e.processNames {
+			if st, ok := e.state[name]; ok && len(st.pids) > 0 {
+				pids[name] = st.pids[0]
+			}
+		}
+		e.mutex.Unlock()
+		for name := range last {
+			if _, ok := pids[name]; !ok {
+				delete(last, name)
+			}
+		}
+		for name, pid := range pids {
+			p, err := process.NewProcessWithContext(ctx, pid)
+			if err != nil {
+				continue
+			}
+			if mem, err := p.MemoryInfoWithContext(ctx); err == nil {
+				e.rssHist.WithLabelValues(name).Observe(float64(mem.RSS))
+			}
+			times, err := p.TimesWithContext(ctx)
+			if err != nil {
+				continue
+			}
+			now := e.clock.Now()
+			cur := cpuSample{pid: pid, seconds: times.User + times.System, at: now}
+			// 第一次采样和进程变化后只记录基线
+			if prev, ok := last[name]; ok && prev.pid == pid && now.After(prev.at) {
+				e.cpuHist.WithLabelValues(name).Observe(max(cur.seconds-prev.seconds, 0) / now.Sub(prev.at).Seconds() * 100)
+			}
+			last[name] = cur
+		}
+	}
+}
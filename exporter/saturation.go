@@ -0,0 +1,54 @@
+package exporter
+
+// ioDelaySample 是上一次读取的等待块设备 I/O 的累计时间
+type ioDelaySample struct {
+	pid     int32
+	seconds float64
+}
+
+// schedSample 是上一次读取的调度统计
+type schedSample struct {
+	pid       int32
+	run, wait uint64
+}
+
+// updateSaturation 根据两次采集之间线程在运行队列中等待的时间与运行时间之比导出 CPU 饱和度:
+// CPU 使用率低而饱和度高说明进程是因为抢不到 CPU 而变慢, 两者都低说明进程只是空闲
+func (e *Exporter) updateSaturation(processName string, pid int32) {
+	run, wait, err := readSchedstat(pid)
+	if err != nil {
+		e.cpuSaturation.DeleteLabelValues(processName)
+		return
+	}
+	prev, ok := e.sched[processName]
+	e.sched[processName] = schedSample{pid: pid, run: run, wait: wait}
+	// 第一次读取时只创建序列
+	e.cpuWait.WithLabelValues(processName)
+	if !ok || prev.pid != pid || run < prev.run || wait < prev.wait {
+		return
+	}
+	dRun, dWait := run-prev.run, wait-prev.wait
+	e.cpuWait.WithLabelValues(processName).Add(float64(dWait) / 1e9)
+	if dRun+dWait == 0 {
+		// 这段时间内没有运行过, 也没有在等待
+		e.cpuSaturation.WithLabelValues(processName).Set(0)
+		return
+	}
+	e.cpuSaturation.WithLabelValues(processName).Set(float64(dWait) / float64(dRun+dWait))
+}
+
+// updateIOWait 累加两次采集之间进程等待块设备 I/O 的时间: 与 process_cpu_wait_seconds_total 一起
+// 可以区分进程变慢是因为抢不到 CPU 还是阻塞在磁盘上
+func (e *Exporter) updateIOWait(processName string, pid int32) {
+	seconds, err := readBlkioDelay(pid)
+	if err != nil {
+		return
+	}
+	prev, ok := e.ioDelay[processName]
+	e.ioDelay[processName] = ioDelaySample{pid: pid, seconds: seconds}
+	// 第一次读取时只创建序列
+	e.ioWait.WithLabelValues(processName)
+	if ok && prev.pid == pid && seconds > prev.seconds {
+		e.ioWait.WithLabelValues(processName).Add(seconds - prev.seconds)
+	}
+}
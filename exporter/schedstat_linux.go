@@ -0,0 +1,71 @@
+package exporter
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// readSchedstat 累加 /proc/<pid>/task/*/schedstat 中所有线程在 CPU 上运行和在运行队列中等待的时间 (纳秒)
+func readSchedstat(pid int32) (run, wait uint64, err error) {
+	files, err := filepath.Glob(procPath(pid, "task", "*", "schedstat"))
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(files) == 0 {
+		return 0, 0, os.ErrNotExist
+	}
+	for _, name := range files {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			// 线程已经退出
+			continue
+		}
+		fields := bytes.Fields(data)
+		if len(fields) < 2 {
+			continue
+		}
+		r, _ := strconv.ParseUint(string(fields[0]), 10, 64)
+		w, _ := strconv.ParseUint(string(fields[1]), 10, 64)
+		run += r
+		wait += w
+	}
+	return run, wait, nil
+}
+
+// userHZ 是 /proc/<pid>/stat 中时间的单位, 在 Linux 上固定为 100
+const userHZ = 100
+
+// readBlkioDelay 返回进程的线程等待块设备 I/O 完成的累计时间 (秒), 来自 /proc/<pid>/stat 的
+// delayacct_blkio_ticks. 需要内核开启 delay accounting (sysctl kernel.task_delayacct=1 或启动参数 delayacct), 否则始终为 0
+func readBlkioDelay(pid int32) (float64, error) {
+	fields, err := statFields(pid)
+	if err != nil {
+		return 0, err
+	}
+	// delayacct_blkio_ticks 是第 42 个字段
+	if len(fields) < 40 {
+		return 0, errors.New("stat has no delayacct_blkio_ticks")
+	}
+	ticks, err := strconv.ParseUint(string(fields[39]), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return float64(ticks) / userHZ, nil
+}
+
+// statFields 返回 /proc/<pid>/stat 中进程名之后的字段, 第一个为第 3 个字段 state.
+// 进程名可能包含空格和括号, 因此从最后一个 ')' 之后开始解析
+func statFields(pid int32) ([][]byte, error) {
+	data, err := os.ReadFile(procPath(pid, "stat"))
+	if err != nil {
+		return nil, err
+	}
+	i := bytes.LastIndexByte(data, ')')
+	if i < 0 {
+		return nil, errors.New("malformed stat")
+	}
+	return bytes.Fields(data[i+1:]), nil
+}
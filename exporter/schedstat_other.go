@@ -0,0 +1,17 @@
+//go:build !linux
+
+package exporter
+
+import (
+	"errors"
+)
+
+// readSchedstat 只在 Linux 上支持
+func readSchedstat(pid int32) (run, wait uint64, err error) {
+	return 0, 0, errors.New("schedstat is only available on Linux")
+}
+
+// readBlkioDelay 只在 Linux 上支持
+func readBlkioDelay(pid int32) (float64, error) {
+	return 0, errors.New("delay accounting is only available on Linux")
+}
@@ -0,0 +1,30 @@
+package exporter
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ProcessCollector 在每次抓取时采集所有目标后输出内置的指标, 使用 Opts.CollectOnScrape 时由 New 注册.
+// 这样指标总是最新的, 采集频率由 Prometheus 的抓取间隔决定; 并发的抓取依次采集
+type ProcessCollector struct {
+	e          *Exporter
+	collectors []prometheus.Collector
+}
+
+// Describe 实现 prometheus.Collector
+func (c *ProcessCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, col := range c.collectors {
+		col.Describe(ch)
+	}
+}
+
+// Collect 实现 prometheus.Collector, 备用实例不采集
+func (c *ProcessCollector) Collect(ch chan<- prometheus.Metric) {
+	if c.e.active.Load() {
+		c.e.Update(context.Background())
+	}
+	for _, col := range c.collectors {
+		col.Collect(ch)
+	}
+}
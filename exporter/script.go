@@ -0,0 +1,96 @@
+package exporter
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// script 是编译好的派生指标脚本
+type script struct {
+	name    string
+	program *starlark.Program
+	gauge   *prometheus.GaugeVec
+}
+
+func newScript(cfg ScriptConfig) (*script, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("script without name")
+	}
+	program, err := compileScript(cfg.Name, cfg.Source)
+	if err != nil {
+		return nil, fmt.Errorf("compiling script %s: %w", cfg.Name, err)
+	}
+
+	help := cfg.Help
+	if help == "" {
+		help = T("help.script", cfg.Name)
+	}
+	return &script{
+		name:    cfg.Name,
+		program: program,
+		gauge: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: cfg.Name,
+			Help: help,
+		}, []string{"process"}),
+	}, nil
+}
+
+// compileScript 编译可以引用 statsVars 的脚本, 单个表达式等价于给 value 赋值
+func compileScript(name, src string) (*starlark.Program, error) {
+	if _, err := syntax.ParseExpr(name, src, 0); err == nil {
+		src = "value = (" + src + ")\n"
+	}
+
+	known := make(map[string]bool, len(statsVars))
+	for _, v := range statsVars {
+		known[v] = true
+	}
+	// 允许在顶层使用 if/for 以便写简单的条件逻辑
+	opts := &syntax.FileOptions{TopLevelControl: true, GlobalReassign: true}
+	_, program, err := starlark.SourceProgramOptions(opts, name, src, func(name string) bool {
+		return known[name]
+	})
+	return program, err
+}
+
+// eval 以 s 的数据执行脚本, 返回 value 的值. 脚本没有给出 value 或 value 为 None 时 ok 为 false
+func (sc *script) eval(s *Stats) (v float64, ok bool, err error) {
+	return evalProgram(sc.name, sc.program, s)
+}
+
+func evalProgram(name string, program *starlark.Program, s *Stats) (v float64, ok bool, err error) {
+	predeclared := make(starlark.StringDict)
+	for name, val := range s.values() {
+		predeclared[name] = starlark.Float(val)
+	}
+	thread := &starlark.Thread{Name: name}
+	// 防止脚本死循环拖住采集
+	thread.SetMaxExecutionSteps(100000)
+
+	globals, err := program.Init(thread, predeclared)
+	if err != nil {
+		return 0, false, err
+	}
+	return toFloat(globals["value"])
+}
+
+// toFloat 把脚本结果转换为指标值, nil 或 None 时 ok 为 false
+func toFloat(v starlark.Value) (f float64, ok bool, err error) {
+	switch x := v.(type) {
+	case nil, starlark.NoneType:
+		return 0, false, nil
+	case starlark.Float:
+		return float64(x), true, nil
+	case starlark.Int:
+		return float64(x.Float()), true, nil
+	case starlark.Bool:
+		if x {
+			return 1, true, nil
+		}
+		return 0, true, nil
+	default:
+		return 0, false, fmt.Errorf("value has type %s, want number", x.Type())
+	}
+}
@@ -0,0 +1,158 @@
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+const (
+	namespace = "process"
+	// subsystem 用于 exporter 自身的指标
+	subsystem = "exporter"
+)
+
+// selfMetrics 是 exporter 自身运行状况的指标
+type selfMetrics struct {
+	scrapes        *prometheus.CounterVec
+	scrapesFlight  prometheus.Gauge
+	scrapeDuration *prometheus.HistogramVec
+	cycles         *prometheus.CounterVec
+	cycleDuration  prometheus.Histogram
+	denials        *prometheus.CounterVec
+	targetTimeouts *prometheus.CounterVec
+	collTimeouts   *prometheus.CounterVec
+	leader         prometheus.Gauge
+	tableScans     prometheus.Counter
+	// 设置了 cache_ttl 时复用上一次结果的抓取
+	cacheHits prometheus.Counter
+	// 按结果统计的告警通知
+	alertNotifications *prometheus.CounterVec
+
+	// 每个目标最近一次采集的耗时和失败次数, 以及最近一个采集周期结束的时间
+	collectDuration *prometheus.GaugeVec
+	collectErrors   *prometheus.CounterVec
+	lastCollect     prometheus.Gauge
+}
+
+// nativeHistogramBucketFactor 是启用 native histogram 时的桶增长系数
+const nativeHistogramBucketFactor = 1.1
+
+// newSelfMetrics 创建自身指标, native 为 true 时直方图同时提供 native histogram,
+// 它只能通过 protobuf 格式抓取, 文本格式仍然输出普通的桶
+func newSelfMetrics(native bool) *selfMetrics {
+	factor := 0.0
+	if native {
+		factor = nativeHistogramBucketFactor
+	}
+	return &selfMetrics{
+		scrapes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "scrapes_total",
+			Help:      T("help.scrapes_total"),
+		}, []string{"code"}),
+		scrapesFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "scrapes_in_flight",
+			Help:      T("help.scrapes_in_flight"),
+		}),
+		scrapeDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "scrape_duration_seconds",
+			Help:      T("help.scrape_duration_seconds"),
+			Buckets:   prometheus.DefBuckets,
+
+			NativeHistogramBucketFactor: factor,
+		}, []string{"code"}),
+		cycles: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "collection_cycles_total",
+			Help:      T("help.collection_cycles_total"),
+		}, []string{"result"}),
+		cycleDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "collection_cycle_duration_seconds",
+			Help:      T("help.collection_cycle_duration_seconds"),
+			Buckets:   prometheus.DefBuckets,
+
+			NativeHistogramBucketFactor: factor,
+		}),
+		denials: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "permission_denials_total",
+			Help:      T("help.permission_denials_total"),
+		}, []string{"process", "field"}),
+		targetTimeouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "target_timeouts_total",
+			Help:      T("help.target_timeouts_total"),
+		}, []string{"process"}),
+		collTimeouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "collector_timeouts_total",
+			Help:      T("help.collector_timeouts_total"),
+		}, []string{"collector"}),
+		leader: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "leader",
+			Help:      T("help.leader"),
+		}),
+		tableScans: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "process_table_scans_total",
+			Help:      T("help.process_table_scans_total"),
+		}),
+		cacheHits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "scrape_cache_hits_total",
+			Help:      T("help.scrape_cache_hits_total"),
+		}),
+		alertNotifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "alert_notifications_total",
+			Help:      T("help.alert_notifications_total"),
+		}, []string{"result"}),
+		collectDuration: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "collect_duration_seconds",
+			Help:      T("help.collect_duration_seconds"),
+		}, []string{"process"}),
+		collectErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "collect_errors_total",
+			Help:      T("help.collect_errors_total"),
+		}, []string{"process", "error_type"}),
+		lastCollect: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "last_collect_timestamp_seconds",
+			Help:      T("help.last_collect_timestamp_seconds"),
+		}),
+	}
+}
+
+func (m *selfMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.scrapes, m.scrapesFlight, m.scrapeDuration, m.cycles, m.cycleDuration, m.denials, m.targetTimeouts, m.collTimeouts, m.leader, m.tableScans, m.cacheHits, m.alertNotifications,
+		m.collectDuration, m.collectErrors, m.lastCollect}
+}
+
+// instrument 为指标 handler 记录抓取次数、并发数和耗时
+func (m *selfMetrics) instrument(h http.Handler) http.Handler {
+	return promhttp.InstrumentHandlerInFlight(m.scrapesFlight,
+		promhttp.InstrumentHandlerCounter(m.scrapes,
+			promhttp.InstrumentHandlerDuration(m.scrapeDuration, h)))
+}
@@ -0,0 +1,15 @@
+package exporter
+
+import (
+	"context"
+	"github.com/shirou/gopsutil/process"
+)
+
+// sharedMemory 返回进程的共享内存字节数, 来自 /proc/<pid>/statm
+func sharedMemory(ctx context.Context, p *process.Process) (uint64, error) {
+	ex, err := p.MemoryInfoExWithContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return ex.Shared, nil
+}
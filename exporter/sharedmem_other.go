@@ -0,0 +1,14 @@
+//go:build !linux
+
+package exporter
+
+import (
+	"context"
+	"errors"
+	"github.com/shirou/gopsutil/process"
+)
+
+// sharedMemory 只在 Linux 上支持
+func sharedMemory(ctx context.Context, p *process.Process) (uint64, error) {
+	return 0, errors.New("shared memory is only available on Linux")
+}
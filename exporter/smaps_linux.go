@@ -0,0 +1,42 @@
+package exporter
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// readSmaps 返回进程交换到 swap 的字节数和按共享进程数均摊的 SwapPss 字节数,
+// 来自 /proc/<pid>/smaps_rollup, 4.14 之前的内核没有它时逐个累加 /proc/<pid>/smaps 中的映射
+func readSmaps(pid int32) (swap, swapPss uint64, err error) {
+	dir := procPath(pid)
+	data, err := os.ReadFile(filepath.Join(dir, "smaps_rollup"))
+	if errors.Is(err, os.ErrNotExist) {
+		data, err = os.ReadFile(filepath.Join(dir, "smaps"))
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		// 形如 "Swap:               12 kB"
+		key, value, ok := strings.Cut(sc.Text(), ":")
+		if !ok || (key != "Swap" && key != "SwapPss") {
+			continue
+		}
+		kb, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimSpace(value), " kB"), 10, 64)
+		if err != nil {
+			continue
+		}
+		if key == "Swap" {
+			swap += kb * 1024
+		} else {
+			swapPss += kb * 1024
+		}
+	}
+	return swap, swapPss, sc.Err()
+}
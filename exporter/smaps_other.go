@@ -0,0 +1,10 @@
+//go:build !linux
+
+package exporter
+
+import "errors"
+
+// readSmaps 只在 Linux 上支持
+func readSmaps(pid int32) (swap, swapPss uint64, err error) {
+	return 0, 0, errors.New("smaps is only available on Linux")
+}
@@ -0,0 +1,109 @@
+package exporter
+
+import (
+	"net/http"
+	"sort"
+	"time"
+)
+
+// targetState 记录单个监控目标最近的采集情况
+type targetState struct {
+	// 所有匹配的 PID, 第一个为单进程指标监控的进程
+	pids        []int32
+	lastAttempt time.Time
+	lastError   error
+	lastErrorAt time.Time
+	// 连续失败的次数, 成功后清零
+	failures int
+	// 上一次看到的监控的进程的 PID, 用于统计重启次数
+	lastPID int32
+	// 最近一次匹配到进程的时间, 以及是否因超过 series_ttl 删除了序列
+	lastSeen time.Time
+	expired  bool
+	// 上一次扫描进程表查找 PID 的时间, 在此之前 pids 仍然有效时复用它们
+	scannedAt time.Time
+}
+
+// targetState 返回 name 的状态, 不存在时创建
+func (e *Exporter) targetState(name string) *targetState {
+	st, ok := e.state[name]
+	if !ok {
+		st = &targetState{}
+		e.state[name] = st
+	}
+	return st
+}
+
+// observe 记录一次采集的结果
+func (st *targetState) observe(at time.Time, err error) {
+	st.lastAttempt = at
+	if err != nil {
+		st.lastError = err
+		st.lastErrorAt = at
+		st.failures++
+		return
+	}
+	st.failures = 0
+}
+
+// targetStateView 是 /debug/state 中单个目标的输出
+type targetStateView struct {
+	Target string  `json:"target"`
+	Match  string  `json:"match"`
+	PIDs   []int32 `json:"pids"`
+
+	LastAttempt    *time.Time `json:"last_attempt,omitempty"`
+	LastCollection *time.Time `json:"last_collection,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	LastErrorAt    *time.Time `json:"last_error_at,omitempty"`
+
+	// 两次采集之间至少间隔 5 秒 (或目标配置的 interval), 在此之前的周期会跳过该目标
+	NextCollection *time.Time `json:"next_collection,omitempty"`
+	Throttled      bool       `json:"throttled"`
+	Failures       int        `json:"consecutive_failures"`
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// stateViews 返回按目标名排序的内部目标表, 调用方需持有 mutex
+func (e *Exporter) stateViews() []targetStateView {
+	views := make([]targetStateView, 0, len(e.processNames))
+	now := e.clock.Now()
+	for _, name := range e.processNames {
+		v := targetStateView{Target: name, Match: e.matchers[name].pattern, PIDs: []int32{}}
+		if st, ok := e.state[name]; ok {
+			v.PIDs = append(v.PIDs, st.pids...)
+			v.LastAttempt = timePtr(st.lastAttempt)
+			if st.lastError != nil {
+				v.LastError = st.lastError.Error()
+				v.LastErrorAt = timePtr(st.lastErrorAt)
+			}
+			v.Failures = st.failures
+		}
+		if last, ok := e.lastUpdate[name]; ok {
+			next := e.nextCollection(name, last)
+			v.LastCollection = timePtr(last)
+			v.NextCollection = &next
+			v.Throttled = now.Before(next)
+		}
+		views = append(views, v)
+	}
+	sort.Slice(views, func(i, j int) bool { return views[i].Target < views[j].Target })
+	return views
+}
+
+// StateHandler 返回以 JSON 输出内部目标表的 http.Handler,
+// 用于排查某个序列为什么一直是 0
+func (e *Exporter) StateHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		e.mutex.Lock()
+		views := e.stateViews()
+		e.mutex.Unlock()
+		writeJSON(w, http.StatusOK, views)
+	})
+}
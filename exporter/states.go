@@ -0,0 +1,35 @@
+package exporter
+
+import (
+	"context"
+	"github.com/shirou/gopsutil/process"
+)
+
+// processStates 是 process_states 的 state 标签的取值, 与 process_status 一致, 其余状态计入 other
+var processStates = []string{"running", "sleeping", "disk-sleep", "zombie", "stopped", "idle", "other"}
+
+// updateStates 按状态统计目标匹配到的进程数, 用于发现卡在僵尸状态、没有被父进程回收的工作进程
+func (e *Exporter) updateStates(ctx context.Context, processName string, pids []int32) {
+	counts := make(map[string]int, len(processStates))
+	for _, s := range processStates {
+		counts[s] = 0
+	}
+	for _, pid := range pids {
+		p, err := process.NewProcessWithContext(ctx, pid)
+		if err != nil {
+			continue
+		}
+		status, err := p.StatusWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		state := stateName(status)
+		if _, ok := counts[state]; !ok {
+			state = "other"
+		}
+		counts[state]++
+	}
+	for state, n := range counts {
+		e.states.WithLabelValues(processName, state).Set(float64(n))
+	}
+}
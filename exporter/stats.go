@@ -0,0 +1,74 @@
+package exporter
+
+import (
+	"time"
+)
+
+// Stats 是一次采集得到的单个进程的数据
+type Stats struct {
+	Process       string
+	PID           int32
+	CPUPercent    float64
+	MemoryPercent float64
+	MemoryRSS     uint64
+	MemoryVMS     uint64
+	MemorySwap    uint64
+	MemoryShared  uint64
+	// 开启 smaps 时按共享进程数均摊的 swap 字节数
+	MemorySwapPss uint64
+	NumFDs        int32
+	NumThreads    int32
+	// 进程状态, 如 running、sleeping, 见 stateName
+	Status string
+	// 监控的进程的启动时间, 读取失败时为零值
+	StartTime time.Time
+	// 部分数据因权限不足无法读取, 对应字段为 0
+	Incomplete bool
+	Time       time.Time
+}
+
+// fdTypes 是 process_open_files 的 type 标签的所有取值
+var fdTypes = []string{"file", "socket", "pipe", "anon_inode", "device", "other"}
+
+// statsVars 是脚本中可以引用的变量名, 与 Stats.values 的键保持一致
+var statsVars = []string{"pid", "cpu_percent", "memory_percent", "memory_rss_bytes", "memory_vms_bytes", "memory_swap_bytes", "num_fds", "num_threads"}
+
+// values 返回供脚本使用的变量
+func (s *Stats) values() map[string]float64 {
+	return map[string]float64{
+		"pid":               float64(s.PID),
+		"cpu_percent":       s.CPUPercent,
+		"memory_percent":    s.MemoryPercent,
+		"memory_rss_bytes":  float64(s.MemoryRSS),
+		"memory_vms_bytes":  float64(s.MemoryVMS),
+		"memory_swap_bytes": float64(s.MemorySwap),
+		"num_fds":           float64(s.NumFDs),
+		"num_threads":       float64(s.NumThreads),
+	}
+}
+
+// stateName 把 /proc/<pid>/status 中的单字母状态转换为可读的名称
+func stateName(letter string) string {
+	switch letter {
+	case "R":
+		return "running"
+	case "S":
+		return "sleeping"
+	case "D":
+		return "disk-sleep"
+	case "Z":
+		return "zombie"
+	case "T":
+		return "stopped"
+	case "t":
+		return "tracing-stop"
+	case "I":
+		return "idle"
+	case "X":
+		return "dead"
+	case "":
+		return "unknown"
+	default:
+		return letter
+	}
+}
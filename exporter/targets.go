@@ -0,0 +1,90 @@
+package exporter
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// targetConfigs 返回以名称为键的目标配置, 同名的目标只使用第一个
+func targetConfigs(targets []TargetConfig) map[string]TargetConfig {
+	m := make(map[string]TargetConfig, len(targets))
+	for _, t := range targets {
+		if _, ok := m[t.Name]; !ok {
+			m[t.Name] = t
+		}
+	}
+	return m
+}
+
+// targetLabelNames 返回 process_target_info 的标签名: process、incomplete、match 以及所有目标和 groups 规则的静态标签名和动态标签名
+func targetLabelNames(targets map[string]TargetConfig, groups []GroupConfig) ([]string, error) {
+	seen := make(map[string]bool)
+	var extra []string
+	for _, t := range append(slices.Collect(maps.Values(targets)), groupTargets(groups)...) {
+		for expr := range maps.Values(t.CmdlineLabels) {
+			if _, err := regexp.Compile(expr); err != nil {
+				return nil, fmt.Errorf("target %s: cmdline_labels: %w", t.Name, err)
+			}
+		}
+		own := make(map[string]bool)
+		for _, labels := range []map[string]string{t.Labels, t.EnvLabels, t.CmdlineLabels} {
+			for name := range labels {
+				switch {
+				case !model.LabelName(name).IsValid() || strings.HasPrefix(name, "__"):
+					return nil, fmt.Errorf("target %s: invalid label name %q", t.Name, name)
+				case name == "process" || name == "incomplete" || name == "match":
+					return nil, fmt.Errorf("target %s: label name %q is reserved", t.Name, name)
+				case own[name]:
+					return nil, fmt.Errorf("target %s: label %q is set more than once", t.Name, name)
+				}
+				own[name] = true
+				if !seen[name] {
+					seen[name] = true
+					extra = append(extra, name)
+				}
+			}
+		}
+	}
+	sort.Strings(extra)
+	return append([]string{"process", "incomplete", "match"}, extra...), nil
+}
+
+// targetInfoLabels 返回目标在 process_target_info 上的标签, 其他目标配置而该目标没有的标签为空
+func (e *Exporter) targetInfoLabels(name string, incomplete bool) prometheus.Labels {
+	labels := make(prometheus.Labels, len(e.infoLabelNames))
+	for _, l := range e.infoLabelNames {
+		labels[l] = e.targets[name].Labels[l]
+		if v, ok := e.dynamicLabels[name][l]; ok {
+			labels[l] = v
+		}
+	}
+	labels["process"] = name
+	labels["incomplete"] = strconv.FormatBool(incomplete)
+	labels["match"] = e.matchers[name].pattern
+	return labels
+}
+
+// interval 返回目标两次采集之间的最短间隔, 不小于采集周期. 抓取时采集时只受目标的 interval 限制
+func (e *Exporter) interval(name string) time.Duration {
+	floor := e.collectInterval
+	if e.collectOnScrape {
+		floor = 0
+	}
+	if d := e.targets[name].Interval; d > floor {
+		return d
+	}
+	return floor
+}
+
+// nextCollection 返回上次在 last 采集的目标下一次到期的时间, 包括 jitter 的随机推迟
+func (e *Exporter) nextCollection(name string, last time.Time) time.Time {
+	return last.Add(e.interval(name) + e.delays[name])
+}
@@ -0,0 +1,109 @@
+package exporter
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"slices"
+)
+
+// targetView 是 /api/v1/targets 中的单个监控目标
+type targetView struct {
+	Name  string `json:"name"`
+	Match string `json:"match"`
+}
+
+// handleListTargets 返回当前的监控目标
+func (e *Exporter) handleListTargets(w http.ResponseWriter, r *http.Request) {
+	e.mutex.Lock()
+	views := make([]targetView, 0, len(e.processNames))
+	for _, name := range e.processNames {
+		views = append(views, targetView{Name: name, Match: e.matchers[name].pattern})
+	}
+	e.mutex.Unlock()
+	writeJSON(w, http.StatusOK, apiResponse{Status: "success", Data: views})
+}
+
+// targetRequest 是 POST /api/v1/targets 的请求体. 只允许进程名和匹配规则,
+// check_command、probe、logs 等会执行命令、读取文件或发起请求的设置只能写在配置文件中
+type targetRequest struct {
+	Name  string `yaml:"name"`
+	Match string `yaml:"match"`
+}
+
+// admin 在没有启用 AdminAPI 时对修改目标的请求返回 403
+func (e *Exporter) admin(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !e.adminAPI {
+			writeAPIError(w, http.StatusForbidden, errors.New("modifying targets is disabled, start with -web.enable-admin-api to enable it"))
+			return
+		}
+		h(w, r)
+	}
+}
+
+// handleAddTarget 添加一个监控目标, 请求体是只含 name 和 match 的 JSON 或 YAML,
+// 如 {"name": "nginx", "match": "name:nginx.*"}, 其他字段返回 400
+func (e *Exporter) handleAddTarget(w http.ResponseWriter, r *http.Request) {
+	var req targetRequest
+	dec := yaml.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.KnownFields(true)
+	if err := dec.Decode(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("parsing target: %w", err))
+		return
+	}
+	t := TargetConfig{Name: req.Name, Match: req.Match}
+	code, err := e.updateTargets(func(cfg *Config) (int, error) {
+		if slices.Contains(cfg.TargetNames(), t.Name) {
+			return http.StatusConflict, fmt.Errorf("target %q already exists", t.Name)
+		}
+		cfg.Targets = append(slices.Clone(cfg.Targets), t)
+		return http.StatusCreated, nil
+	})
+	if err != nil {
+		writeAPIError(w, code, err)
+		return
+	}
+	writeJSON(w, code, apiResponse{Status: "success"})
+}
+
+// handleDeleteTarget 删除一个监控目标及其所有序列
+func (e *Exporter) handleDeleteTarget(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	code, err := e.updateTargets(func(cfg *Config) (int, error) {
+		if !slices.Contains(cfg.TargetNames(), name) {
+			return http.StatusNotFound, fmt.Errorf("target %q not found", name)
+		}
+		cfg.Processes = slices.DeleteFunc(slices.Clone(cfg.Processes), func(p string) bool { return p == name })
+		cfg.Targets = slices.DeleteFunc(slices.Clone(cfg.Targets), func(t TargetConfig) bool { return t.Name == name })
+		return http.StatusOK, nil
+	})
+	if err != nil {
+		writeAPIError(w, code, err)
+		return
+	}
+	writeJSON(w, code, apiResponse{Status: "success"})
+}
+
+// updateTargets 用 modify 修改当前配置的副本后通过 Reload 应用, 返回响应的状态码.
+// apiMutex 保证并发的修改不会互相覆盖
+func (e *Exporter) updateTargets(modify func(cfg *Config) (int, error)) (int, error) {
+	e.apiMutex.Lock()
+	defer e.apiMutex.Unlock()
+	e.mutex.Lock()
+	cfg := e.config
+	e.mutex.Unlock()
+
+	code, err := modify(&cfg)
+	if err != nil {
+		return code, err
+	}
+	if len(cfg.TargetNames()) == 0 && len(cfg.Groups) == 0 {
+		return http.StatusBadRequest, errors.New("cannot remove the last target")
+	}
+	if err := e.Reload(cfg); err != nil {
+		return http.StatusBadRequest, err
+	}
+	return code, nil
+}
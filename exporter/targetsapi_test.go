@@ -0,0 +1,71 @@
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func newAPIExporter(t *testing.T, admin bool) *Exporter {
+	t.Helper()
+	e, err := New(Opts{Config: Config{Processes: []string{"api-existing"}}, Registerer: prometheus.NewRegistry(), AdminAPI: admin})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return e
+}
+
+func apiRequest(e *Exporter, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	e.APIHandler().ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return w
+}
+
+func TestAddTarget(t *testing.T) {
+	e := newAPIExporter(t, true)
+	if w := apiRequest(e, http.MethodPost, "/api/v1/targets", `{"name": "api-new", "match": "name:api-new.*"}`); w.Code != http.StatusCreated {
+		t.Fatalf("POST = %d %s, want %d", w.Code, w.Body, http.StatusCreated)
+	}
+	if !slices.Contains(e.processNames, "api-new") {
+		t.Fatalf("targets = %v, want api-new added", e.processNames)
+	}
+	if w := apiRequest(e, http.MethodDelete, "/api/v1/targets/api-new", ""); w.Code != http.StatusOK {
+		t.Fatalf("DELETE = %d %s, want %d", w.Code, w.Body, http.StatusOK)
+	}
+	if slices.Contains(e.processNames, "api-new") {
+		t.Fatalf("targets = %v, want api-new removed", e.processNames)
+	}
+}
+
+// 请求中的 check_command、probe 和 logs 会执行命令、发起请求或读取文件, 必须被拒绝
+func TestAddTargetRejectsUnsafeFields(t *testing.T) {
+	e := newAPIExporter(t, true)
+	for _, body := range []string{
+		`{"name": "api-evil", "check_command": "touch /tmp/pwned"}`,
+		`{"name": "api-evil", "probe": {"http": "http://169.254.169.254/"}}`,
+		`{"name": "api-evil", "logs": [{"path": "/etc/shadow", "patterns": ["."]}]}`,
+	} {
+		if w := apiRequest(e, http.MethodPost, "/api/v1/targets", body); w.Code != http.StatusBadRequest {
+			t.Errorf("POST %s = %d, want %d", body, w.Code, http.StatusBadRequest)
+		}
+	}
+	if slices.Contains(e.processNames, "api-evil") {
+		t.Fatalf("targets = %v, rejected target was added", e.processNames)
+	}
+}
+
+func TestAdminAPIDisabled(t *testing.T) {
+	e := newAPIExporter(t, false)
+	if w := apiRequest(e, http.MethodPost, "/api/v1/targets", `{"name": "api-new"}`); w.Code != http.StatusForbidden {
+		t.Errorf("POST = %d, want %d", w.Code, http.StatusForbidden)
+	}
+	if w := apiRequest(e, http.MethodDelete, "/api/v1/targets/api-existing", ""); w.Code != http.StatusForbidden {
+		t.Errorf("DELETE = %d, want %d", w.Code, http.StatusForbidden)
+	}
+	if w := apiRequest(e, http.MethodGet, "/api/v1/targets", ""); w.Code != http.StatusOK {
+		t.Errorf("GET = %d, want %d", w.Code, http.StatusOK)
+	}
+}
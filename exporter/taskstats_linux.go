@@ -0,0 +1,327 @@
+//go:build linux && !notaskstats
+
+package exporter
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"log/slog"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// generic netlink 和 taskstats 的常量, 见 linux/genetlink.h 和 linux/taskstats.h
+const (
+	genlIDCtrl             = 0x10
+	ctrlCmdGetFamily       = 3
+	ctrlAttrFamilyID       = 1
+	ctrlAttrFamilyName     = 2
+	taskstatsCmdGet        = 1
+	taskstatsCmdNew        = 2
+	taskstatsGenlVersion   = 1
+	taskstatsAttrRegister  = 3
+	taskstatsTypePID       = 1
+	taskstatsTypeStats     = 3
+	taskstatsTypeAggrPID   = 4
+	nlaTypeMask            = 0x3fff
+	taskstatsFamilyName    = "TASKSTATS"
+	taskstatsRecvBuffer    = 1 << 20
+	taskstatsReadTimeout   = time.Second
+	taskstatsCommLen       = 32
+	taskstatsOffsetComm    = 80
+	taskstatsOffsetPID     = 128
+	taskstatsOffsetEtime   = 144
+	taskstatsOffsetUtime   = 152
+	taskstatsOffsetStime   = 160
+	taskstatsOffsetRead    = 248
+	taskstatsOffsetWrite   = 256
+	taskstatsMinStructSize = taskstatsOffsetWrite + 8
+	// ac_tgid 从 taskstats 第 12 版 (Linux 6.0) 开始提供
+	taskstatsVersionTGID = 12
+	taskstatsOffsetTGID  = 368
+	taskstatsMinTGIDSize = taskstatsOffsetTGID + 4
+)
+
+// maxTaskstatsComms 限制 comm 标签的取值个数, 超出的计入 comm="other"
+const maxTaskstatsComms = 500
+
+// TaskstatsCollector 通过 netlink taskstats 接收内核在任务退出时发送的统计,
+// 按命令名累计短命进程的 CPU 时间和 IO, 需要 CAP_NET_ADMIN
+type TaskstatsCollector struct {
+	fd     int
+	family uint16
+	logger *slog.Logger
+	// 在两个采集周期以内退出的任务视为短命进程, 轮询采集通常完全看不到它们
+	threshold time.Duration
+
+	mu    sync.Mutex
+	comms map[string]bool
+	exits *prometheus.CounterVec
+	cpu   *prometheus.CounterVec
+	read  *prometheus.CounterVec
+	write *prometheus.CounterVec
+	drops prometheus.Counter
+}
+
+// NewTaskstatsCollector 注册接收所有 CPU 上的任务退出统计, 调用 Start 后才开始接收
+func NewTaskstatsCollector(logger *slog.Logger) (*TaskstatsCollector, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, syscall.NETLINK_GENERIC)
+	if err != nil {
+		return nil, fmt.Errorf("opening netlink socket: %w", err)
+	}
+	c := &TaskstatsCollector{fd: fd, logger: logger, comms: make(map[string]bool)}
+	if err := c.init(); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+
+	c.exits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "short_lived_exits_total",
+		Help:      T("help.short_lived_exits_total"),
+	}, []string{"comm"})
+	c.cpu = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "short_lived_cpu_seconds_total",
+		Help:      T("help.short_lived_cpu_seconds_total"),
+	}, []string{"comm"})
+	c.read = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "short_lived_read_bytes_total",
+		Help:      T("help.short_lived_read_bytes_total"),
+	}, []string{"comm"})
+	c.write = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "short_lived_write_bytes_total",
+		Help:      T("help.short_lived_write_bytes_total"),
+	}, []string{"comm"})
+	c.drops = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "taskstats_dropped_total",
+		Help:      T("help.taskstats_dropped_total"),
+	})
+	return c, nil
+}
+
+// Start 在后台接收退出统计直到 ctx 被取消, 运行不到两个 cycle (Exporter.CycleInterval) 的任务计为短命进程
+func (c *TaskstatsCollector) Start(ctx context.Context, cycle time.Duration) {
+	c.threshold = 2 * cycle
+	go c.run(ctx)
+}
+
+// init 查询 TASKSTATS 的 family ID 并注册接收退出统计
+func (c *TaskstatsCollector) init() error {
+	if err := syscall.Bind(c.fd, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		return fmt.Errorf("binding netlink socket: %w", err)
+	}
+	syscall.SetsockoptInt(c.fd, syscall.SOL_SOCKET, syscall.SO_RCVBUF, taskstatsRecvBuffer)
+	tv := syscall.NsecToTimeval(taskstatsReadTimeout.Nanoseconds())
+	if err := syscall.SetsockoptTimeval(c.fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &tv); err != nil {
+		return fmt.Errorf("setting netlink read timeout: %w", err)
+	}
+
+	if err := c.send(genlIDCtrl, ctrlCmdGetFamily, ctrlAttrFamilyName, taskstatsFamilyName); err != nil {
+		return fmt.Errorf("resolving taskstats family: %w", err)
+	}
+	msgs, err := c.recv()
+	if err != nil {
+		return fmt.Errorf("resolving taskstats family: %w", err)
+	}
+	for _, m := range msgs {
+		if m.Header.Type == syscall.NLMSG_ERROR {
+			return fmt.Errorf("resolving taskstats family: %w", netlinkError(m.Data))
+		}
+		if len(m.Data) < 4 {
+			continue
+		}
+		for _, a := range parseAttrs(m.Data[4:]) {
+			if a.typ == ctrlAttrFamilyID && len(a.data) >= 2 {
+				c.family = binary.NativeEndian.Uint16(a.data)
+			}
+		}
+	}
+	if c.family == 0 {
+		return errors.New("taskstats family not found")
+	}
+
+	if err := c.send(c.family, taskstatsCmdGet, taskstatsAttrRegister, possibleCPUs()); err != nil {
+		return fmt.Errorf("registering for taskstats: %w", err)
+	}
+	return nil
+}
+
+// send 发送一条只带一个字符串属性的 generic netlink 请求
+func (c *TaskstatsCollector) send(family uint16, cmd uint8, attr uint16, value string) error {
+	payload := append([]byte(value), 0)
+	attrLen := 4 + len(payload)
+	msgLen := syscall.NLMSG_HDRLEN + 4 + nlaAlign(attrLen)
+	b := make([]byte, msgLen)
+	binary.NativeEndian.PutUint32(b[0:], uint32(msgLen))
+	binary.NativeEndian.PutUint16(b[4:], family)
+	binary.NativeEndian.PutUint16(b[6:], syscall.NLM_F_REQUEST)
+	b[16] = cmd
+	b[17] = taskstatsGenlVersion
+	binary.NativeEndian.PutUint16(b[20:], uint16(attrLen))
+	binary.NativeEndian.PutUint16(b[22:], attr)
+	copy(b[24:], payload)
+	return syscall.Sendto(c.fd, b, 0, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK})
+}
+
+func (c *TaskstatsCollector) recv() ([]syscall.NetlinkMessage, error) {
+	buf := make([]byte, os.Getpagesize()*16)
+	n, _, err := syscall.Recvfrom(c.fd, buf, 0)
+	if err != nil {
+		return nil, err
+	}
+	return syscall.ParseNetlinkMessage(buf[:n])
+}
+
+// run 接收退出统计直到 ctx 被取消
+func (c *TaskstatsCollector) run(ctx context.Context) {
+	defer syscall.Close(c.fd)
+	for ctx.Err() == nil {
+		msgs, err := c.recv()
+		switch {
+		case err == syscall.EAGAIN || err == syscall.EINTR:
+			continue
+		case err == syscall.ENOBUFS:
+			// 接收缓冲区溢出, 部分退出统计丢失
+			c.drops.Inc()
+			continue
+		case err != nil:
+			c.logger.Error(T("log.taskstats"), "error", err)
+			return
+		}
+		for _, m := range msgs {
+			if m.Header.Type == c.family && len(m.Data) > 4 && m.Data[0] == taskstatsCmdNew {
+				c.handle(m.Data[4:])
+			}
+		}
+	}
+}
+
+// handle 处理一条 TASKSTATS_CMD_NEW 消息. 每个线程退出时各发送一次 AGGR_PID,
+// 只统计它就不会与整个线程组退出时的 AGGR_TGID 重复计算. CPU 时间和 IO 按线程累加,
+// 退出次数只统计主线程 (tgid 等于 pid), 不提供 ac_tgid 的旧内核上仍按线程计数
+func (c *TaskstatsCollector) handle(data []byte) {
+	for _, a := range parseAttrs(data) {
+		if a.typ != taskstatsTypeAggrPID {
+			continue
+		}
+		for _, na := range parseAttrs(a.data) {
+			if na.typ != taskstatsTypeStats || len(na.data) < taskstatsMinStructSize {
+				continue
+			}
+			s := na.data
+			etime := time.Duration(binary.NativeEndian.Uint64(s[taskstatsOffsetEtime:])) * time.Microsecond
+			if etime >= c.threshold {
+				continue
+			}
+			comm := c.comm(strings.TrimRight(string(s[taskstatsOffsetComm:taskstatsOffsetComm+taskstatsCommLen]), "\x00"))
+			cpu := binary.NativeEndian.Uint64(s[taskstatsOffsetUtime:]) + binary.NativeEndian.Uint64(s[taskstatsOffsetStime:])
+			if leader(s) {
+				c.exits.WithLabelValues(comm).Inc()
+			}
+			c.cpu.WithLabelValues(comm).Add(float64(cpu) / 1e6)
+			c.read.WithLabelValues(comm).Add(float64(binary.NativeEndian.Uint64(s[taskstatsOffsetRead:])))
+			c.write.WithLabelValues(comm).Add(float64(binary.NativeEndian.Uint64(s[taskstatsOffsetWrite:])))
+		}
+	}
+}
+
+// leader 返回 taskstats 记录是否属于线程组的主线程
+func leader(s []byte) bool {
+	if binary.NativeEndian.Uint16(s) < taskstatsVersionTGID || len(s) < taskstatsMinTGIDSize {
+		return true
+	}
+	return binary.NativeEndian.Uint32(s[taskstatsOffsetTGID:]) == binary.NativeEndian.Uint32(s[taskstatsOffsetPID:])
+}
+
+// comm 返回规范化后用作标签的命令名, 超过 maxTaskstatsComms 个不同的名称后新名称计入 other
+func (c *TaskstatsCollector) comm(name string) string {
+	name = sanitizeName(name)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.comms[name] {
+		return name
+	}
+	if len(c.comms) >= maxTaskstatsComms {
+		return "other"
+	}
+	c.comms[name] = true
+	return name
+}
+
+// Name 实现 Collector
+func (c *TaskstatsCollector) Name() string { return "taskstats" }
+
+// Describe 实现 prometheus.Collector
+func (c *TaskstatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.exits.Describe(ch)
+	c.cpu.Describe(ch)
+	c.read.Describe(ch)
+	c.write.Describe(ch)
+	c.drops.Describe(ch)
+}
+
+// Collect 实现 prometheus.Collector
+func (c *TaskstatsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.exits.Collect(ch)
+	c.cpu.Collect(ch)
+	c.read.Collect(ch)
+	c.write.Collect(ch)
+	c.drops.Collect(ch)
+}
+
+type netlinkAttr struct {
+	typ  uint16
+	data []byte
+}
+
+// parseAttrs 解析 netlink 属性列表, 属性按 4 字节对齐, 使用本机字节序
+func parseAttrs(b []byte) []netlinkAttr {
+	var attrs []netlinkAttr
+	for len(b) >= 4 {
+		l := int(binary.NativeEndian.Uint16(b))
+		if l < 4 || l > len(b) {
+			break
+		}
+		attrs = append(attrs, netlinkAttr{typ: binary.NativeEndian.Uint16(b[2:]) & nlaTypeMask, data: b[4:l]})
+		if nlaAlign(l) >= len(b) {
+			break
+		}
+		b = b[nlaAlign(l):]
+	}
+	return attrs
+}
+
+func nlaAlign(l int) int { return (l + 3) &^ 3 }
+
+// netlinkError 解析 NLMSG_ERROR 消息中的错误码
+func netlinkError(data []byte) error {
+	if len(data) < 4 {
+		return errors.New("malformed netlink error")
+	}
+	errno := -int32(binary.NativeEndian.Uint32(data))
+	if errno == 0 {
+		return nil
+	}
+	return syscall.Errno(errno)
+}
+
+// possibleCPUs 返回所有可能的 CPU 编号, 如 "0-7"
+func possibleCPUs() string {
+	if b, err := os.ReadFile("/sys/devices/system/cpu/possible"); err == nil {
+		return strings.TrimSpace(string(b))
+	}
+	return "0-" + strconv.Itoa(runtime.NumCPU()-1)
+}
@@ -0,0 +1,23 @@
+//go:build !linux || notaskstats
+
+package exporter
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// TaskstatsCollector 只在 Linux 上且未使用 notaskstats 标签构建时可用
+type TaskstatsCollector struct {
+	Collector
+}
+
+// NewTaskstatsCollector 在不支持时总是返回错误
+func NewTaskstatsCollector(logger *slog.Logger) (*TaskstatsCollector, error) {
+	return nil, errors.New("taskstats is only available on Linux builds without the notaskstats tag")
+}
+
+// Start 不会被调用, NewTaskstatsCollector 总是返回错误
+func (c *TaskstatsCollector) Start(ctx context.Context, cycle time.Duration) {}
@@ -0,0 +1,151 @@
+package exporter
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// textfileCollector 在抓取时读取目录中的 *.prom 文件 (Prometheus 文本格式) 并原样输出其中的指标,
+// 与 node_exporter 的 textfile 采集器相同, 供部署脚本附加服务的元数据等指标
+type textfileCollector struct {
+	dir    string
+	logger *slog.Logger
+
+	mtime       *prometheus.Desc
+	scrapeError *prometheus.Desc
+}
+
+func newTextfileCollector(ns, dir string, logger *slog.Logger) *textfileCollector {
+	return &textfileCollector{
+		dir:         dir,
+		logger:      logger,
+		mtime:       prometheus.NewDesc(prometheus.BuildFQName(ns, "textfile", "mtime_seconds"), T("help.textfile_mtime_seconds"), []string{"file"}, nil),
+		scrapeError: prometheus.NewDesc(prometheus.BuildFQName(ns, "textfile", "scrape_error"), T("help.textfile_scrape_error"), nil, nil),
+	}
+}
+
+// Describe 不输出任何 Desc, 文件中的指标在读取之前未知, 因此作为 unchecked 采集器注册
+func (c *textfileCollector) Describe(ch chan<- *prometheus.Desc) {}
+
+// Collect 跳过无法读取或解析的文件, 此时 scrape_error 为 1
+func (c *textfileCollector) Collect(ch chan<- prometheus.Metric) {
+	failed := 0.0
+	files, err := filepath.Glob(filepath.Join(c.dir, "*.prom"))
+	if err != nil {
+		c.logger.Warn(T("log.textfile"), "dir", c.dir, "error", err)
+		failed = 1
+	}
+	for _, path := range files {
+		mtime, err := c.collectFile(ch, path)
+		if err != nil {
+			c.logger.Warn(T("log.textfile"), "file", path, "error", err)
+			failed = 1
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.mtime, prometheus.GaugeValue, mtime, filepath.Base(path))
+	}
+	ch <- prometheus.MustNewConstMetric(c.scrapeError, prometheus.GaugeValue, failed)
+}
+
+// collectFile 输出文件 path 中的所有指标, 返回文件的修改时间. 文件中的任何错误都会让整个文件被跳过
+func (c *textfileCollector) collectFile(ch chan<- prometheus.Metric, path string) (float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(f)
+	if err != nil {
+		return 0, err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	var metrics []prometheus.Metric
+	for _, mf := range families {
+		ms, err := textfileMetrics(mf)
+		if err != nil {
+			return 0, err
+		}
+		metrics = append(metrics, ms...)
+	}
+	for _, m := range metrics {
+		ch <- m
+	}
+	return float64(st.ModTime().UnixNano()) / 1e9, nil
+}
+
+// textfileMetrics 把解析出的指标族转换为常量指标. 同一指标族中的序列缺少的标签补为空字符串,
+// 不支持带时间戳的样本
+func textfileMetrics(mf *dto.MetricFamily) ([]prometheus.Metric, error) {
+	help := mf.GetHelp()
+	if help == "" {
+		help = T("help.textfile_metric")
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for _, m := range mf.Metric {
+		if m.TimestampMs != nil {
+			return nil, fmt.Errorf("metric %s has a timestamp, which is not supported", mf.GetName())
+		}
+		for _, l := range m.Label {
+			if !seen[l.GetName()] {
+				seen[l.GetName()] = true
+				names = append(names, l.GetName())
+			}
+		}
+	}
+	sort.Strings(names)
+	desc := prometheus.NewDesc(mf.GetName(), help, names, nil)
+
+	var res []prometheus.Metric
+	for _, m := range mf.Metric {
+		labels := make(map[string]string, len(m.Label))
+		for _, l := range m.Label {
+			labels[l.GetName()] = l.GetValue()
+		}
+		values := make([]string, len(names))
+		for i, name := range names {
+			values[i] = labels[name]
+		}
+		var metric prometheus.Metric
+		var err error
+		switch mf.GetType() {
+		case dto.MetricType_COUNTER:
+			metric, err = prometheus.NewConstMetric(desc, prometheus.CounterValue, m.GetCounter().GetValue(), values...)
+		case dto.MetricType_GAUGE:
+			metric, err = prometheus.NewConstMetric(desc, prometheus.GaugeValue, m.GetGauge().GetValue(), values...)
+		case dto.MetricType_UNTYPED:
+			metric, err = prometheus.NewConstMetric(desc, prometheus.UntypedValue, m.GetUntyped().GetValue(), values...)
+		case dto.MetricType_SUMMARY:
+			s := m.GetSummary()
+			quantiles := make(map[float64]float64, len(s.Quantile))
+			for _, q := range s.Quantile {
+				quantiles[q.GetQuantile()] = q.GetValue()
+			}
+			metric, err = prometheus.NewConstSummary(desc, s.GetSampleCount(), s.GetSampleSum(), quantiles, values...)
+		case dto.MetricType_HISTOGRAM:
+			h := m.GetHistogram()
+			buckets := make(map[float64]uint64, len(h.Bucket))
+			for _, b := range h.Bucket {
+				buckets[b.GetUpperBound()] = b.GetCumulativeCount()
+			}
+			metric, err = prometheus.NewConstHistogram(desc, h.GetSampleCount(), h.GetSampleSum(), buckets, values...)
+		default:
+			err = fmt.Errorf("metric %s has unsupported type %s", mf.GetName(), mf.GetType())
+		}
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, metric)
+	}
+	return res, nil
+}
@@ -0,0 +1,24 @@
+package exporter
+
+// ctxSwitches 记录一个目标上一次采集时监控的进程及其上下文切换次数
+type ctxSwitches struct {
+	pid                    int32
+	voluntary, involuntary int64
+
+	// 为 true 时下一次采集只记录基线, 用于从其他实例接管计数器之后
+	baseline bool
+}
+
+// updateCtxSwitches 把监控的进程自上次采集以来主动和被动的上下文切换次数累加到
+// voluntary_ctxt_switches_total 和 involuntary_ctxt_switches_total, 进程重启的处理与 updateCPUTime 相同
+func (e *Exporter) updateCtxSwitches(processName string, pid int32, voluntary, involuntary int64) {
+	dv, di := voluntary, involuntary
+	if last, ok := e.ctxSwitches[processName]; ok && last.pid == pid {
+		dv, di = voluntary-last.voluntary, involuntary-last.involuntary
+	} else if ok && last.baseline {
+		dv, di = 0, 0
+	}
+	e.ctxSwitches[processName] = &ctxSwitches{pid: pid, voluntary: voluntary, involuntary: involuntary}
+	e.voluntaryCtx.WithLabelValues(processName).Add(float64(max(dv, 0)))
+	e.involuntaryCtx.WithLabelValues(processName).Add(float64(max(di, 0)))
+}
@@ -0,0 +1,114 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"github.com/shirou/gopsutil/process"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// top_n_sort 的取值
+const (
+	TopNSortCPU    = "cpu"
+	TopNSortMemory = "memory"
+)
+
+func validateTopN(cfg Config) error {
+	switch cfg.TopNSort {
+	case "", TopNSortCPU, TopNSortMemory:
+	default:
+		return fmt.Errorf("unsupported top_n_sort %q, want %s or %s", cfg.TopNSort, TopNSortCPU, TopNSortMemory)
+	}
+	if cfg.TopN < 0 {
+		return fmt.Errorf("top_n must not be negative, got %d", cfg.TopN)
+	}
+	return nil
+}
+
+// topN 记录每个 PID 上一次的 CPU 时间, 用于计算两次采集之间的 CPU 使用率
+type topN struct {
+	last map[int32]float64
+	at   time.Time
+	// 上一次导出的序列, 键为 name 和 pid
+	seen map[[2]string]bool
+}
+
+// topProcess 是主机上的一个进程
+type topProcess struct {
+	name, pid  string
+	cpuPercent float64
+	rss        uint64
+}
+
+// updateTopN 导出主机上 CPU 使用率或常驻内存最高的 top_n 个进程, 不需要事先声明进程名.
+// CPU 使用率为两次采集之间的平均值, 因此按 CPU 排序时第一个周期不导出
+func (e *Exporter) updateTopN(ctx context.Context) {
+	n, by := e.config.TopN, e.config.TopNSort
+	if n <= 0 {
+		if e.topN != nil {
+			e.topCPU.Reset()
+			e.topRSS.Reset()
+			e.topN = nil
+		}
+		return
+	}
+	processes, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		e.logger.Error(T("log.get_processes"), "error", err)
+		return
+	}
+	now := e.clock.Now()
+	prev := e.topN
+	e.topN = &topN{last: make(map[int32]float64, len(processes)), at: now, seen: make(map[[2]string]bool)}
+
+	all := make([]topProcess, 0, len(processes))
+	for _, p := range processes {
+		name, err := p.NameWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		// 主机上的任何进程都可能出现在这里, 名称需要规范化后才能用作标签值
+		tp := topProcess{name: sanitizeName(name), pid: strconv.Itoa(int(p.Pid))}
+		if times, err := p.TimesWithContext(ctx); err == nil {
+			seconds := times.User + times.System
+			e.topN.last[p.Pid] = seconds
+			if prev != nil {
+				// 新出现的进程计入其启动以来的全部 CPU 时间
+				if elapsed := now.Sub(prev.at).Seconds(); elapsed > 0 {
+					tp.cpuPercent = max(seconds-prev.last[p.Pid], 0) / elapsed * 100
+				}
+			}
+		}
+		if mem, err := p.MemoryInfoWithContext(ctx); err == nil {
+			tp.rss = mem.RSS
+		}
+		all = append(all, tp)
+	}
+	if by == TopNSortMemory {
+		sort.Slice(all, func(i, j int) bool { return all[i].rss > all[j].rss })
+	} else {
+		if prev == nil {
+			return
+		}
+		sort.Slice(all, func(i, j int) bool { return all[i].cpuPercent > all[j].cpuPercent })
+	}
+
+	for _, tp := range all[:min(n, len(all))] {
+		e.topN.seen[[2]string{tp.name, tp.pid}] = true
+		e.topRSS.WithLabelValues(tp.name, tp.pid).Set(float64(tp.rss))
+		if prev != nil {
+			e.topCPU.WithLabelValues(tp.name, tp.pid).Set(tp.cpuPercent)
+		}
+	}
+	// 删除跌出前 N 名或已经退出的进程的序列
+	if prev != nil {
+		for k := range prev.seen {
+			if !e.topN.seen[k] {
+				e.topCPU.DeleteLabelValues(k[0], k[1])
+				e.topRSS.DeleteLabelValues(k[0], k[1])
+			}
+		}
+	}
+}
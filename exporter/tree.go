@@ -0,0 +1,59 @@
+package exporter
+
+import (
+	"context"
+	"github.com/shirou/gopsutil/process"
+)
+
+// descendants 返回 pid 的所有后代进程, 不含 pid 本身
+func descendants(pid int32) []int32 {
+	var all []int32
+	seen := map[int32]bool{pid: true}
+	queue := []int32{pid}
+	for len(queue) > 0 {
+		pids, _ := childPIDs(queue[0])
+		queue = queue[1:]
+		for _, c := range pids {
+			if !seen[c] {
+				seen[c] = true
+				all = append(all, c)
+				queue = append(queue, c)
+			}
+		}
+	}
+	return all
+}
+
+// readTree 读取 pid 所有后代进程的数据和磁盘 I/O 计数, 读取失败的进程 (如已经退出) 跳过
+func readTree(ctx context.Context, pid int32, smaps bool) ([]*Stats, map[int32]process.IOCountersStat) {
+	var kids []*Stats
+	io := make(map[int32]process.IOCountersStat)
+	for _, c := range descendants(pid) {
+		ps, err := readPID(ctx, c, smaps)
+		if err != nil {
+			continue
+		}
+		kids = append(kids, ps)
+		if p, err := process.NewProcessWithContext(ctx, c); err == nil {
+			if cur, err := p.IOCountersWithContext(ctx); err == nil {
+				io[c] = *cur
+			}
+		}
+	}
+	return kids, io
+}
+
+// addTree 把后代进程的 CPU、内存、文件描述符数和线程数加到监控的进程的 s 上
+func addTree(s *Stats, kids []*Stats) {
+	for _, ps := range kids {
+		s.CPUPercent += ps.CPUPercent
+		s.MemoryPercent += ps.MemoryPercent
+		s.MemoryRSS += ps.MemoryRSS
+		s.MemoryVMS += ps.MemoryVMS
+		s.MemorySwap += ps.MemorySwap
+		s.MemoryShared += ps.MemoryShared
+		s.MemorySwapPss += ps.MemorySwapPss
+		s.NumFDs += ps.NumFDs
+		s.NumThreads += ps.NumThreads
+	}
+}
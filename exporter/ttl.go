@@ -0,0 +1,21 @@
+package exporter
+
+import "time"
+
+// expireTargets 删除进程消失超过 series_ttl 的目标的所有序列, 包括 process_up, 调用方需持有 mutex.
+// 进程重新出现时重新创建序列, 计数器从 0 开始. 启动以来从未匹配到进程的目标不会过期
+func (e *Exporter) expireTargets(now time.Time) {
+	ttl := e.config.SeriesTTL
+	if ttl <= 0 {
+		return
+	}
+	for _, name := range e.processNames {
+		st, ok := e.state[name]
+		if !ok || st.expired || len(st.pids) > 0 || st.lastSeen.IsZero() || now.Sub(st.lastSeen) < ttl {
+			continue
+		}
+		e.logger.Info(T("log.series_expired"), "target", name, "last_seen", st.lastSeen)
+		e.deleteTarget(name)
+		st.expired = true
+	}
+}
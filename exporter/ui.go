@@ -0,0 +1,34 @@
+//go:build !noui
+
+package exporter
+
+import (
+	"embed"
+	"html/template"
+	"io/fs"
+	"net/http"
+)
+
+// uiFiles 是 /ui/ 的页面和静态文件, 页面通过 /api/v1/stats 刷新数据
+//
+//go:embed ui
+var uiFiles embed.FS
+
+var uiTemplate = template.Must(template.New("index.html").Funcs(template.FuncMap{
+	"T": func(key string) string { return T(key) },
+}).ParseFS(uiFiles, "ui/index.html"))
+
+// UIHandler 返回 /ui/ 的 http.Handler, 以只读的网页实时显示各监控目标的 PID、CPU、内存、文件描述符、状态和运行时间,
+// 需要同时挂载 APIHandler. 挂载到其他前缀时需配合 http.StripPrefix, 使请求路径以 / 开头
+func UIHandler() http.Handler {
+	static, _ := fs.Sub(uiFiles, "ui")
+	files := http.FileServerFS(static)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" && r.URL.Path != "/index.html" {
+			files.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		uiTemplate.Execute(w, nil)
+	})
+}
@@ -0,0 +1,78 @@
+// 每 2 秒从 /api/v1/stats 读取各目标最近一次采集的数据并刷新表格
+(function () {
+  var body = document.getElementById("processes");
+  var updated = document.getElementById("updated");
+
+  function bytes(n) {
+    var units = ["B", "KiB", "MiB", "GiB", "TiB"];
+    var i = 0;
+    while (n >= 1024 && i < units.length - 1) {
+      n /= 1024;
+      i++;
+    }
+    return n.toFixed(i === 0 ? 0 : 1) + " " + units[i];
+  }
+
+  function duration(seconds) {
+    seconds = Math.max(0, Math.floor(seconds));
+    var d = Math.floor(seconds / 86400);
+    var h = Math.floor(seconds % 86400 / 3600);
+    var m = Math.floor(seconds % 3600 / 60);
+    var s = seconds % 60;
+    if (d > 0) return d + "d " + h + "h";
+    if (h > 0) return h + "h " + m + "m";
+    if (m > 0) return m + "m " + s + "s";
+    return s + "s";
+  }
+
+  function cell(row, text, num) {
+    var td = row.insertCell();
+    td.textContent = text;
+    if (num) td.className = "num";
+  }
+
+  function render(stats) {
+    var rows = document.createDocumentFragment();
+    stats.forEach(function (p) {
+      var row = document.createElement("tr");
+      cell(row, p.process);
+      if (!p.up) {
+        row.className = "down";
+        for (var i = 0; i < 5; i++) cell(row, "-", true);
+        cell(row, body.dataset.down);
+        cell(row, "-", true);
+        rows.appendChild(row);
+        return;
+      }
+      cell(row, p.pid, true);
+      cell(row, p.cpu_percent.toFixed(1), true);
+      cell(row, bytes(p.memory_rss_bytes), true);
+      cell(row, p.num_fds, true);
+      cell(row, p.num_threads, true);
+      cell(row, p.status || "-");
+      cell(row, p.start_time ? duration((Date.now() - Date.parse(p.start_time)) / 1000) : "-", true);
+      rows.appendChild(row);
+    });
+    body.replaceChildren(rows);
+  }
+
+  function refresh() {
+    fetch("../api/v1/stats")
+      .then(function (resp) {
+        if (!resp.ok) throw new Error(resp.status + " " + resp.statusText);
+        return resp.json();
+      })
+      .then(function (r) {
+        render(r.data || []);
+        updated.className = "";
+        updated.textContent = updated.dataset.label + " " + new Date().toLocaleTimeString();
+      })
+      .catch(function (err) {
+        updated.className = "error";
+        updated.textContent = updated.dataset.error + ": " + err.message;
+      });
+  }
+
+  refresh();
+  setInterval(refresh, 2000);
+})();
@@ -0,0 +1,12 @@
+//go:build noui
+
+package exporter
+
+import "net/http"
+
+// UIHandler 在使用 noui 标签构建时对所有请求返回 404
+func UIHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "built without the web UI (noui)", http.StatusNotFound)
+	})
+}
@@ -0,0 +1,42 @@
+package exporter
+
+import (
+	"context"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// unitMainPID 通过 systemctl 查询 systemd unit 的主进程, 没有运行时返回 0
+func unitMainPID(ctx context.Context, unit string) (int32, error) {
+	out, err := exec.CommandContext(ctx, "systemctl", "show", "--property=MainPID", "--value", "--", unit).Output()
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 32)
+	return int32(pid), err
+}
+
+// mainPIDFirst 把 unit 的主进程移到 pids 的最前面, 使其成为单进程指标监控的进程. 查询失败时保持原来的顺序
+func mainPIDFirst(ctx context.Context, unit string, pids []int32) []int32 {
+	main, err := unitMainPID(ctx, unit)
+	if err != nil || main == 0 {
+		return pids
+	}
+	i := slices.Index(pids, main)
+	if i <= 0 {
+		return pids
+	}
+	return append([]int32{main}, slices.Delete(slices.Clone(pids), i, i+1)...)
+}
+
+// UnitTargets 为 -discover.systemd-units 中的每个 unit 返回一个监控目标, 以 unit 名为目标名,
+// 匹配 unit 的 cgroup 中的所有进程并带有 unit 标签
+func UnitTargets(units []string) []TargetConfig {
+	targets := make([]TargetConfig, 0, len(units))
+	for _, u := range units {
+		targets = append(targets, TargetConfig{Name: u, Match: "unit:" + u, Labels: map[string]string{"unit": u}})
+	}
+	return targets
+}
@@ -0,0 +1,23 @@
+package exporter
+
+import (
+	"os"
+	"slices"
+	"strings"
+)
+
+// inUnit 根据 /proc/<pid>/cgroup 判断进程是否属于 systemd unit, unit 的子进程和子 cgroup 中的进程也算
+func inUnit(pid int32, unit string) bool {
+	data, err := os.ReadFile(procPath(pid, "cgroup"))
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		// 如 0::/system.slice/nginx.service 或 1:name=systemd:/system.slice/nginx.service
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) == 3 && slices.Contains(strings.Split(parts[2], "/"), unit) {
+			return true
+		}
+	}
+	return false
+}
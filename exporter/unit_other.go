@@ -0,0 +1,8 @@
+//go:build !linux
+
+package exporter
+
+// inUnit 只在 Linux 上支持 systemd unit
+func inUnit(pid int32, unit string) bool {
+	return false
+}
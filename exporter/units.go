@@ -0,0 +1,60 @@
+package exporter
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// 指标单位
+const (
+	UnitPercent = "percent"
+	UnitRatio   = "ratio"
+	UnitBytes   = "bytes"
+)
+
+// UnitsConfig 控制 CPU 和内存指标的单位, 为空时保持百分比
+type UnitsConfig struct {
+	// percent 或 ratio (0-1)
+	CPU string `yaml:"cpu,omitempty"`
+
+	// percent, ratio (0-1) 或 bytes (常驻内存字节数)
+	Memory string `yaml:"memory,omitempty"`
+}
+
+// cpuGaugeOpts 返回单位对应的 CPU 指标名称 (不含前缀) 和说明, 以及从百分比换算的系数
+func cpuGaugeOpts(unit string) (prometheus.GaugeOpts, float64, error) {
+	switch unit {
+	case "", UnitPercent:
+		return prometheus.GaugeOpts{Name: "cpu_usage_percent", Help: T("help.cpu_percent")}, 1, nil
+	case UnitRatio:
+		return prometheus.GaugeOpts{Name: "cpu_usage_ratio", Help: T("help.cpu_ratio")}, 0.01, nil
+	default:
+		return prometheus.GaugeOpts{}, 0, fmt.Errorf("unsupported cpu unit %q, want %s or %s", unit, UnitPercent, UnitRatio)
+	}
+}
+
+// memGaugeOpts 返回单位对应的内存指标名称 (不含前缀) 和说明
+func memGaugeOpts(unit string) (prometheus.GaugeOpts, error) {
+	switch unit {
+	case "", UnitPercent:
+		return prometheus.GaugeOpts{Name: "memory_usage_percent", Help: T("help.memory_percent")}, nil
+	case UnitRatio:
+		return prometheus.GaugeOpts{Name: "memory_usage_ratio", Help: T("help.memory_ratio")}, nil
+	case UnitBytes:
+		return prometheus.GaugeOpts{Name: "memory_bytes", Help: T("help.memory_bytes")}, nil
+	default:
+		return prometheus.GaugeOpts{}, fmt.Errorf("unsupported memory unit %q, want %s, %s or %s", unit, UnitPercent, UnitRatio, UnitBytes)
+	}
+}
+
+// memValue 按单位返回 s 的内存指标值
+func memValue(unit string, s *Stats) float64 {
+	switch unit {
+	case UnitRatio:
+		return s.MemoryPercent / 100
+	case UnitBytes:
+		return float64(s.MemoryRSS)
+	default:
+		return s.MemoryPercent
+	}
+}
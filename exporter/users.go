@@ -0,0 +1,103 @@
+package exporter
+
+import (
+	"context"
+	"github.com/shirou/gopsutil/process"
+	"os/user"
+	"strconv"
+)
+
+// users 记录按用户聚合时每个 PID 上一次的 CPU 时间和 uid 对应的用户名
+type users struct {
+	last  map[int32]float64
+	names map[int32]string
+	// 上一次采集时有进程的用户
+	seen map[string]bool
+}
+
+// userName 返回进程所属的用户名, 查不到用户名时为 uid
+func (u *users) userName(ctx context.Context, p *process.Process) (string, error) {
+	uids, err := p.UidsWithContext(ctx)
+	if err != nil || len(uids) == 0 {
+		// Windows 等不支持 uid 的平台
+		return p.UsernameWithContext(ctx)
+	}
+	uid := uids[0]
+	if name, ok := u.names[uid]; ok {
+		return name, nil
+	}
+	name := strconv.Itoa(int(uid))
+	if usr, err := user.LookupId(name); err == nil {
+		name = usr.Username
+	}
+	u.names[uid] = name
+	return name, nil
+}
+
+// updateUsers 按所属用户聚合主机上的所有进程, 导出每个用户的进程数、常驻内存和 CPU 时间.
+// 与 group_cpu_seconds_total 一样, 新出现的进程计入其全部 CPU 时间, 已退出的进程最后一个周期的 CPU 时间会丢失
+func (e *Exporter) updateUsers(ctx context.Context) {
+	if !e.config.ByUser {
+		if e.users != nil {
+			e.userProcs.Reset()
+			e.userRSS.Reset()
+			e.userCPU.Reset()
+			e.users = nil
+		}
+		return
+	}
+	processes, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		e.logger.Error(T("log.get_processes"), "error", err)
+		return
+	}
+	first := e.users == nil
+	if first {
+		e.users = &users{last: make(map[int32]float64), names: make(map[int32]string)}
+	}
+
+	counts := make(map[string]int)
+	rss := make(map[string]uint64)
+	cur := make(map[int32]float64, len(processes))
+	for _, p := range processes {
+		name, err := e.users.userName(ctx, p)
+		if err != nil {
+			continue
+		}
+		counts[name]++
+		if mem, err := p.MemoryInfoWithContext(ctx); err == nil {
+			rss[name] += mem.RSS
+		}
+		times, err := p.TimesWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		seconds := times.User + times.System
+		cur[p.Pid] = seconds
+		delta := seconds
+		if last, ok := e.users.last[p.Pid]; ok {
+			delta = seconds - last
+		} else if first {
+			// 第一次采集只记录基线
+			delta = 0
+		}
+		e.userCPU.WithLabelValues(name).Add(max(delta, 0))
+	}
+	e.users.last = cur
+
+	for name, n := range counts {
+		e.userProcs.WithLabelValues(name).Set(float64(n))
+		e.userRSS.WithLabelValues(name).Set(float64(rss[name]))
+	}
+	// 删除已经没有进程的用户的序列, CPU 时间的计数器保留
+	for name := range e.users.seen {
+		if counts[name] == 0 {
+			e.userProcs.DeleteLabelValues(name)
+			e.userRSS.DeleteLabelValues(name)
+		}
+	}
+	e.users.seen = make(map[string]bool, len(counts))
+	for name := range counts {
+		e.users.seen[name] = true
+	}
+}
@@ -0,0 +1,238 @@
+package exporter
+
+import (
+	"context"
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/net"
+	"github.com/shirou/gopsutil/process"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"maps"
+	"sync"
+	"time"
+)
+
+// readJob 是交给 worker 的一个目标, 在持有互斥锁时从当前配置取出
+type readJob struct {
+	name   string
+	m      *matcher
+	target TargetConfig
+	// 是否匹配内核线程
+	kernelThreads bool
+	// 是否从 smaps 读取 swap
+	smaps bool
+	// 是否按 CPU 统计各线程的 CPU 时间
+	perCPU bool
+	// 不为 nil 时计算可执行文件的校验和
+	binaries *binarySums
+	// due 为 false 时目标处于最小采集间隔内, 只查找 PID
+	due bool
+	// 上一周期匹配到的 PID, rescan 为 false 时先检查它们是否仍然有效
+	cached []int32
+	rescan bool
+}
+
+// procEntry 是一个周期开始时进程表中的一个进程, 所有目标共用
+type procEntry struct {
+	p    *process.Process
+	name string
+}
+
+// targetRead 是一个目标在一个周期内从 /proc 读取的原始数据. worker 不访问 Exporter 的状态,
+// 之后由 updateTarget 在持有互斥锁时据此更新指标
+type targetRead struct {
+	pattern string
+	pids    []int32
+	due     bool
+	// 是否扫描了进程表
+	scanned bool
+	// 读取的耗时, 计入 process_exporter_collect_duration_seconds
+	duration time.Duration
+
+	p   *process.Process
+	err error
+
+	cpuPercent     float64
+	cpuErr         error
+	memPercent     float32
+	memPercentErr  error
+	memInfo        *process.MemoryInfoStat
+	memInfoErr     error
+	shared         uint64
+	sharedErr      error
+	smaps          bool
+	swap           uint64
+	swapPss        uint64
+	smapsErr       error
+	times          *cpu.TimesStat
+	timesErr       error
+	kids           []*Stats
+	kidsIO         map[int32]process.IOCountersStat
+	io             *process.IOCountersStat
+	ioErr          error
+	fds            int32
+	fdsErr         error
+	threads        int32
+	threadsErr     error
+	ctxSwitches    *process.NumCtxSwitchesStat
+	ctxSwitchesErr error
+	pageFaults     *process.PageFaultsStat
+	pageFaultsErr  error
+	maxFDs         float64
+	maxFDsErr      error
+	fdTypes        map[string]int
+	fdTypesErr     error
+	conns          []net.ConnectionStat
+	connsErr       error
+	created        int64
+	createdErr     error
+	status         string
+	affinity       string
+	affinityErr    error
+	threadCPU      map[int32]threadCPU
+	threadCPUErr   error
+	priority       int64
+	nice           int64
+	priorityErr    error
+	oomScore       int64
+	oomScoreAdj    int64
+	oomErr         error
+	labels         map[string]string
+	binary         *binaryRead
+	binaryErr      error
+}
+
+// processTable 读取进程表及每个进程的进程名, 失败时返回 nil
+func (e *Exporter) processTable(ctx context.Context) []procEntry {
+	e.self.tableScans.Inc()
+	processes, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		e.logger.Error(T("log.get_processes"), "error", err)
+		return nil
+	}
+	procs := make([]procEntry, 0, len(processes))
+	for _, p := range processes {
+		// 进程可能已经退出
+		if name, err := p.NameWithContext(ctx); err == nil {
+			procs = append(procs, procEntry{p: p, name: name})
+		}
+	}
+	return procs
+}
+
+// readTargets 用最多 workers 个 goroutine 并发读取所有目标, 不持有互斥锁.
+// 每个目标的读取有 timeout 的期限, 超时或在周期的期限前没有开始读取的目标结果为 nil
+func (e *Exporter) readTargets(ctx context.Context, jobs []readJob, workers int, timeout time.Duration) map[string]*targetRead {
+	ctx, span := e.tracer.Start(ctx, "read-targets")
+	defer span.End()
+
+	// 进程表只在有目标需要时读取一次, 而不是每个目标读取一次
+	procs := sync.OnceValue(func() []procEntry { return e.processTable(ctx) })
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	reads := make(map[string]*targetRead, len(jobs))
+	for _, j := range jobs {
+		reads[j.name] = nil
+	}
+	sem := make(chan struct{}, workers)
+dispatch:
+	for _, j := range jobs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break dispatch
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			tctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			// 不响应 ctx 的读取 (如卡在 D 状态的进程) 超时后继续在后台运行, 结果被丢弃
+			ch := make(chan *targetRead, 1)
+			go func() { ch <- e.readTarget(tctx, j, procs) }()
+			var r *targetRead
+			select {
+			case r = <-ch:
+			case <-tctx.Done():
+			}
+			if tctx.Err() != nil {
+				r = nil
+			}
+			mu.Lock()
+			reads[j.name] = r
+			mu.Unlock()
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	return maps.Clone(reads)
+}
+
+// readTarget 查找目标的 PID, 目标需要采集时读取监控的进程的数据
+func (e *Exporter) readTarget(ctx context.Context, j readJob, procs func() []procEntry) *targetRead {
+	ctx, span := e.tracer.Start(ctx, "read-target", trace.WithAttributes(attribute.String("target", j.name)))
+	defer span.End()
+
+	start := e.clock.Now()
+	r := &targetRead{pattern: j.m.pattern}
+	if !j.rescan && cachedPIDs(ctx, j) {
+		r.pids = j.cached
+	} else {
+		r.pids, r.scanned = e.findPIDs(ctx, j, procs()), true
+	}
+	if len(r.pids) == 0 || !j.due {
+		return r
+	}
+	r.due = true
+	p, err := process.NewProcessWithContext(ctx, r.pids[0])
+	if err != nil {
+		r.err = err
+		return r
+	}
+	r.p = p
+	r.cpuPercent, r.cpuErr = p.CPUPercentWithContext(ctx)
+	r.memPercent, r.memPercentErr = p.MemoryPercentWithContext(ctx)
+	r.memInfo, r.memInfoErr = p.MemoryInfoWithContext(ctx)
+	r.shared, r.sharedErr = sharedMemory(ctx, p)
+	if j.smaps {
+		r.smaps = true
+		r.swap, r.swapPss, r.smapsErr = readSmaps(p.Pid)
+	}
+	r.times, r.timesErr = p.TimesWithContext(ctx)
+	if j.target.IncludeChildren {
+		r.kids, r.kidsIO = readTree(ctx, p.Pid, j.smaps)
+	}
+	r.io, r.ioErr = p.IOCountersWithContext(ctx)
+	r.fds, r.fdsErr = p.NumFDsWithContext(ctx)
+	r.threads, r.threadsErr = p.NumThreadsWithContext(ctx)
+	r.ctxSwitches, r.ctxSwitchesErr = p.NumCtxSwitchesWithContext(ctx)
+	r.pageFaults, r.pageFaultsErr = p.PageFaultsWithContext(ctx)
+	r.maxFDs, r.maxFDsErr = maxFDs(ctx, p)
+	r.fdTypes, r.fdTypesErr = countFDTypes(p.Pid)
+	r.conns, r.connsErr = p.ConnectionsWithContext(ctx)
+	r.created, r.createdErr = p.CreateTimeWithContext(ctx)
+	r.status, _ = p.StatusWithContext(ctx)
+	r.affinity, r.affinityErr = readAffinity(p.Pid)
+	if j.perCPU {
+		r.threadCPU, r.threadCPUErr = readThreadCPU(p.Pid)
+	}
+	r.priority, r.nice, r.priorityErr = readPriority(p.Pid)
+	r.oomScore, r.oomScoreAdj, r.oomErr = readOOMScore(p.Pid)
+	r.labels = readDynamicLabels(ctx, j.target, p)
+	if j.binaries != nil {
+		r.binary, r.binaryErr = j.binaries.read(p.Pid)
+	}
+	r.duration = e.clock.Now().Sub(start)
+	return r
+}
@@ -0,0 +1,27 @@
+package exporter
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+)
+
+// hostZombies 统计主机上处于僵尸状态的进程数, 读取 /proc/<pid>/stat 中进程名之后的状态字段
+func hostZombies() (int, error) {
+	files, err := filepath.Glob(filepath.Join(procRoot, "[0-9]*", "stat"))
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, name := range files {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		// 进程名可能包含空格和括号, 状态在最后一个 ")" 之后
+		if i := bytes.LastIndexByte(data, ')'); i >= 0 && bytes.HasPrefix(data[i+1:], []byte(" Z")) {
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/qishu321/exporter/exporter"
+	"os"
+)
+
+// runGenerate 实现 generate 子命令: generate dashboard 输出 Grafana 仪表盘的 JSON,
+// generate rules 输出 Prometheus 告警规则的 YAML, 其中的监控目标和指标名来自配置文件和命令行
+func runGenerate(args []string) int {
+	if len(args) == 0 || (args[0] != "dashboard" && args[0] != "rules") {
+		fmt.Fprintln(os.Stderr, exporter.T("cli.generate_usage"))
+		return 2
+	}
+	fs := flag.NewFlagSet("generate "+args[0], flag.ExitOnError)
+	configFile := fs.String("config.file", "", exporter.T("flag.config.file"))
+	fs.Parse(args[1:])
+
+	cfg := &exporter.Config{}
+	if *configFile != "" {
+		var err error
+		if cfg, err = exporter.LoadConfig(*configFile); err != nil {
+			fmt.Fprintln(os.Stderr, exporter.T("cli.generate", err))
+			return 1
+		}
+	}
+	cfg.Processes = append(cfg.Processes, fs.Args()...)
+
+	generate := exporter.GenerateDashboard
+	if args[0] == "rules" {
+		generate = exporter.GenerateRules
+	}
+	out, err := generate(*cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, exporter.T("cli.generate", err))
+		return 1
+	}
+	os.Stdout.Write(out)
+	return 0
+}
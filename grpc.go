@@ -0,0 +1,91 @@
+//go:build !nogrpc
+
+package main
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative statspb/stats.proto
+
+import (
+	"context"
+	"github.com/qishu321/exporter/exporter"
+	"github.com/qishu321/exporter/statspb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"log/slog"
+	"net"
+	"slices"
+	"time"
+)
+
+// statsServer 实现 ProcessStatsService, 每个采集周期向客户端推送一次 exp.Snapshot()
+type statsServer struct {
+	statspb.UnimplementedProcessStatsServiceServer
+	exp *exporter.Exporter
+}
+
+func (s *statsServer) WatchProcessStats(req *statspb.WatchProcessStatsRequest, stream statspb.ProcessStatsService_WatchProcessStatsServer) error {
+	send := func() error {
+		return stream.Send(statsUpdate(s.exp.Snapshot(), req.Targets))
+	}
+	if err := send(); err != nil {
+		return err
+	}
+	t := time.NewTicker(s.exp.CycleInterval())
+	defer t.Stop()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-t.C:
+			if err := send(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// statsUpdate 把 snap 转换为一次推送, targets 不为空时只保留其中的目标
+func statsUpdate(snap []exporter.Stats, targets []string) *statspb.ProcessStatsUpdate {
+	update := &statspb.ProcessStatsUpdate{Time: timestamppb.Now()}
+	for _, s := range snap {
+		if len(targets) > 0 && !slices.Contains(targets, s.Process) {
+			continue
+		}
+		p := &statspb.ProcessStats{
+			Process:           s.Process,
+			Up:                s.PID != 0,
+			Pid:               s.PID,
+			CpuPercent:        s.CPUPercent,
+			MemoryPercent:     s.MemoryPercent,
+			MemoryRssBytes:    s.MemoryRSS,
+			MemoryVmsBytes:    s.MemoryVMS,
+			MemorySwapBytes:   s.MemorySwap,
+			MemorySharedBytes: s.MemoryShared,
+			NumFds:            s.NumFDs,
+			NumThreads:        s.NumThreads,
+			Status:            s.Status,
+			Incomplete:        s.Incomplete,
+		}
+		if !s.Time.IsZero() {
+			p.Time = timestamppb.New(s.Time)
+		}
+		update.Processes = append(update.Processes, p)
+	}
+	return update
+}
+
+// startGRPC 在后台通过 ln 提供 gRPC 流式接口直到 ctx 被取消, 停止时直接断开进行中的流
+func startGRPC(ctx context.Context, ln net.Listener, exp *exporter.Exporter, logger *slog.Logger) error {
+	srv := grpc.NewServer()
+	statspb.RegisterProcessStatsServiceServer(srv, &statsServer{exp: exp})
+	go func() {
+		<-ctx.Done()
+		srv.Stop()
+	}()
+	logger.Info(exporter.T("cli.grpc_started"), "address", ln.Addr().String())
+	go func() {
+		if err := srv.Serve(ln); err != nil {
+			logger.Error(exporter.T("cli.grpc"), "error", err)
+		}
+	}()
+	return nil
+}
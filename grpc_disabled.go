@@ -0,0 +1,16 @@
+//go:build nogrpc
+
+package main
+
+import (
+	"context"
+	"errors"
+	"github.com/qishu321/exporter/exporter"
+	"log/slog"
+	"net"
+)
+
+// startGRPC 在使用 nogrpc 标签构建时总是返回错误
+func startGRPC(ctx context.Context, ln net.Listener, exp *exporter.Exporter, logger *slog.Logger) error {
+	return errors.New("built without gRPC support (nogrpc)")
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/qishu321/exporter/exporter"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// runHealthcheck 实现 healthcheck 子命令: 请求本机运行中实例的 /-/healthy, 健康时返回 0,
+// 用于镜像中没有 curl 时的 Docker HEALTHCHECK 和 Kubernetes exec 探针
+func runHealthcheck(args []string) int {
+	fs := flag.NewFlagSet("healthcheck", flag.ExitOnError)
+	listenAddress := fs.String("web.listen-address", ":9100", exporter.T("flag.web.listen-address"))
+	listenFamily := fs.String("web.listen-family", familyDual, exporter.T("flag.web.listen-family"))
+	timeout := fs.Duration("timeout", 3*time.Second, exporter.T("flag.healthcheck.timeout"))
+	fs.Parse(args)
+
+	if err := healthcheck(*listenFamily, *listenAddress, *timeout); err != nil {
+		fmt.Fprintln(os.Stderr, exporter.T("cli.healthcheck", err))
+		return 1
+	}
+	return 0
+}
+
+func healthcheck(family, addr string, timeout time.Duration) error {
+	family, addr = listenTarget(family, addr)
+	transport := &http.Transport{}
+	url := "http://unix/-/healthy"
+	if family == familyUnix {
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", addr)
+		}
+	} else {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return err
+		}
+		// 监听所有地址时请求本机回环地址
+		if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+			host = "127.0.0.1"
+			if family == familyIPv6 {
+				host = "::1"
+			}
+		}
+		url = "http://" + net.JoinHostPort(host, port) + "/-/healthy"
+	}
+
+	client := &http.Client{Transport: transport, Timeout: timeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/qishu321/exporter/exporter"
+	"io/fs"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// runLeaderElection 每个周期尝试获得 lockFile 的锁, 获得后把 exp 切换为主实例. 调用前 exp 应已设置为备用实例,
+// 备用实例不采集数据, 只输出 exporter 自身的指标. 锁一直持有到进程退出.
+// stateFile 不为空时, 接管前先从中恢复上一个主实例的累计值, 之后每个周期把自己的累计值写入其中
+func runLeaderElection(ctx context.Context, exp *exporter.Exporter, lockFile, stateFile string, logger *slog.Logger) {
+	t := time.NewTicker(exp.CycleInterval())
+	defer t.Stop()
+	for {
+		f, err := tryLock(lockFile)
+		if err == nil {
+			go func() {
+				<-ctx.Done()
+				f.Close()
+			}()
+			// 记录持有锁的进程, 便于排查
+			f.Truncate(0)
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			if stateFile != "" {
+				loadHandover(exp, stateFile, logger)
+			}
+			exp.SetActive(true)
+			logger.Info(exporter.T("cli.leader"), "lock_file", lockFile)
+			if stateFile != "" {
+				saveHandover(ctx, exp, stateFile, logger)
+			}
+			return
+		}
+		logger.Debug(exporter.T("cli.standby"), "lock_file", lockFile, "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// loadHandover 从 stateFile 恢复上一个主实例的累计值, 文件不存在时什么也不做
+func loadHandover(exp *exporter.Exporter, stateFile string, logger *slog.Logger) {
+	data, err := os.ReadFile(stateFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	if err == nil {
+		err = exp.ImportState(data)
+	}
+	if err != nil {
+		logger.Warn(exporter.T("cli.handover_load"), "state_file", stateFile, "error", err)
+		return
+	}
+	logger.Info(exporter.T("cli.handover_loaded"), "state_file", stateFile)
+}
+
+// saveHandover 每个周期把累计值写入 stateFile, 直到 ctx 被取消. 先写临时文件再改名, 备用实例不会读到写了一半的文件
+func saveHandover(ctx context.Context, exp *exporter.Exporter, stateFile string, logger *slog.Logger) {
+	t := time.NewTicker(exp.CycleInterval())
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		writeHandover(exp, stateFile, logger)
+	}
+}
+
+// writeHandover 把累计值写入 stateFile
+func writeHandover(exp *exporter.Exporter, stateFile string, logger *slog.Logger) {
+	data, err := exp.ExportState()
+	if err == nil {
+		tmp := stateFile + ".tmp"
+		if err = os.WriteFile(tmp, data, 0o644); err == nil {
+			err = os.Rename(tmp, stateFile)
+		}
+	}
+	if err != nil {
+		logger.Warn(exporter.T("cli.handover_save"), "state_file", stateFile, "error", err)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/qishu321/exporter/exporter"
+	"log/slog"
+	"path/filepath"
+	"testing"
+)
+
+func newHandoverExporter(t *testing.T) *exporter.Exporter {
+	t.Helper()
+	exp, err := exporter.New(exporter.Opts{
+		Config:     exporter.Config{Processes: []string{"ho-a"}},
+		Registerer: prometheus.NewRegistry(),
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return exp
+}
+
+// 主实例写入的状态文件由接管的实例原样恢复
+func TestHandoverStateFile(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	a := newHandoverExporter(t)
+	seed := `{"counters":{"starts_total":[{"labels":{"process":"ho-a"},"value":3}],` +
+		`"cpu_core_seconds_total":[{"labels":{"process":"ho-a","cpu":"1"},"value":1.5}]}}`
+	if err := a.ImportState([]byte(seed)); err != nil {
+		t.Fatalf("ImportState: %v", err)
+	}
+	writeHandover(a, stateFile, logger)
+
+	b := newHandoverExporter(t)
+	loadHandover(b, stateFile, logger)
+	want, _ := a.ExportState()
+	got, _ := b.ExportState()
+	if !bytes.Equal(got, want) {
+		t.Fatalf("state after loadHandover = %s, want %s", got, want)
+	}
+}
+
+// 状态文件不存在时什么也不恢复
+func TestLoadHandoverMissingFile(t *testing.T) {
+	b := newHandoverExporter(t)
+	want, _ := b.ExportState()
+	loadHandover(b, filepath.Join(t.TempDir(), "missing.json"), slog.New(slog.DiscardHandler))
+	if got, _ := b.ExportState(); !bytes.Equal(got, want) {
+		t.Fatalf("state = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/qishu321/exporter/exporter"
+	"os"
+	"text/tabwriter"
+)
+
+// runList 实现 list 子命令: 列出主机上的进程, 便于编写匹配规则
+func runList(args []string) int {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	match := fs.String("match", "", exporter.T("flag.list.match"))
+	fs.Parse(args)
+
+	infos, err := exporter.ListProcesses(context.Background(), *match)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, exporter.T("cli.list", err))
+		return 1
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, exporter.T("list.header"))
+	for _, p := range infos {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", p.PID, p.Name, p.User, p.Cmdline)
+	}
+	if err := tw.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, exporter.T("cli.list", err))
+		return 1
+	}
+	return 0
+}
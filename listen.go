@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// 监听地址族
+const (
+	familyDual = "dual"
+	familyIPv4 = "ipv4"
+	familyIPv6 = "ipv6"
+	// addr 为 unix socket 的路径
+	familyUnix = "unix"
+)
+
+// unixPrefix 开头的监听地址是 unix socket 的路径, 如 unix:///run/process-exporter.sock
+const unixPrefix = "unix://"
+
+// listenTarget 返回实际的地址族和地址, 监听地址以 unix:// 开头时等价于 -web.listen-family unix
+func listenTarget(family, addr string) (string, string) {
+	if path, ok := strings.CutPrefix(addr, unixPrefix); ok {
+		return familyUnix, path
+	}
+	return family, addr
+}
+
+// listen 按地址族监听 addr. addr 可以是 ":9100"、"0.0.0.0:9100"、"[::]:9100",
+// 也可以是带 zone 的 IPv6 链路本地地址, 如 "[fe80::1%eth0]:9100".
+// family 为 unix 时 addr 是 socket 文件的路径, 上次运行残留的文件会先被删除
+func listen(family, addr string) (net.Listener, error) {
+	family, addr = listenTarget(family, addr)
+	network := "tcp"
+	switch family {
+	case familyDual, "":
+	case familyIPv4:
+		network = "tcp4"
+	case familyIPv6:
+		network = "tcp6"
+	case familyUnix:
+		network = "unix"
+		if err := os.Remove(addr); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported address family %q, want %s, %s, %s or %s", family, familyDual, familyIPv4, familyIPv6, familyUnix)
+	}
+	return net.Listen(network, addr)
+}
+
+// systemdListener 返回 systemd socket 激活 (LISTEN_PID 和 LISTEN_FDS) 传入的第一个 socket,
+// 多余的 socket 被关闭. 传入的 fd 从 3 开始
+func systemdListener() (net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, errors.New("not started by systemd socket activation (LISTEN_PID not set)")
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n < 1 {
+		return nil, errors.New("no sockets passed by systemd (LISTEN_FDS not set)")
+	}
+	// 不传给子进程 (如 check_command)
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+	const firstFD = 3
+	for fd := firstFD + 1; fd < firstFD+n; fd++ {
+		os.NewFile(uintptr(fd), "LISTEN_FD_"+strconv.Itoa(fd)).Close()
+	}
+	f := os.NewFile(firstFD, "LISTEN_FD_3")
+	defer f.Close()
+	return net.FileListener(f)
+}
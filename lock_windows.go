@@ -0,0 +1,23 @@
+//go:build windows
+
+package main
+
+import (
+	"golang.org/x/sys/windows"
+	"os"
+)
+
+// tryLock 尝试以非阻塞方式获得 path 的排它锁, 已被其他进程持有时返回错误.
+// 锁在进程退出时由系统释放
+func tryLock(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	ol := new(windows.Overlapped)
+	if err := windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, ol); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
@@ -1,160 +1,527 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"github.com/shirou/gopsutil/process"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/config"
+	"github.com/prometheus/exporter-toolkit/web"
+	"github.com/qishu321/exporter/exporter"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"strings"
-	"sync"
 	"time"
 )
 
-var (
-	cpuUsage = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Name: "Cpuinfo",
-		Help: "CPU使用率",
-	}, []string{"process"})
+// version 在构建时通过 -ldflags "-X main.version=v1.2.3" 设置
+var version = "dev"
 
-	memUsage = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Name: "Meminfo",
-		Help: "内存使用率",
-	}, []string{"process"})
+func main() {
+	// 先根据环境变量确定语言, 以便参数说明也能本地化
+	exporter.SetLocale(exporter.DetectLocale())
 
-	pidUsage = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Name: "Pidinfo",
-		Help: "进程pid",
-	}, []string{"process"})
+	// 子命令, 要监控同名进程时可以写成 ./process -- list
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "list":
+			os.Exit(runList(os.Args[2:]))
+		case "top":
+			os.Exit(runTop(os.Args[2:]))
+		case "service":
+			os.Exit(runService(os.Args[2:]))
+		case "healthcheck":
+			os.Exit(runHealthcheck(os.Args[2:]))
+		case "generate":
+			os.Exit(runGenerate(os.Args[2:]))
+		case "check-config":
+			os.Exit(runCheckConfig(os.Args[2:]))
+		}
+	}
 
-	// 使用互斥锁确保在更新指标时不被同时执行
-	mutex sync.Mutex
+	var plugins []string
+	flag.Func("plugin", exporter.T("flag.plugin"), func(s string) error {
+		plugins = append(plugins, s)
+		return nil
+	})
+	var matches []string
+	flag.Func("process.match", exporter.T("flag.process.match"), func(s string) error {
+		matches = append(matches, s)
+		return nil
+	})
+	var excludes []string
+	flag.Func("process.exclude", exporter.T("flag.process.exclude"), func(s string) error {
+		excludes = append(excludes, s)
+		return nil
+	})
+	listenAddress := flag.String("web.listen-address", ":9100", exporter.T("flag.web.listen-address"))
+	telemetryPath := flag.String("web.telemetry-path", "/metrics", exporter.T("flag.web.telemetry-path"))
+	listenFamily := flag.String("web.listen-family", familyDual, exporter.T("flag.web.listen-family"))
+	enablePprof := flag.Bool("web.enable-pprof", false, exporter.T("flag.web.enable-pprof"))
+	enableAdminAPI := flag.Bool("web.enable-admin-api", false, exporter.T("flag.web.enable-admin-api"))
+	systemdSocket := flag.Bool("web.systemd-socket", false, exporter.T("flag.web.systemd-socket"))
+	webConfig := flag.String("web.config.file", "", exporter.T("flag.web.config.file"))
+	configFile := flag.String("config.file", "", exporter.T("flag.config.file"))
+	cpuUnit := flag.String("units.cpu", "", exporter.T("flag.units.cpu"))
+	memUnit := flag.String("units.memory", "", exporter.T("flag.units.memory"))
+	metricsNamespace := flag.String("metrics.namespace", "", exporter.T("flag.metrics.namespace"))
+	seriesTTL := flag.Duration("metrics.series-ttl", 0, exporter.T("flag.metrics.series-ttl"))
+	legacyNames := flag.Bool("metrics.legacy-names", false, exporter.T("flag.metrics.legacy-names"))
+	discoverDocker := flag.Bool("discover.docker", false, exporter.T("flag.discover.docker"))
+	dockerSocket := flag.String("discover.docker-socket", "", exporter.T("flag.discover.docker-socket"))
+	systemdUnits := flag.String("discover.systemd-units", "", exporter.T("flag.discover.systemd-units"))
+	kubeletURL := flag.String("discover.kubelet-url", "", exporter.T("flag.discover.kubelet-url"))
+	kubeletInsecure := flag.Bool("discover.kubelet-insecure", false, exporter.T("flag.discover.kubelet-insecure"))
+	byUser := flag.Bool("collector.by-user", false, exporter.T("flag.collector.by-user"))
+	topN := flag.Int("collector.topn", 0, exporter.T("flag.collector.topn"))
+	topNSort := flag.String("collector.topn.sort", "", exporter.T("flag.collector.topn.sort"))
+	sampleInterval := flag.Duration("collector.sample-interval", 0, exporter.T("flag.collector.sample-interval"))
+	gpu := flag.Bool("collector.gpu", false, exporter.T("flag.collector.gpu"))
+	smaps := flag.Bool("collector.smaps", false, exporter.T("flag.collector.smaps"))
+	binaryHash := flag.Bool("collector.binary-hash", false, exporter.T("flag.collector.binary-hash"))
+	host := flag.Bool("collector.host", false, exporter.T("flag.collector.host"))
+	perCPU := flag.Bool("collector.per-cpu", false, exporter.T("flag.collector.per-cpu"))
+	textfileDir := flag.String("collector.textfile.directory", "", exporter.T("flag.collector.textfile.directory"))
+	procfs := flag.String("path.procfs", "/proc", exporter.T("flag.path.procfs"))
+	kernelThreads := flag.Bool("kernel-threads", false, exporter.T("flag.kernel-threads"))
+	locale := flag.String("locale", "", exporter.T("flag.locale"))
+	tracingEndpoint := flag.String("tracing.endpoint", "", exporter.T("flag.tracing.endpoint"))
+	reportConsole := flag.Bool("report.console", false, exporter.T("flag.report.console"))
+	reportInterval := flag.Duration("report.interval", 5*time.Second, exporter.T("flag.report.interval"))
+	nativeHistograms := flag.Bool("metrics.native-histograms", false, exporter.T("flag.metrics.native-histograms"))
+	taskstats := flag.Bool("collector.taskstats", false, exporter.T("flag.collector.taskstats"))
+	collectInterval := flag.Duration("collector.interval", 0, exporter.T("flag.collector.interval"))
+	onScrape := flag.Bool("collector.on-scrape", false, exporter.T("flag.collector.on-scrape"))
+	cacheTTL := flag.Duration("collector.cache-ttl", 0, exporter.T("flag.collector.cache-ttl"))
+	dumpDir := flag.String("dump.dir", os.TempDir(), exporter.T("flag.dump.dir"))
+	leaderLock := flag.String("leader.lock-file", "", exporter.T("flag.leader.lock-file"))
+	leaderState := flag.String("leader.state-file", "", exporter.T("flag.leader.state-file"))
+	stateFile := flag.String("state.file", "", exporter.T("flag.state.file"))
+	dryRunMode := flag.Bool("dry-run", false, exporter.T("flag.dry-run"))
+	tracingInsecure := flag.Bool("tracing.insecure", false, exporter.T("flag.tracing.insecure"))
+	otlpEndpoint := flag.String("otlp.endpoint", "", exporter.T("flag.otlp.endpoint"))
+	otlpProtocol := flag.String("otlp.protocol", "grpc", exporter.T("flag.otlp.protocol"))
+	otlpInsecure := flag.Bool("otlp.insecure", false, exporter.T("flag.otlp.insecure"))
+	otlpInterval := flag.Duration("otlp.interval", 0, exporter.T("flag.otlp.interval"))
+	grpcListenAddress := flag.String("grpc.listen-address", "", exporter.T("flag.grpc.listen-address"))
+	remoteWriteURL := flag.String("remote-write.url", "", exporter.T("flag.remote-write.url"))
+	remoteWriteInterval := flag.Duration("remote-write.interval", 0, exporter.T("flag.remote-write.interval"))
+	remoteWriteJob := flag.String("remote-write.job", "process-exporter", exporter.T("flag.remote-write.job"))
+	remoteWriteToken := flag.String("remote-write.bearer-token-file", "", exporter.T("flag.remote-write.bearer-token-file"))
+	remoteWriteCA := flag.String("remote-write.tls.ca-file", "", exporter.T("flag.remote-write.tls.ca-file"))
+	remoteWriteCert := flag.String("remote-write.tls.cert-file", "", exporter.T("flag.remote-write.tls.cert-file"))
+	remoteWriteKey := flag.String("remote-write.tls.key-file", "", exporter.T("flag.remote-write.tls.key-file"))
+	remoteWriteInsecure := flag.Bool("remote-write.tls.insecure-skip-verify", false, exporter.T("flag.remote-write.tls.insecure-skip-verify"))
+	shutdownTimeout := flag.Duration("web.shutdown-timeout", 10*time.Second, exporter.T("flag.web.shutdown-timeout"))
+	once := flag.Bool("once", false, exporter.T("flag.once"))
+	onceFormat := flag.String("format", "text", exporter.T("flag.format"))
+	pushgatewayURL := flag.String("pushgateway.url", "", exporter.T("flag.pushgateway.url"))
+	pushgatewayJob := flag.String("pushgateway.job", "process-exporter", exporter.T("flag.pushgateway.job"))
+	pushgatewayGrouping := flag.String("pushgateway.grouping", "", exporter.T("flag.pushgateway.grouping"))
+	otlpOnly := flag.Bool("otlp.disable-scrape", false, exporter.T("flag.otlp.disable-scrape"))
+	logFormat := flag.String("log.format", exporter.LogFormatLogfmt, exporter.T("flag.log.format"))
+	logLevel := &slog.LevelVar{}
+	flag.TextVar(logLevel, "log.level", logLevel, exporter.T("flag.log.level"))
+	logDedup := flag.Duration("log.dedup-interval", 5*time.Minute, exporter.T("flag.log.dedup-interval"))
+	logEventLog := flag.String("log.eventlog", "", exporter.T("flag.log.eventlog"))
+	serviceInstall := flag.Bool("service.install", false, exporter.T("flag.service.install"))
+	serviceRun := flag.Bool("service.run", false, exporter.T("flag.service.run"))
+	showVersion := flag.Bool("version", false, exporter.T("flag.version"))
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), exporter.T("cli.usage", os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
 
-	// 存储每个进程上次更新的时间戳
-	lastUpdate = make(map[string]time.Time)
-)
+	// -service.install 与 service install 子命令相同, 其余参数作为服务启动时的参数
+	if *serviceInstall {
+		args := []string{"install", "-service.run"}
+		for _, arg := range os.Args[1:] {
+			if name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "="); name != "service.install" && name != "service.run" {
+				args = append(args, arg)
+			}
+		}
+		os.Exit(runService(args))
+	}
 
-func init() {
-	// Register metrics once
-	prometheus.MustRegister(cpuUsage, memUsage, pidUsage)
-}
+	build := currentBuildInfo()
+	if *showVersion {
+		fmt.Println(build)
+		return
+	}
 
-func main() {
-	processNames := os.Args[1:]
-	if len(processNames) == 0 {
-		fmt.Println("Usage: go run main.go <process1> <process2> ... <processN>")
+	logger, err := exporter.NewLogger(os.Stderr, exporter.LogOpts{
+		Format:         *logFormat,
+		Level:          logLevel,
+		DedupInterval:  *logDedup,
+		EventLogSource: *logEventLog,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// loadConfig 读取配置文件并应用命令行参数, 启动和重新加载时使用
+	loadConfig := func() (*exporter.Config, error) {
+		cfg := &exporter.Config{}
+		if *configFile != "" {
+			var err error
+			cfg, err = exporter.LoadConfig(*configFile)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if *locale != "" {
+			cfg.Locale = *locale
+		}
+		if *cpuUnit != "" {
+			cfg.Units.CPU = *cpuUnit
+		}
+		if *memUnit != "" {
+			cfg.Units.Memory = *memUnit
+		}
+		if *metricsNamespace != "" {
+			cfg.Namespace = *metricsNamespace
+		}
+		if *seriesTTL > 0 {
+			cfg.SeriesTTL = *seriesTTL
+		}
+		if *legacyNames {
+			cfg.LegacyNames = true
+		}
+		if *collectInterval > 0 {
+			cfg.CollectInterval = *collectInterval
+		}
+		if *discoverDocker {
+			cfg.Docker = true
+		}
+		if *dockerSocket != "" {
+			cfg.DockerSocket = *dockerSocket
+		}
+		if *kubeletURL != "" {
+			cfg.KubeletURL = exporter.Secret(*kubeletURL)
+		}
+		if *kubeletInsecure {
+			cfg.KubeletInsecure = true
+		}
+		if *byUser {
+			cfg.ByUser = true
+		}
+		if *topN != 0 {
+			cfg.TopN = *topN
+		}
+		if *topNSort != "" {
+			cfg.TopNSort = *topNSort
+		}
+		if *sampleInterval > 0 {
+			cfg.SampleInterval = *sampleInterval
+		}
+		if *gpu {
+			cfg.GPU = true
+		}
+		if *smaps {
+			cfg.Smaps = true
+		}
+		if *binaryHash {
+			cfg.BinaryHash = true
+		}
+		if *host {
+			cfg.Host = true
+		}
+		if *cacheTTL > 0 {
+			cfg.CacheTTL = *cacheTTL
+		}
+		if *perCPU {
+			cfg.PerCPU = true
+		}
+		if *textfileDir != "" {
+			cfg.TextfileDirectory = *textfileDir
+		}
+		if *kernelThreads {
+			cfg.KernelThreads = true
+		}
+		// 命令行中的监控目标追加在配置文件之后
+		if *systemdUnits != "" {
+			cfg.Targets = append(cfg.Targets, exporter.UnitTargets(strings.Split(*systemdUnits, ","))...)
+		}
+		cfg.Exclude = append(cfg.Exclude, excludes...)
+		cfg.Processes = append(cfg.Processes, matches...)
+		cfg.Processes = append(cfg.Processes, flag.Args()...)
+		return cfg, nil
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		logger.Error(exporter.T("cli.load_config"), "error", err)
+		os.Exit(1)
+	}
+	if cfg.Locale != "" {
+		if err := exporter.SetLocale(cfg.Locale); err != nil {
+			logger.Error(exporter.T("cli.load_config"), "error", err)
+			os.Exit(1)
+		}
+	}
+	if len(cfg.TargetNames()) == 0 && len(cfg.Groups) == 0 {
+		flag.Usage()
 		return
 	}
+	if !strings.HasPrefix(*telemetryPath, "/") {
+		logger.Error(exporter.T("cli.telemetry_path"), "path", *telemetryPath)
+		os.Exit(1)
+	}
 
-	// 开启一个子协程执行更新指标逻辑
-	go func() {
-		for range time.Tick(time.Second * 5) { // 每隔 5 秒更新一次指标
-			updateMetrics(processNames)
+	if *procfs != "/proc" {
+		if err := exporter.SetProcfs(*procfs); err != nil {
+			logger.Error(exporter.T("cli.procfs"), "error", err)
+			os.Exit(1)
 		}
-	}()
+	}
 
-	// 开启一个子协程定时打印 metrics 到控制台
-	go func() {
-		for range time.Tick(time.Second * 5) { // 每隔 5 秒打印一次
-			printMetrics()
+	if *dryRunMode {
+		if err := dryRun(context.Background(), os.Stdout, *cfg); err != nil {
+			logger.Error(exporter.T("cli.dry_run"), "error", err)
+			os.Exit(1)
 		}
-	}()
+		return
+	}
 
-	// Start HTTP server
-	http.Handle("/metrics", promhttp.Handler())
-	err := http.ListenAndServe("0.0.0.0:9100", nil)
-	if err != nil {
-		fmt.Printf("Error starting HTTP server: %s\n", err)
+	if *tracingEndpoint != "" {
+		shutdown, err := setupTracing(context.Background(), *tracingEndpoint, *tracingInsecure)
+		if err != nil {
+			logger.Error(exporter.T("cli.tracing"), "error", err)
+			os.Exit(1)
+		}
+		defer shutdown(context.Background())
 	}
-}
 
-func updateMetrics(processNames []string) {
-	// 使用互斥锁确保在更新指标时不被同时执行
-	mutex.Lock()
-	defer mutex.Unlock()
-
-	for _, processName := range processNames {
-		// 获取进程的 PID
-		pid := getPID(processName)
-		if pid == 0 {
-			// 如果进程不存在，设置指标为 0 表示未知值
-			cpuUsage.WithLabelValues(processName).Set(float64(0)) // NaN
-			memUsage.WithLabelValues(processName).Set(float64(0)) // NaN
-			pidUsage.WithLabelValues(processName).Set(float64(0)) // NaN
-			continue
-		}
-
-		lastUpdateTime, ok := lastUpdate[processName]
-		// 检查是否需要更新，避免在短时间内频繁更新导致数据丢失
-		if !ok || time.Since(lastUpdateTime) >= time.Second*5 {
-			p, err := process.NewProcess(int32(pid))
-			if err != nil {
-				fmt.Printf("Error getting process: %s\n", err)
-				return
-			}
+	// 使用独立的 registry, 避免与默认 registry 上的其他指标冲突.
+	// exporter 自身的进程指标加上 process_exporter_ 前缀, 以免与监控目标的 process_open_fds 等指标冲突
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{Namespace: "process_exporter"}),
+		buildInfoCollector(build),
+	)
 
-			// 获取进程的 CPU 使用率
-			cpuPercent, err := p.CPUPercent()
-			if err != nil {
-				fmt.Printf("Error getting CPU percent: %s\n", err)
-				return
-			}
-			cpuUsage.WithLabelValues(processName).Set(cpuPercent)
+	var extra []exporter.Collector
+	var ts *exporter.TaskstatsCollector
+	if *taskstats {
+		ts, err = exporter.NewTaskstatsCollector(logger)
+		if err != nil {
+			logger.Error(exporter.T("cli.taskstats"), "error", err)
+			os.Exit(1)
+		}
+		extra = append(extra, ts)
+	}
 
-			// 获取进程的 mem 使用率
-			memoryPercent, err := p.MemoryPercent()
-			if err != nil {
-				fmt.Printf("Error getting mem percent: %s\n", err)
-				return
-			}
-			memUsage.WithLabelValues(processName).Set(float64(memoryPercent))
+	exp, err := exporter.New(exporter.Opts{
+		Config:     *cfg,
+		Registerer: reg,
+		Collectors: extra,
+		Plugins:    plugins,
+		Logger:     logger,
 
-			// 获取进程的 pid
-			pidUsage.WithLabelValues(processName).Set(float64(pid))
+		NativeHistograms: *nativeHistograms,
+		CollectOnScrape:  *onScrape,
+		AdminAPI:         *enableAdminAPI,
+	})
+	if err != nil {
+		logger.Error(exporter.T("cli.new_exporter"), "error", err)
+		os.Exit(1)
+	}
+	if ts != nil {
+		ts.Start(context.Background(), exp.CycleInterval())
+	}
 
-			// 更新上次更新时间
-			lastUpdate[processName] = time.Now()
+	// 只采集一次, 推送到 Pushgateway 或打印后退出
+	if *once {
+		grouping, err := parseGrouping(*pushgatewayGrouping)
+		if err == nil {
+			err = runOnce(context.Background(), exp, reg, os.Stdout, *onceFormat, *pushgatewayURL, *pushgatewayJob, grouping)
 		}
+		if err != nil {
+			logger.Error(exporter.T("cli.once"), "error", err)
+			os.Exit(1)
+		}
+		return
 	}
-}
 
-func getPID(processName string) int {
-	processes, err := process.Processes()
-	if err != nil {
-		fmt.Printf("Error getting processes: %s\n", err)
-		return 0
+	// 通过 OTLP 推送与 /metrics 相同的指标, 迁移到 OpenTelemetry 期间可以同时使用两者
+	if *otlpEndpoint != "" {
+		interval := *otlpInterval
+		if interval <= 0 {
+			interval = exp.CycleInterval()
+		}
+		// 备用实例不推送指标, 以免与主实例推送相同的序列
+		active := prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+			if !exp.Active() {
+				return nil, nil
+			}
+			return reg.Gather()
+		})
+		shutdown, err := setupOTLP(context.Background(), *otlpEndpoint, *otlpProtocol, *otlpInsecure, interval, active)
+		if err != nil {
+			logger.Error(exporter.T("cli.otlp"), "error", err)
+			os.Exit(1)
+		}
+		defer shutdown(context.Background())
+	}
+	// net/http/pprof 导入时就注册到 http.DefaultServeMux, 因此使用单独的 ServeMux, 只在 -web.enable-pprof 时挂载
+	mux := http.NewServeMux()
+	if *enablePprof {
+		registerPprof(mux)
 	}
+	if !*otlpOnly {
+		mux.Handle(*telemetryPath, exp.Handler())
+	}
+	mux.Handle("/", exp.LandingHandler(version, *telemetryPath))
+	mux.Handle("/version", versionHandler(build))
+	mux.Handle("/probe", exp.ProbeHandler())
+	mux.Handle("/api/", exp.APIHandler())
+	mux.Handle("/ui/", http.StripPrefix("/ui", exporter.UIHandler()))
+	mux.Handle("/debug/state", exp.StateHandler())
+	mux.Handle("/debug/targets", exp.TargetsPageHandler())
+	mux.Handle("/-/healthy", exp.HealthyHandler())
+	mux.Handle("/-/ready", exp.ReadyHandler())
+	mux.Handle("/-/loglevel", exporter.LogLevelHandler(logLevel, logger))
+	mux.Handle("/-/dump", exp.DumpHandler(*dumpDir))
 
-	for _, p := range processes {
-		name, _ := p.Name()
-		if name == processName {
-			return int(p.Pid)
+	// 收到 SIGHUP 或 POST /-/reload 时重新加载配置, 配置不合法时继续使用原来的配置
+	reload := func() error {
+		cfg, err := loadConfig()
+		if err == nil {
+			err = exp.Reload(*cfg)
+		}
+		if err != nil {
+			logger.Error(exporter.T("cli.reload"), "error", err)
 		}
+		return err
 	}
-	fmt.Printf("Process with name %s not found\n", processName)
-	return 0
-}
+	mux.Handle("/-/reload", exporter.ReloadHandler(reload))
+	reloadSignal := make(chan os.Signal, 1)
+	notifyReload(reloadSignal)
+	go func() {
+		for range reloadSignal {
+			reload()
+		}
+	}()
 
-func printMetrics() {
-	// 使用互斥锁确保在清除指标和打印指标时不被同时执行
-	mutex.Lock()
-	defer mutex.Unlock()
+	// 收到 SIGUSR1 时把当前序列和内部状态写入快照文件
+	dumpSignal := make(chan os.Signal, 1)
+	notifyDump(dumpSignal)
+	go func() {
+		for range dumpSignal {
+			if _, err := exp.WriteDump(*dumpDir); err != nil {
+				logger.Error(exporter.T("cli.dump"), "error", err)
+			}
+		}
+	}()
 
-	// 打印最新的指标
-	mfs, err := prometheus.DefaultGatherer.Gather()
-	if err != nil {
-		fmt.Printf("Error gathering metrics: %s\n", err)
-		return
+	// 冗余部署时先以备用实例启动, 获得锁后才开始采集
+	if *leaderLock != "" {
+		exp.SetActive(false)
+		if *leaderState == "" {
+			*leaderState = *stateFile
+		}
+	} else if *stateFile != "" {
+		// 开始采集前恢复上次退出时的累计值, 重新部署不会让计数器归零
+		loadHandover(exp, *stateFile, logger)
 	}
 
-	for _, mf := range mfs {
-		for _, m := range mf.Metric {
-			// 检查标签是否以 "go_" 开头
-			if len(m.Label) > 0 && !strings.HasPrefix(*m.Label[0].Name, "go_") {
-				fmt.Printf("Metric: %s - Value: %f\n", m, m.Gauge.GetValue())
+	// serve 运行采集和 HTTP 服务直到 ctx 被取消, 作为 Windows 服务运行时由服务管理器停止
+	serve := func(ctx context.Context) error {
+		// 开启一个子协程执行更新指标逻辑
+		runDone := make(chan struct{})
+		go func() {
+			exp.Run(ctx) // 每隔 5 秒 (-collector.interval) 更新一次指标, 使用 -collector.on-scrape 时在抓取时更新
+			close(runDone)
+		}()
+		go runSystemdNotify(ctx, exp, logger)
+		if *leaderLock != "" {
+			go runLeaderElection(ctx, exp, *leaderLock, *leaderState, logger)
+		} else if *stateFile != "" {
+			go saveHandover(ctx, exp, *stateFile, logger)
+		}
+		if *remoteWriteURL != "" {
+			rw := remoteWriteConfig{url: *remoteWriteURL, interval: *remoteWriteInterval, job: *remoteWriteJob}
+			if rw.interval <= 0 {
+				rw.interval = exp.CycleInterval()
+			}
+			rw.client = config.DefaultHTTPClientConfig
+			rw.client.BearerTokenFile = *remoteWriteToken
+			rw.client.TLSConfig = config.TLSConfig{
+				CAFile:             *remoteWriteCA,
+				CertFile:           *remoteWriteCert,
+				KeyFile:            *remoteWriteKey,
+				InsecureSkipVerify: *remoteWriteInsecure,
+			}
+			go runRemoteWrite(ctx, rw, exp, reg, logger)
+		}
+
+		if *grpcListenAddress != "" {
+			grpcLn, err := listen(*listenFamily, *grpcListenAddress)
+			if err != nil {
+				return err
+			}
+			if err := startGRPC(ctx, grpcLn, exp, logger); err != nil {
+				grpcLn.Close()
+				return err
 			}
 		}
+
+		// 开启一个子协程定时打印各目标的概要到控制台
+		if *reportConsole {
+			color := exporter.ColorEnabled(os.Stdout)
+			go func() {
+				for range time.Tick(*reportInterval) {
+					exporter.WriteReport(os.Stdout, exp.Snapshot(), color)
+					fmt.Println()
+				}
+			}()
+		}
+
+		// Start HTTP server
+		var ln net.Listener
+		if *systemdSocket {
+			ln, err = systemdListener()
+		} else {
+			ln, err = listen(*listenFamily, *listenAddress)
+		}
+		if err != nil {
+			return err
+		}
+		// 收到 SIGTERM 或 SIGINT 后不再接受新连接, 等待进行中的抓取完成, 最多等待 -web.shutdown-timeout
+		srv := &http.Server{Handler: mux}
+		shutdownDone := make(chan error, 1)
+		go func() {
+			<-ctx.Done()
+			sdNotify("STOPPING=1")
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+			defer cancel()
+			shutdownDone <- srv.Shutdown(shutdownCtx)
+		}()
+		logger.Info(exporter.T("cli.started"), "address", ln.Addr().String(), "targets", len(cfg.TargetNames()))
+		// -web.config.file 为空时不启用 TLS 和认证
+		if err := web.Serve(ln, srv, &web.FlagConfig{WebConfigFile: webConfig}, logger); err != nil && err != http.ErrServerClosed {
+			return err
+		}
+		// Serve 在开始关闭时就会返回, 需要等待 Shutdown 和正在进行的采集周期结束
+		if err := <-shutdownDone; err != nil {
+			logger.Warn(exporter.T("cli.shutdown"), "timeout", *shutdownTimeout, "error", err)
+		}
+		<-runDone
+		// 最后一个采集周期结束后再写入一次, 重启后从退出时的值继续
+		if *leaderLock == "" && *stateFile != "" {
+			writeHandover(exp, *stateFile, logger)
+		}
+		logger.Info(exporter.T("cli.stopped"))
+		return nil
+	}
+	if err := runDaemon(serve, *serviceRun); err != nil {
+		logger.Error(exporter.T("cli.start_server"), "error", err)
+		os.Exit(1)
 	}
-	fmt.Println("==========================================")
 }
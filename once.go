@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"github.com/prometheus/common/expfmt"
+	"github.com/qishu321/exporter/exporter"
+	"io"
+	"os"
+	"strings"
+)
+
+// runOnce 采集一次所有目标, pushURL 不为空时把结果推送到 Pushgateway 的 job 和 grouping 分组, 否则按 format 写到 w:
+// text 为 Prometheus 文本格式, table 为与 -report.console 相同的表格, json 与 /api/v1/stats 相同.
+// 用于由 cron 启动的批处理任务和排查问题
+func runOnce(ctx context.Context, exp *exporter.Exporter, gatherer prometheus.Gatherer, w io.Writer, format, pushURL, job string, grouping map[string]string) error {
+	if format != "text" && format != "table" && format != "json" {
+		return fmt.Errorf("unknown format %q, want text, table or json", format)
+	}
+	exp.Update(ctx)
+	if pushURL != "" {
+		p := push.New(pushURL, job).Gatherer(gatherer)
+		for name, value := range grouping {
+			p = p.Grouping(name, value)
+		}
+		return p.PushContext(ctx)
+	}
+	switch format {
+	case "table":
+		f, ok := w.(*os.File)
+		exporter.WriteReport(w, exp.Snapshot(), ok && exporter.ColorEnabled(f))
+		return nil
+	case "json":
+		return exporter.WriteStatsJSON(w, exp.Snapshot())
+	}
+	mfs, err := gatherer.Gather()
+	if err != nil {
+		return err
+	}
+	enc := expfmt.NewEncoder(w, expfmt.FmtText)
+	for _, mf := range mfs {
+		if err := enc.Encode(mf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseGrouping 解析 -pushgateway.grouping, 如 "instance=db1,batch=nightly", 没有 instance 时使用主机名
+func parseGrouping(s string) (map[string]string, error) {
+	grouping := make(map[string]string)
+	for _, kv := range strings.Split(s, ",") {
+		if kv = strings.TrimSpace(kv); kv == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("invalid grouping %q, want name=value", kv)
+		}
+		grouping[name] = value
+	}
+	if _, ok := grouping["instance"]; !ok {
+		if host, err := os.Hostname(); err == nil {
+			grouping["instance"] = host
+		}
+	}
+	return grouping, nil
+}
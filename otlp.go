@@ -0,0 +1,57 @@
+//go:build !nootlp
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	otelprom "go.opentelemetry.io/contrib/bridges/prometheus"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"time"
+)
+
+// setupOTLP 每隔 interval 把 gatherer 中的指标通过 OTLP (protocol 为 grpc 或 http/protobuf) 推送到 endpoint,
+// 与 /metrics 导出的是同一组指标. 返回的函数用于退出前推送最后一次并关闭连接
+func setupOTLP(ctx context.Context, endpoint, protocol string, insecure bool, interval time.Duration, gatherer prometheus.Gatherer) (func(context.Context) error, error) {
+	var exp sdkmetric.Exporter
+	var err error
+	switch protocol {
+	case "grpc":
+		opts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(endpoint)}
+		if insecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		}
+		exp, err = otlpmetricgrpc.New(ctx, opts...)
+	case "http/protobuf":
+		opts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(endpoint)}
+		if insecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		}
+		exp, err = otlpmetrichttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %q, want grpc or http/protobuf", protocol)
+	}
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		// 不带 schema URL, 以免与 resource.Default() 使用的 semconv 版本冲突
+		semconv.ServiceName("process-exporter"),
+	))
+	if err != nil {
+		return nil, err
+	}
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exp,
+			sdkmetric.WithInterval(interval),
+			sdkmetric.WithProducer(otelprom.NewMetricProducer(otelprom.WithGatherer(gatherer))),
+		)),
+		sdkmetric.WithResource(res),
+	)
+	return mp.Shutdown, nil
+}
@@ -0,0 +1,15 @@
+//go:build nootlp
+
+package main
+
+import (
+	"context"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"time"
+)
+
+// setupOTLP 在使用 nootlp 标签构建时总是返回错误
+func setupOTLP(ctx context.Context, endpoint, protocol string, insecure bool, interval time.Duration, gatherer prometheus.Gatherer) (func(context.Context) error, error) {
+	return nil, errors.New("built without OTLP metrics support (nootlp)")
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"net/http"
+	"net/http/pprof"
+)
+
+// registerPprof 在 mux 上挂载 /debug/pprof/, 用于排查卡住的采集或内存增长, 如
+// go tool pprof http://localhost:9100/debug/pprof/heap
+func registerPprof(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+}
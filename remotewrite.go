@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/klauspost/compress/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/config"
+	"github.com/qishu321/exporter/exporter"
+	"google.golang.org/protobuf/encoding/protowire"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// remoteWriteConfig 是 -remote-write.* 参数
+type remoteWriteConfig struct {
+	url      string
+	interval time.Duration
+	job      string
+	client   config.HTTPClientConfig
+}
+
+// runRemoteWrite 每隔 interval 把 gatherer 中的所有样本通过 Prometheus remote_write 协议推送到 url,
+// 用于 exporter 位于 NAT 之后无法被抓取的环境. 推送失败时只记录日志, 下一个周期推送的是新的样本.
+// exp 为备用实例时不推送, 以免与主实例推送相同的序列
+func runRemoteWrite(ctx context.Context, cfg remoteWriteConfig, exp *exporter.Exporter, gatherer prometheus.Gatherer, logger *slog.Logger) {
+	client, err := config.NewClientFromConfig(cfg.client, "remote_write")
+	if err != nil {
+		logger.Error(exporter.T("cli.remote_write"), "url", cfg.url, "error", err)
+		return
+	}
+	client.Timeout = cfg.interval
+	instance, _ := os.Hostname()
+	t := time.NewTicker(cfg.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		if !exp.Active() {
+			continue
+		}
+		if err := pushRemoteWrite(ctx, client, cfg.url, gatherer, cfg.job, instance); err != nil {
+			logger.Warn(exporter.T("cli.remote_write"), "url", cfg.url, "error", err)
+		}
+	}
+}
+
+func pushRemoteWrite(ctx context.Context, client *http.Client, url string, gatherer prometheus.Gatherer, job, instance string) error {
+	mfs, err := gatherer.Gather()
+	if err != nil {
+		return err
+	}
+	body := snappy.Encode(nil, encodeWriteRequest(mfs, time.Now().UnixMilli(), job, instance))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	req.Header.Set("User-Agent", "process-exporter/"+version)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("remote write: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// encodeWriteRequest 把指标编码为 remote_write 的 WriteRequest protobuf, 直方图和摘要按文本格式拆分为
+// _bucket、_sum、_count 等序列. 没有 job 和 instance 标签的序列加上这两个标签, 与抓取时的目标标签一致
+func encodeWriteRequest(mfs []*dto.MetricFamily, ts int64, job, instance string) []byte {
+	var buf []byte
+	series := func(name string, labels []*dto.LabelPair, value float64, extra ...string) {
+		all := map[string]string{"__name__": name, "job": job, "instance": instance}
+		for _, l := range labels {
+			all[l.GetName()] = l.GetValue()
+		}
+		for i := 0; i+1 < len(extra); i += 2 {
+			all[extra[i]] = extra[i+1]
+		}
+		// remote_write 要求标签按名称排序
+		names := make([]string, 0, len(all))
+		for n := range all {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		var s []byte
+		for _, n := range names {
+			var l []byte
+			l = protowire.AppendTag(l, 1, protowire.BytesType)
+			l = protowire.AppendString(l, n)
+			l = protowire.AppendTag(l, 2, protowire.BytesType)
+			l = protowire.AppendString(l, all[n])
+			s = protowire.AppendTag(s, 1, protowire.BytesType)
+			s = protowire.AppendBytes(s, l)
+		}
+		var sample []byte
+		sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+		sample = protowire.AppendFixed64(sample, math.Float64bits(value))
+		sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+		sample = protowire.AppendVarint(sample, uint64(ts))
+		s = protowire.AppendTag(s, 2, protowire.BytesType)
+		s = protowire.AppendBytes(s, sample)
+		buf = protowire.AppendTag(buf, 1, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, s)
+	}
+	for _, mf := range mfs {
+		name := mf.GetName()
+		for _, m := range mf.GetMetric() {
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				series(name, m.GetLabel(), m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				series(name, m.GetLabel(), m.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				series(name, m.GetLabel(), m.GetUntyped().GetValue())
+			case dto.MetricType_HISTOGRAM:
+				h := m.GetHistogram()
+				for _, b := range h.GetBucket() {
+					if !math.IsInf(b.GetUpperBound(), 1) {
+						series(name+"_bucket", m.GetLabel(), float64(b.GetCumulativeCount()), "le", formatFloat(b.GetUpperBound()))
+					}
+				}
+				series(name+"_bucket", m.GetLabel(), float64(h.GetSampleCount()), "le", "+Inf")
+				series(name+"_sum", m.GetLabel(), h.GetSampleSum())
+				series(name+"_count", m.GetLabel(), float64(h.GetSampleCount()))
+			case dto.MetricType_SUMMARY:
+				s := m.GetSummary()
+				for _, q := range s.GetQuantile() {
+					series(name, m.GetLabel(), q.GetValue(), "quantile", formatFloat(q.GetQuantile()))
+				}
+				series(name+"_sum", m.GetLabel(), s.GetSampleSum())
+				series(name+"_count", m.GetLabel(), float64(s.GetSampleCount()))
+			}
+		}
+	}
+	return buf
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
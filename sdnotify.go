@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"github.com/qishu321/exporter/exporter"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// sdNotify 向 systemd 发送状态通知, 不是由 systemd 以 Type=notify 启动时什么也不做
+func sdNotify(state string) error {
+	addr := os.Getenv("NOTIFY_SOCKET")
+	if addr == "" {
+		return nil
+	}
+	// @ 开头的是抽象命名空间的 socket
+	if addr[0] == '@' {
+		addr = "\x00" + addr[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+// watchdogInterval 返回 systemd 要求的 watchdog 间隔, 没有启用 WatchdogSec 时为 0
+func watchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
+
+// runSystemdNotify 在第一次成功采集 (抓取时采集时为第一次成功抓取) 后发送 READY=1, 备用实例在开始运行后即发送.
+// 之后只要 exp.Healthy 返回 nil 就定期发送 WATCHDOG=1, 采集卡住时停止发送, 由 systemd 重启 exporter
+func runSystemdNotify(ctx context.Context, exp *exporter.Exporter, logger *slog.Logger) {
+	if os.Getenv("NOTIFY_SOCKET") == "" {
+		return
+	}
+	wd := watchdogInterval()
+	ping := time.Second
+	if wd > 0 {
+		ping = wd / 2
+	}
+	t := time.NewTicker(ping)
+	defer t.Stop()
+
+	ready := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		healthy := exp.Healthy() == nil
+		if !ready && (exp.Ready() == nil || !exp.Active() && healthy) {
+			if err := sdNotify("READY=1"); err != nil {
+				logger.Warn(exporter.T("log.sd_notify"), "error", err)
+			}
+			ready = true
+		}
+		// Healthy 允许错过两个周期, 避免偶尔较慢的采集导致重启
+		if ready && wd > 0 && healthy {
+			if err := sdNotify("WATCHDOG=1"); err != nil {
+				logger.Warn(exporter.T("log.sd_notify"), "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,28 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/qishu321/exporter/exporter"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// runDaemon 运行 serve 直到收到 SIGINT 或 SIGTERM, service (-service.run) 只在 Windows 上支持
+func runDaemon(serve func(ctx context.Context) error, service bool) error {
+	if service {
+		return errors.New(exporter.T("service.unsupported"))
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return serve(ctx)
+}
+
+func runService(args []string) int {
+	fmt.Fprintln(os.Stderr, exporter.T("service.unsupported"))
+	return 1
+}
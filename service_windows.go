@@ -0,0 +1,181 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/qishu321/exporter/exporter"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// serviceName 是注册到服务管理器和事件日志的名称
+const serviceName = "process-exporter"
+
+// runDaemon 由服务管理器启动或设置了 service (-service.run) 时以 Windows 服务运行 serve, 否则直接运行
+func runDaemon(serve func(ctx context.Context) error, service bool) error {
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		return err
+	}
+	if !isService && !service {
+		return serve(context.Background())
+	}
+	h := &serviceHandler{serve: serve}
+	if err := svc.Run(serviceName, h); err != nil {
+		return err
+	}
+	return h.err
+}
+
+// serviceHandler 处理服务管理器的控制请求, 收到停止或关机时取消 serve 的 ctx
+type serviceHandler struct {
+	serve func(ctx context.Context) error
+	err   error
+}
+
+func (h *serviceHandler) Execute(args []string, reqs <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	const accepts = svc.AcceptStop | svc.AcceptShutdown
+	status <- svc.Status{State: svc.StartPending}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- h.serve(ctx) }()
+	status <- svc.Status{State: svc.Running, Accepts: accepts}
+
+	for {
+		select {
+		case err := <-done:
+			h.err = err
+			if err != nil {
+				return false, 1
+			}
+			return false, 0
+		case req := <-reqs:
+			switch req.Cmd {
+			case svc.Interrogate:
+				status <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				cancel()
+			}
+		}
+	}
+}
+
+// runService 实现 service 子命令: install [参数...] | uninstall | start | stop
+func runService(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, exporter.T("service.usage", os.Args[0]))
+		return 2
+	}
+	var err error
+	switch args[0] {
+	case "install":
+		err = installService(args[1:])
+	case "uninstall":
+		err = uninstallService()
+	case "start":
+		err = controlService(func(s *mgr.Service) error { return s.Start() })
+	case "stop":
+		err = controlService(func(s *mgr.Service) error {
+			_, err := s.Control(svc.Stop)
+			return err
+		})
+	default:
+		fmt.Fprintln(os.Stderr, exporter.T("service.usage", os.Args[0]))
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, exporter.T("cli.service", err))
+		return 1
+	}
+	return 0
+}
+
+// installService 注册自动启动的服务, args 为服务启动时使用的参数
+func installService(args []string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	exe, err = filepath.Abs(exe)
+	if err != nil {
+		return err
+	}
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := m.CreateService(serviceName, exe, mgr.Config{
+		DisplayName: "Process Exporter",
+		Description: exporter.T("service.description"),
+		StartType:   mgr.StartAutomatic,
+	}, args...)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	// 事件来源已存在时忽略错误
+	eventlog.InstallAsEventCreate(serviceName, eventlog.Error|eventlog.Warning|eventlog.Info)
+	return nil
+}
+
+func uninstallService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	if err := s.Delete(); err != nil {
+		return err
+	}
+	eventlog.Remove(serviceName)
+	return nil
+}
+
+// controlService 对已注册的服务执行 f, 并等待服务状态变化
+func controlService(f func(s *mgr.Service) error) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	before, err := s.Query()
+	if err != nil {
+		return err
+	}
+	if err := f(s); err != nil {
+		return err
+	}
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(300 * time.Millisecond) {
+		st, err := s.Query()
+		if err != nil {
+			return err
+		}
+		if st.State != before.State && st.State != svc.StartPending && st.State != svc.StopPending {
+			return nil
+		}
+	}
+	return fmt.Errorf("timeout waiting for service %s", serviceName)
+}
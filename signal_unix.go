@@ -0,0 +1,19 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyDump 在收到 SIGUSR1 时向 ch 发送信号
+func notifyDump(ch chan<- os.Signal) {
+	signal.Notify(ch, syscall.SIGUSR1)
+}
+
+// notifyReload 在收到 SIGHUP 时向 ch 发送信号
+func notifyReload(ch chan<- os.Signal) {
+	signal.Notify(ch, syscall.SIGHUP)
+}
@@ -0,0 +1,13 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+)
+
+// notifyDump 在 Windows 上没有对应的信号, 只能通过 /-/dump 生成快照
+func notifyDump(ch chan<- os.Signal) {}
+
+// notifyReload 在 Windows 上没有对应的信号, 只能通过 /-/reload 重新加载配置
+func notifyReload(ch chan<- os.Signal) {}
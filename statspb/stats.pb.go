@@ -0,0 +1,359 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.28.3
+// source: statspb/stats.proto
+
+package statspb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type WatchProcessStatsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 只推送这些目标, 为空时推送所有目标
+	Targets       []string `protobuf:"bytes,1,rep,name=targets,proto3" json:"targets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchProcessStatsRequest) Reset() {
+	*x = WatchProcessStatsRequest{}
+	mi := &file_statspb_stats_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchProcessStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchProcessStatsRequest) ProtoMessage() {}
+
+func (x *WatchProcessStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_statspb_stats_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchProcessStatsRequest.ProtoReflect.Descriptor instead.
+func (*WatchProcessStatsRequest) Descriptor() ([]byte, []int) {
+	return file_statspb_stats_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *WatchProcessStatsRequest) GetTargets() []string {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+// ProcessStatsUpdate 是一次推送的所有目标的数据, 按目标名排序
+type ProcessStatsUpdate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Processes     []*ProcessStats        `protobuf:"bytes,2,rep,name=processes,proto3" json:"processes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessStatsUpdate) Reset() {
+	*x = ProcessStatsUpdate{}
+	mi := &file_statspb_stats_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessStatsUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessStatsUpdate) ProtoMessage() {}
+
+func (x *ProcessStatsUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_statspb_stats_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessStatsUpdate.ProtoReflect.Descriptor instead.
+func (*ProcessStatsUpdate) Descriptor() ([]byte, []int) {
+	return file_statspb_stats_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ProcessStatsUpdate) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *ProcessStatsUpdate) GetProcesses() []*ProcessStats {
+	if x != nil {
+		return x.Processes
+	}
+	return nil
+}
+
+// ProcessStats 是单个目标最近一次采集的数据, 与 /api/v1/stats 相同, 没有匹配到进程时 up 为 false, 其余字段为 0
+type ProcessStats struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Process           string                 `protobuf:"bytes,1,opt,name=process,proto3" json:"process,omitempty"`
+	Up                bool                   `protobuf:"varint,2,opt,name=up,proto3" json:"up,omitempty"`
+	Pid               int32                  `protobuf:"varint,3,opt,name=pid,proto3" json:"pid,omitempty"`
+	CpuPercent        float64                `protobuf:"fixed64,4,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"`
+	MemoryPercent     float64                `protobuf:"fixed64,5,opt,name=memory_percent,json=memoryPercent,proto3" json:"memory_percent,omitempty"`
+	MemoryRssBytes    uint64                 `protobuf:"varint,6,opt,name=memory_rss_bytes,json=memoryRssBytes,proto3" json:"memory_rss_bytes,omitempty"`
+	MemoryVmsBytes    uint64                 `protobuf:"varint,7,opt,name=memory_vms_bytes,json=memoryVmsBytes,proto3" json:"memory_vms_bytes,omitempty"`
+	MemorySwapBytes   uint64                 `protobuf:"varint,8,opt,name=memory_swap_bytes,json=memorySwapBytes,proto3" json:"memory_swap_bytes,omitempty"`
+	MemorySharedBytes uint64                 `protobuf:"varint,9,opt,name=memory_shared_bytes,json=memorySharedBytes,proto3" json:"memory_shared_bytes,omitempty"`
+	NumFds            int32                  `protobuf:"varint,10,opt,name=num_fds,json=numFds,proto3" json:"num_fds,omitempty"`
+	NumThreads        int32                  `protobuf:"varint,11,opt,name=num_threads,json=numThreads,proto3" json:"num_threads,omitempty"`
+	Status            string                 `protobuf:"bytes,12,opt,name=status,proto3" json:"status,omitempty"`
+	Incomplete        bool                   `protobuf:"varint,13,opt,name=incomplete,proto3" json:"incomplete,omitempty"`
+	Time              *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ProcessStats) Reset() {
+	*x = ProcessStats{}
+	mi := &file_statspb_stats_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessStats) ProtoMessage() {}
+
+func (x *ProcessStats) ProtoReflect() protoreflect.Message {
+	mi := &file_statspb_stats_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessStats.ProtoReflect.Descriptor instead.
+func (*ProcessStats) Descriptor() ([]byte, []int) {
+	return file_statspb_stats_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ProcessStats) GetProcess() string {
+	if x != nil {
+		return x.Process
+	}
+	return ""
+}
+
+func (x *ProcessStats) GetUp() bool {
+	if x != nil {
+		return x.Up
+	}
+	return false
+}
+
+func (x *ProcessStats) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *ProcessStats) GetCpuPercent() float64 {
+	if x != nil {
+		return x.CpuPercent
+	}
+	return 0
+}
+
+func (x *ProcessStats) GetMemoryPercent() float64 {
+	if x != nil {
+		return x.MemoryPercent
+	}
+	return 0
+}
+
+func (x *ProcessStats) GetMemoryRssBytes() uint64 {
+	if x != nil {
+		return x.MemoryRssBytes
+	}
+	return 0
+}
+
+func (x *ProcessStats) GetMemoryVmsBytes() uint64 {
+	if x != nil {
+		return x.MemoryVmsBytes
+	}
+	return 0
+}
+
+func (x *ProcessStats) GetMemorySwapBytes() uint64 {
+	if x != nil {
+		return x.MemorySwapBytes
+	}
+	return 0
+}
+
+func (x *ProcessStats) GetMemorySharedBytes() uint64 {
+	if x != nil {
+		return x.MemorySharedBytes
+	}
+	return 0
+}
+
+func (x *ProcessStats) GetNumFds() int32 {
+	if x != nil {
+		return x.NumFds
+	}
+	return 0
+}
+
+func (x *ProcessStats) GetNumThreads() int32 {
+	if x != nil {
+		return x.NumThreads
+	}
+	return 0
+}
+
+func (x *ProcessStats) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ProcessStats) GetIncomplete() bool {
+	if x != nil {
+		return x.Incomplete
+	}
+	return false
+}
+
+func (x *ProcessStats) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+var File_statspb_stats_proto protoreflect.FileDescriptor
+
+const file_statspb_stats_proto_rawDesc = "" +
+	"\n" +
+	"\x13statspb/stats.proto\x12\x12processexporter.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"4\n" +
+	"\x18WatchProcessStatsRequest\x12\x18\n" +
+	"\atargets\x18\x01 \x03(\tR\atargets\"\x84\x01\n" +
+	"\x12ProcessStatsUpdate\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12>\n" +
+	"\tprocesses\x18\x02 \x03(\v2 .processexporter.v1.ProcessStatsR\tprocesses\"\xe4\x03\n" +
+	"\fProcessStats\x12\x18\n" +
+	"\aprocess\x18\x01 \x01(\tR\aprocess\x12\x0e\n" +
+	"\x02up\x18\x02 \x01(\bR\x02up\x12\x10\n" +
+	"\x03pid\x18\x03 \x01(\x05R\x03pid\x12\x1f\n" +
+	"\vcpu_percent\x18\x04 \x01(\x01R\n" +
+	"cpuPercent\x12%\n" +
+	"\x0ememory_percent\x18\x05 \x01(\x01R\rmemoryPercent\x12(\n" +
+	"\x10memory_rss_bytes\x18\x06 \x01(\x04R\x0ememoryRssBytes\x12(\n" +
+	"\x10memory_vms_bytes\x18\a \x01(\x04R\x0ememoryVmsBytes\x12*\n" +
+	"\x11memory_swap_bytes\x18\b \x01(\x04R\x0fmemorySwapBytes\x12.\n" +
+	"\x13memory_shared_bytes\x18\t \x01(\x04R\x11memorySharedBytes\x12\x17\n" +
+	"\anum_fds\x18\n" +
+	" \x01(\x05R\x06numFds\x12\x1f\n" +
+	"\vnum_threads\x18\v \x01(\x05R\n" +
+	"numThreads\x12\x16\n" +
+	"\x06status\x18\f \x01(\tR\x06status\x12\x1e\n" +
+	"\n" +
+	"incomplete\x18\r \x01(\bR\n" +
+	"incomplete\x12.\n" +
+	"\x04time\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\x04time2\x82\x01\n" +
+	"\x13ProcessStatsService\x12k\n" +
+	"\x11WatchProcessStats\x12,.processexporter.v1.WatchProcessStatsRequest\x1a&.processexporter.v1.ProcessStatsUpdate0\x01B&Z$github.com/qishu321/exporter/statspbb\x06proto3"
+
+var (
+	file_statspb_stats_proto_rawDescOnce sync.Once
+	file_statspb_stats_proto_rawDescData []byte
+)
+
+func file_statspb_stats_proto_rawDescGZIP() []byte {
+	file_statspb_stats_proto_rawDescOnce.Do(func() {
+		file_statspb_stats_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_statspb_stats_proto_rawDesc), len(file_statspb_stats_proto_rawDesc)))
+	})
+	return file_statspb_stats_proto_rawDescData
+}
+
+var file_statspb_stats_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_statspb_stats_proto_goTypes = []any{
+	(*WatchProcessStatsRequest)(nil), // 0: processexporter.v1.WatchProcessStatsRequest
+	(*ProcessStatsUpdate)(nil),       // 1: processexporter.v1.ProcessStatsUpdate
+	(*ProcessStats)(nil),             // 2: processexporter.v1.ProcessStats
+	(*timestamppb.Timestamp)(nil),    // 3: google.protobuf.Timestamp
+}
+var file_statspb_stats_proto_depIdxs = []int32{
+	3, // 0: processexporter.v1.ProcessStatsUpdate.time:type_name -> google.protobuf.Timestamp
+	2, // 1: processexporter.v1.ProcessStatsUpdate.processes:type_name -> processexporter.v1.ProcessStats
+	3, // 2: processexporter.v1.ProcessStats.time:type_name -> google.protobuf.Timestamp
+	0, // 3: processexporter.v1.ProcessStatsService.WatchProcessStats:input_type -> processexporter.v1.WatchProcessStatsRequest
+	1, // 4: processexporter.v1.ProcessStatsService.WatchProcessStats:output_type -> processexporter.v1.ProcessStatsUpdate
+	4, // [4:5] is the sub-list for method output_type
+	3, // [3:4] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_statspb_stats_proto_init() }
+func file_statspb_stats_proto_init() {
+	if File_statspb_stats_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_statspb_stats_proto_rawDesc), len(file_statspb_stats_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_statspb_stats_proto_goTypes,
+		DependencyIndexes: file_statspb_stats_proto_depIdxs,
+		MessageInfos:      file_statspb_stats_proto_msgTypes,
+	}.Build()
+	File_statspb_stats_proto = out.File
+	file_statspb_stats_proto_goTypes = nil
+	file_statspb_stats_proto_depIdxs = nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/qishu321/exporter/exporter"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// runTop 实现 top 子命令: 在终端中实时刷新监控目标的表格, 便于通过 SSH 快速排查
+func runTop(args []string) int {
+	fs := flag.NewFlagSet("top", flag.ExitOnError)
+	configFile := fs.String("config.file", "", exporter.T("flag.config.file"))
+	interval := fs.Duration("interval", 5*time.Second, exporter.T("flag.top.interval"))
+	fs.Parse(args)
+
+	cfg := &exporter.Config{}
+	if *configFile != "" {
+		var err error
+		cfg, err = exporter.LoadConfig(*configFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, exporter.T("cli.load_config"), err)
+			return 1
+		}
+	}
+	cfg.Processes = append(cfg.Processes, fs.Args()...)
+	if len(cfg.TargetNames()) == 0 && len(cfg.Groups) == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	// 采集逻辑与服务模式相同, 只是指标注册到一个不对外的 registry 上, 日志丢弃以免打乱画面
+	exp, err := exporter.New(exporter.Opts{
+		Config:     *cfg,
+		Registerer: prometheus.NewRegistry(),
+		Logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, exporter.T("cli.new_exporter"), err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 使用备用屏幕, 退出后恢复原来的终端内容
+	fmt.Print("\033[?1049h")
+	defer fmt.Print("\033[?1049l")
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		exp.Update(ctx)
+		renderTop(os.Stdout, exp.Snapshot())
+		select {
+		case <-ctx.Done():
+			return 0
+		case <-ticker.C:
+		}
+	}
+}
+
+// renderTop 清屏并输出一帧表格
+func renderTop(w io.Writer, stats []exporter.Stats) {
+	fmt.Fprint(w, "\033[H\033[2J")
+	fmt.Fprintln(w, exporter.T("top.title", time.Now().Format("15:04:05")))
+	fmt.Fprintln(w)
+	exporter.WriteReport(w, stats, exporter.ColorEnabled(os.Stdout))
+}
@@ -0,0 +1,13 @@
+//go:build notracing
+
+package main
+
+import (
+	"context"
+	"errors"
+)
+
+// setupTracing 在使用 notracing 标签构建时总是返回错误
+func setupTracing(ctx context.Context, endpoint string, insecure bool) (func(context.Context) error, error) {
+	return nil, errors.New("built without tracing support (notracing)")
+}